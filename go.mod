@@ -4,12 +4,12 @@ go 1.24.0
 
 require (
 	github.com/google/go-cmp v0.6.0
+	github.com/google/uuid v1.6.0
 	github.com/victorwong171/go-utils v0.0.0-20251207103444-2837053c6740
 	gopkg.in/errgo.v2 v2.1.0
 )
 
 require (
-	github.com/google/uuid v1.6.0 // indirect
 	github.com/openzipkin/zipkin-go v0.4.3 // indirect
 	go.uber.org/multierr v1.10.0 // indirect
 	go.uber.org/zap v1.27.0 // indirect
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/victorwong171/go-utils/utils"
+)
+
+// Attachment 附件引用，内容由BlobStore保存
+type Attachment struct {
+	Name string `json:"name"` // 文件名
+	Size int64  `json:"size"` // 字节数
+	Hash string `json:"hash"` // 内容哈希，如 sha256:<hex>
+	URI  string `json:"uri"`  // 存储位置
+}
+
+// Getter methods for Attachment
+func (a *Attachment) GetName() string {
+	return utils.TernaryOperator(a == nil, "", a.Name)
+}
+
+func (a *Attachment) GetSize() int64 {
+	return utils.TernaryOperator(a == nil, 0, a.Size)
+}
+
+func (a *Attachment) GetHash() string {
+	return utils.TernaryOperator(a == nil, "", a.Hash)
+}
+
+func (a *Attachment) GetURI() string {
+	return utils.TernaryOperator(a == nil, "", a.URI)
+}
+
+// Action 工单操作记录，每次审批/驳回追加一条
+type Action struct {
+	Operator    string        `json:"operator"`    // 操作人
+	Operation   string        `json:"operation"`   // 操作名
+	Step        string        `json:"step"`        // 操作时所在步骤
+	Next        string        `json:"next"`        // 选择的下一步骤
+	Comment     string        `json:"comment"`     // 审批意见
+	Attachments []*Attachment `json:"attachments"` // 附件
+	CreatedAt   time.Time     `json:"created_at"`  // 操作时间
+	Override    string        `json:"override"`    // 使用的管理员能力，正常操作时为空
+}
+
+// Getter methods for Action
+func (a *Action) GetOperator() string {
+	return utils.TernaryOperator(a == nil, "", a.Operator)
+}
+
+func (a *Action) GetOperation() string {
+	return utils.TernaryOperator(a == nil, "", a.Operation)
+}
+
+func (a *Action) GetStep() string {
+	return utils.TernaryOperator(a == nil, "", a.Step)
+}
+
+func (a *Action) GetNext() string {
+	return utils.TernaryOperator(a == nil, "", a.Next)
+}
+
+func (a *Action) GetComment() string {
+	return utils.TernaryOperator(a == nil, "", a.Comment)
+}
+
+func (a *Action) GetAttachments() []*Attachment {
+	return utils.TernaryOperator(a == nil, nil, a.Attachments)
+}
+
+func (a *Action) GetOverride() string {
+	return utils.TernaryOperator(a == nil, "", a.Override)
+}
+
+func (a *Action) GetCreatedAt() time.Time {
+	return utils.TernaryOperator(a == nil, time.Time{}, a.CreatedAt)
+}
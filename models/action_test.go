@@ -0,0 +1,67 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAttachment_GetterMethods(t *testing.T) {
+	attachment := &Attachment{Name: "a.pdf", Size: 10, Hash: "sha256:abc", URI: "file:///tmp/abc"}
+
+	if got := attachment.GetName(); got != "a.pdf" {
+		t.Errorf("Attachment.GetName() = %v, want a.pdf", got)
+	}
+	if got := attachment.GetSize(); got != 10 {
+		t.Errorf("Attachment.GetSize() = %v, want 10", got)
+	}
+	if got := attachment.GetHash(); got != "sha256:abc" {
+		t.Errorf("Attachment.GetHash() = %v, want sha256:abc", got)
+	}
+	if got := attachment.GetURI(); got != "file:///tmp/abc" {
+		t.Errorf("Attachment.GetURI() = %v, want file:///tmp/abc", got)
+	}
+}
+
+func TestAction_GetterMethods(t *testing.T) {
+	now := time.Now()
+	action := &Action{
+		Operator:    "user",
+		Operation:   Reject,
+		Step:        "review",
+		Next:        "end",
+		Comment:     "missing invoice",
+		Attachments: []*Attachment{{Name: "a.pdf"}},
+		CreatedAt:   now,
+	}
+
+	if got := action.GetOperator(); got != "user" {
+		t.Errorf("Action.GetOperator() = %v, want user", got)
+	}
+	if got := action.GetOperation(); got != Reject {
+		t.Errorf("Action.GetOperation() = %v, want %v", got, Reject)
+	}
+	if got := action.GetStep(); got != "review" {
+		t.Errorf("Action.GetStep() = %v, want review", got)
+	}
+	if got := action.GetNext(); got != "end" {
+		t.Errorf("Action.GetNext() = %v, want end", got)
+	}
+	if got := action.GetComment(); got != "missing invoice" {
+		t.Errorf("Action.GetComment() = %v, want missing invoice", got)
+	}
+	if got := action.GetAttachments(); len(got) != 1 {
+		t.Errorf("Action.GetAttachments() length = %v, want 1", len(got))
+	}
+	if got := action.GetCreatedAt(); !got.Equal(now) {
+		t.Errorf("Action.GetCreatedAt() = %v, want %v", got, now)
+	}
+}
+
+func TestTicket_History(t *testing.T) {
+	ticket := &Ticket{}
+	ticket.SetHistory([]*Action{{Operator: "a"}})
+	ticket.AddHistory(&Action{Operator: "b"})
+	if got := ticket.GetHistory(); len(got) != 2 || got[1].Operator != "b" {
+		t.Errorf("Ticket.GetHistory() = %v, want 2 actions", got)
+	}
+}
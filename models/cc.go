@@ -0,0 +1,66 @@
+package models
+
+import (
+	"github.com/victorwong171/go-utils/desc/set"
+	"github.com/victorwong171/go-utils/utils"
+)
+
+// CarbonCopy 步骤抄送配置，抄送人仅获得查看权限
+type CarbonCopy struct {
+	Users   []string `json:"users"`   // 抄送用户
+	Roles   []string `json:"roles"`   // 抄送角色
+	Trigger string   `json:"trigger"` // enter/leave/both，为空时按enter处理
+}
+
+// Getter methods for CarbonCopy
+func (cc *CarbonCopy) GetUsers() []string {
+	return utils.TernaryOperator(cc == nil, nil, cc.Users)
+}
+
+func (cc *CarbonCopy) GetRoles() []string {
+	return utils.TernaryOperator(cc == nil, nil, cc.Roles)
+}
+
+func (cc *CarbonCopy) GetTrigger() string {
+	return utils.TernaryOperator(cc == nil, "", cc.Trigger)
+}
+
+// Setter methods for CarbonCopy
+func (cc *CarbonCopy) SetUsers(users []string) {
+	if cc != nil {
+		cc.Users = users
+	}
+}
+
+func (cc *CarbonCopy) SetRoles(roles []string) {
+	if cc != nil {
+		cc.Roles = roles
+	}
+}
+
+func (cc *CarbonCopy) SetTrigger(trigger string) {
+	if cc != nil {
+		cc.Trigger = trigger
+	}
+}
+
+// OnEnter 工单进入步骤时是否抄送
+func (cc *CarbonCopy) OnEnter() bool {
+	return cc != nil && (cc.Trigger == "" || cc.Trigger == CCOnEnter || cc.Trigger == CCOnBoth)
+}
+
+// OnLeave 工单离开步骤时是否抄送
+func (cc *CarbonCopy) OnLeave() bool {
+	return cc != nil && (cc.Trigger == CCOnLeave || cc.Trigger == CCOnBoth)
+}
+
+func appendUnique(list []string, items ...string) []string {
+	exists := set.Setify(list...)
+	for _, item := range items {
+		if !exists.HasKey(item) {
+			exists.Set(item)
+			list = append(list, item)
+		}
+	}
+	return list
+}
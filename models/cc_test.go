@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestCarbonCopy_GetterSetterMethods(t *testing.T) {
+	cc := &CarbonCopy{}
+	cc.SetUsers([]string{"u1"})
+	cc.SetRoles([]string{"hr"})
+	cc.SetTrigger(CCOnLeave)
+
+	if got := cc.GetUsers(); len(got) != 1 || got[0] != "u1" {
+		t.Errorf("CarbonCopy.GetUsers() = %v, want [u1]", got)
+	}
+	if got := cc.GetRoles(); len(got) != 1 || got[0] != "hr" {
+		t.Errorf("CarbonCopy.GetRoles() = %v, want [hr]", got)
+	}
+	if got := cc.GetTrigger(); got != CCOnLeave {
+		t.Errorf("CarbonCopy.GetTrigger() = %v, want %v", got, CCOnLeave)
+	}
+}
+
+func TestCarbonCopy_Trigger(t *testing.T) {
+	tests := []struct {
+		name      string
+		cc        *CarbonCopy
+		wantEnter bool
+		wantLeave bool
+	}{
+		{name: "default", cc: &CarbonCopy{}, wantEnter: true, wantLeave: false},
+		{name: "enter", cc: &CarbonCopy{Trigger: CCOnEnter}, wantEnter: true, wantLeave: false},
+		{name: "leave", cc: &CarbonCopy{Trigger: CCOnLeave}, wantEnter: false, wantLeave: true},
+		{name: "both", cc: &CarbonCopy{Trigger: CCOnBoth}, wantEnter: true, wantLeave: true},
+		{name: "nil", cc: nil, wantEnter: false, wantLeave: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cc.OnEnter(); got != tt.wantEnter {
+				t.Errorf("OnEnter() = %v, want %v", got, tt.wantEnter)
+			}
+			if got := tt.cc.OnLeave(); got != tt.wantLeave {
+				t.Errorf("OnLeave() = %v, want %v", got, tt.wantLeave)
+			}
+		})
+	}
+}
+
+func TestTicket_CC(t *testing.T) {
+	ticket := &Ticket{}
+	ticket.SetCC([]string{"u1"})
+	ticket.AddCC("u2", "u1", "u2")
+	if got := ticket.GetCC(); len(got) != 2 {
+		t.Errorf("Ticket.GetCC() = %v, want [u1 u2]", got)
+	}
+
+	ticket.SetCCRoles([]string{"hr"})
+	ticket.AddCCRoles("hr", "finance")
+	if got := ticket.GetCCRoles(); len(got) != 2 {
+		t.Errorf("Ticket.GetCCRoles() = %v, want [hr finance]", got)
+	}
+
+	stepConfig := &StepConfig{}
+	stepConfig.SetCC(CarbonCopy{Users: []string{"u1"}, Trigger: CCOnBoth})
+	if got := stepConfig.GetCC(); got.Trigger != CCOnBoth || len(got.Users) != 1 {
+		t.Errorf("StepConfig.GetCC() = %v, want both [u1]", got)
+	}
+}
@@ -0,0 +1,117 @@
+package models
+
+// Clone 深拷贝工单，修改副本不会影响原工单
+func (t *Ticket) Clone() *Ticket {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	c.Operator = cloneStrings(t.Operator)
+	c.OperatedUser = cloneStrings(t.OperatedUser)
+	c.RejectedUser = cloneStrings(t.RejectedUser)
+	c.Tally = t.Tally.Clone()
+	c.FormData = cloneMap(t.FormData)
+	c.CC = cloneStrings(t.CC)
+	c.CCRoles = cloneStrings(t.CCRoles)
+	c.Children = cloneStrings(t.Children)
+	c.Visits = cloneCounts(t.Visits)
+	c.Traversals = cloneCounts(t.Traversals)
+	if t.History != nil {
+		c.History = make([]*Action, 0, len(t.History))
+		for _, a := range t.History {
+			c.History = append(c.History, a.Clone())
+		}
+	}
+	return &c
+}
+
+// Clone 深拷贝操作记录
+func (a *Action) Clone() *Action {
+	if a == nil {
+		return nil
+	}
+	c := *a
+	if a.Attachments != nil {
+		c.Attachments = make([]*Attachment, 0, len(a.Attachments))
+		for _, att := range a.Attachments {
+			if att == nil {
+				c.Attachments = append(c.Attachments, nil)
+				continue
+			}
+			copied := *att
+			c.Attachments = append(c.Attachments, &copied)
+		}
+	}
+	return &c
+}
+
+func cloneStrings(list []string) []string {
+	if list == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(list)), list...)
+}
+
+// Clone 深拷贝步骤配置
+func (sc *StepConfig) Clone() *StepConfig {
+	if sc == nil {
+		return nil
+	}
+	c := *sc
+	c.Operator = cloneStrings(sc.Operator)
+	c.Editable = cloneStrings(sc.Editable)
+	c.Hidden = cloneStrings(sc.Hidden)
+	c.CC.Users = cloneStrings(sc.CC.Users)
+	c.CC.Roles = cloneStrings(sc.CC.Roles)
+	c.Weights = cloneCounts(sc.Weights)
+	if sc.Next != nil {
+		c.Next = make([]*NextStep, 0, len(sc.Next))
+		for _, next := range sc.Next {
+			if next != nil {
+				n := *next
+				next = &n
+			}
+			c.Next = append(c.Next, next)
+		}
+	}
+	return &c
+}
+
+func cloneCounts(m map[string]int) map[string]int {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]int, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func cloneMap(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]any, len(m))
+	for k, v := range m {
+		c[k] = cloneValue(v)
+	}
+	return c
+}
+
+// cloneValue 拷贝表单数据中的引用类型，其余类型按值返回
+func cloneValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		return cloneMap(val)
+	case []any:
+		c := make([]any, 0, len(val))
+		for _, item := range val {
+			c = append(c, cloneValue(item))
+		}
+		return c
+	case []string:
+		return cloneStrings(val)
+	}
+	return v
+}
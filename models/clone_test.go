@@ -0,0 +1,78 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTicket_Clone(t *testing.T) {
+	origin := &Ticket{
+		Uid:          "t1",
+		Step:         "a",
+		Operator:     []string{"u1"},
+		OperatedUser: []string{"u2"},
+		FormData: map[string]any{
+			"amount":  100,
+			"tags":    []string{"x"},
+			"items":   []any{map[string]any{"n": 1}},
+			"details": map[string]any{"k": "v"},
+		},
+		History: []*Action{
+			{Operator: "u2", Attachments: []*Attachment{{Name: "a.pdf"}, nil}, CreatedAt: time.Unix(1, 0)},
+		},
+		CC:      []string{"c1"},
+		CCRoles: []string{"hr"},
+	}
+	c := origin.Clone()
+	if diff := cmp.Diff(c, origin); len(diff) > 0 {
+		t.Fatalf("Clone() diff = %v", diff)
+	}
+
+	c.Operator[0] = "changed"
+	c.OperatedUser[0] = "changed"
+	c.FormData["tags"].([]string)[0] = "changed"
+	c.FormData["items"].([]any)[0].(map[string]any)["n"] = 2
+	c.FormData["details"].(map[string]any)["k"] = "changed"
+	c.History[0].Operator = "changed"
+	c.History[0].Attachments[0].Name = "changed"
+	c.CC[0] = "changed"
+	c.CCRoles[0] = "changed"
+
+	want := &Ticket{
+		Uid:          "t1",
+		Step:         "a",
+		Operator:     []string{"u1"},
+		OperatedUser: []string{"u2"},
+		FormData: map[string]any{
+			"amount":  100,
+			"tags":    []string{"x"},
+			"items":   []any{map[string]any{"n": 1}},
+			"details": map[string]any{"k": "v"},
+		},
+		History: []*Action{
+			{Operator: "u2", Attachments: []*Attachment{{Name: "a.pdf"}, nil}, CreatedAt: time.Unix(1, 0)},
+		},
+		CC:      []string{"c1"},
+		CCRoles: []string{"hr"},
+	}
+	if diff := cmp.Diff(origin, want); len(diff) > 0 {
+		t.Errorf("origin modified through clone, diff = %v", diff)
+	}
+}
+
+func TestTicket_CloneNil(t *testing.T) {
+	var ticket *Ticket
+	if ticket.Clone() != nil {
+		t.Errorf("Clone() of nil ticket should be nil")
+	}
+	var action *Action
+	if action.Clone() != nil {
+		t.Errorf("Clone() of nil action should be nil")
+	}
+	empty := (&Ticket{}).Clone()
+	if empty.Operator != nil || empty.History != nil || empty.FormData != nil {
+		t.Errorf("Clone() of empty ticket = %+v, want nil slices", empty)
+	}
+}
@@ -6,15 +6,77 @@ const (
 	JointlySign = "jointly_sign"
 	SerialSign  = "serial_sign"
 	AnyoneSign  = "anyone_sign"
+	// WeightedSign 加权投票，同意权重达到Disposal.WeightThreshold时通过
+	WeightedSign = "weighted_sign"
 
 	Running  = "running"
 	Passed   = "passed"
 	Rejected = "rejected"
+	// Cancelled 工单撤销，结束步骤可声明此状态
+	Cancelled = "cancelled"
 
-	Reject = "reject"
+	Reject   = "reject"
+	Reassign = "reassign" // 管理员更换当前步骤处理人时记录的操作名
+	Jump     = "jump"     // 管理员将工单移动到任意步骤时记录的操作名
+	Rollback = "rollback" // 管理员将工单退回到已经过的步骤时记录的操作名
+	Reopen   = "reopen"   // 重新打开已结束工单时记录的操作名
+
+	// 管理员能力，越权操作时记录在Action.Override中，由Authorizer逐项授予
+	CapForceApprove = "force_approve" // 不在处理人列表中时执行非驳回操作
+	CapForceReject  = "force_reject"  // 不在处理人列表中时执行驳回操作
+	CapReassign     = "reassign"      // 更换当前步骤处理人
+	CapJump         = "jump"          // 将工单移动到任意步骤
+	CapRollback     = "rollback"      // 将工单退回到已经过的步骤
+
+	StepKindApproval   = "approval"    // 人工审批步骤
+	StepKindSubProcess = "sub_process" // 子流程步骤，进入时创建子工单，按子工单结束状态推进
+	StepKindService    = "service"     // 自动步骤，进入时执行已注册的处理器，按处理器结果推进
+
+	ServiceError = "error" // 自动步骤重试耗尽进入错误步骤时记录的操作名
+
+	SystemOperator = "system" // 引擎自动推进工单时记录的操作人
+
+	FieldString     = "string"
+	FieldNumber     = "number"
+	FieldDate       = "date"
+	FieldEnum       = "enum"
+	FieldUser       = "user"
+	FieldAttachment = "attachment"
+
+	ResolveLast      = "last"      // 以最后一名签署人选择的下一步骤为准
+	ResolveMajority  = "majority"  // 以得票（权重）最多的下一步骤为准
+	ResolveStrictest = "strictest" // 有人驳回时以驳回为准，否则以NextStep配置中最靠前的选择为准
+
+	LimitReject   = "reject"   // 超过环路次数限制时驳回工单
+	LimitEscalate = "escalate" // 超过环路次数限制时转入LoopLimit.Step
+
+	CCOnEnter = "enter"
+	CCOnLeave = "leave"
+	CCOnBoth  = "both"
+
+	EventSigned      = "signed"       // 会签/依次签中的部分签署，步骤未变化
+	EventStepLeft    = "step_left"    // 离开步骤
+	EventStepEntered = "step_entered" // 进入步骤
+	EventPassed      = "passed"       // 工单通过
+	EventRejected    = "rejected"     // 工单驳回
+	EventCancelled   = "cancelled"    // 工单撤销
+	EventClosed      = "closed"       // 工单以模板自定义的结束状态结束
+	EventChildOpened = "child_opened" // 进入子流程步骤，已创建子工单
+	EventReassigned  = "reassigned"   // 当前步骤处理人被更换
+	EventOverridden  = "overridden"   // 本次操作使用了管理员能力
+	EventJumped      = "jumped"       // 工单被管理员移动到任意步骤
+	EventRolledBack  = "rolled_back"  // 工单被管理员退回到已经过的步骤
+	EventReopened    = "reopened"     // 已结束的工单被重新打开
+	EventLoopLimited = "loop_limited" // 超过环路次数限制，工单被驳回或转入升级步骤
 )
 
 var (
-	TicketStatus     = set.Setify(Running, Passed, Rejected)
-	DisposalSignType = set.Setify(JointlySign, SerialSign, AnyoneSign)
+	TicketStatus     = set.Setify(Running, Passed, Rejected, Cancelled)
+	DisposalSignType = set.Setify(JointlySign, SerialSign, AnyoneSign, WeightedSign)
+	FormFieldType    = set.Setify(FieldString, FieldNumber, FieldDate, FieldEnum, FieldUser, FieldAttachment)
+	CCTrigger        = set.Setify(CCOnEnter, CCOnLeave, CCOnBoth)
+	Resolution       = set.Setify(ResolveLast, ResolveMajority, ResolveStrictest)
+	StepKind         = set.Setify(StepKindApproval, StepKindSubProcess, StepKindService)
+	LimitBehaviour   = set.Setify(LimitReject, LimitEscalate)
+	Capability       = set.Setify(CapForceApprove, CapForceReject, CapReassign, CapJump, CapRollback)
 )
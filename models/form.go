@@ -0,0 +1,97 @@
+package models
+
+import "github.com/victorwong171/go-utils/utils"
+
+// FormField 模板表单字段定义
+type FormField struct {
+	Name     string   `json:"name"`     // 字段名
+	Label    string   `json:"label"`    // 展示名称
+	Type     string   `json:"type"`     // string/number/date/enum/user/attachment
+	Required bool     `json:"required"` // 是否必填
+	Min      *float64 `json:"min"`      // number为最小值，string/user为最小长度，attachment为最少个数
+	Max      *float64 `json:"max"`      // number为最大值，string/user为最大长度，attachment为最多个数
+	Pattern  string   `json:"pattern"`  // 正则约束，仅string/user时使用
+	Options  []string `json:"options"`  // 可选值，仅enum时使用
+}
+
+// Getter methods for FormField
+func (f *FormField) GetName() string {
+	return utils.TernaryOperator(f == nil, "", f.Name)
+}
+
+func (f *FormField) GetLabel() string {
+	return utils.TernaryOperator(f == nil, "", f.Label)
+}
+
+func (f *FormField) GetType() string {
+	return utils.TernaryOperator(f == nil, "", f.Type)
+}
+
+func (f *FormField) GetRequired() bool {
+	return utils.TernaryOperator(f == nil, false, f.Required)
+}
+
+func (f *FormField) GetMin() *float64 {
+	return utils.TernaryOperator(f == nil, nil, f.Min)
+}
+
+func (f *FormField) GetMax() *float64 {
+	return utils.TernaryOperator(f == nil, nil, f.Max)
+}
+
+func (f *FormField) GetPattern() string {
+	return utils.TernaryOperator(f == nil, "", f.Pattern)
+}
+
+func (f *FormField) GetOptions() []string {
+	return utils.TernaryOperator(f == nil, nil, f.Options)
+}
+
+// Setter methods for FormField
+func (f *FormField) SetName(name string) {
+	if f != nil {
+		f.Name = name
+	}
+}
+
+func (f *FormField) SetLabel(label string) {
+	if f != nil {
+		f.Label = label
+	}
+}
+
+func (f *FormField) SetType(fieldType string) {
+	if f != nil {
+		f.Type = fieldType
+	}
+}
+
+func (f *FormField) SetRequired(required bool) {
+	if f != nil {
+		f.Required = required
+	}
+}
+
+func (f *FormField) SetMin(min float64) {
+	if f != nil {
+		f.Min = &min
+	}
+}
+
+func (f *FormField) SetMax(max float64) {
+	if f != nil {
+		f.Max = &max
+	}
+}
+
+func (f *FormField) SetPattern(pattern string) {
+	if f != nil {
+		f.Pattern = pattern
+	}
+}
+
+func (f *FormField) SetOptions(options []string) {
+	if f != nil {
+		f.Options = options
+	}
+}
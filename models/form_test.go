@@ -0,0 +1,88 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestFormField_GetterMethods(t *testing.T) {
+	min, max := 1.0, 10.0
+	field := &FormField{
+		Name:     "amount",
+		Label:    "金额",
+		Type:     FieldNumber,
+		Required: true,
+		Min:      &min,
+		Max:      &max,
+		Pattern:  `^\d+$`,
+		Options:  []string{"a", "b"},
+	}
+
+	if got := field.GetName(); got != "amount" {
+		t.Errorf("FormField.GetName() = %v, want amount", got)
+	}
+	if got := field.GetLabel(); got != "金额" {
+		t.Errorf("FormField.GetLabel() = %v, want 金额", got)
+	}
+	if got := field.GetType(); got != FieldNumber {
+		t.Errorf("FormField.GetType() = %v, want %v", got, FieldNumber)
+	}
+	if got := field.GetRequired(); got != true {
+		t.Errorf("FormField.GetRequired() = %v, want true", got)
+	}
+	if got := field.GetMin(); got == nil || *got != 1 {
+		t.Errorf("FormField.GetMin() = %v, want 1", got)
+	}
+	if got := field.GetMax(); got == nil || *got != 10 {
+		t.Errorf("FormField.GetMax() = %v, want 10", got)
+	}
+	if got := field.GetPattern(); got != `^\d+$` {
+		t.Errorf("FormField.GetPattern() = %v, want ^\\d+$", got)
+	}
+	if got := field.GetOptions(); len(got) != 2 {
+		t.Errorf("FormField.GetOptions() length = %v, want 2", len(got))
+	}
+}
+
+func TestFormField_SetterMethods(t *testing.T) {
+	field := &FormField{}
+
+	field.SetName("kind")
+	if field.Name != "kind" {
+		t.Errorf("SetName failed, got %v, want kind", field.Name)
+	}
+
+	field.SetLabel("类型")
+	if field.Label != "类型" {
+		t.Errorf("SetLabel failed, got %v, want 类型", field.Label)
+	}
+
+	field.SetType(FieldEnum)
+	if field.Type != FieldEnum {
+		t.Errorf("SetType failed, got %v, want %v", field.Type, FieldEnum)
+	}
+
+	field.SetRequired(true)
+	if !field.Required {
+		t.Errorf("SetRequired failed, got %v, want true", field.Required)
+	}
+
+	field.SetMin(2)
+	if field.Min == nil || *field.Min != 2 {
+		t.Errorf("SetMin failed, got %v, want 2", field.Min)
+	}
+
+	field.SetMax(5)
+	if field.Max == nil || *field.Max != 5 {
+		t.Errorf("SetMax failed, got %v, want 5", field.Max)
+	}
+
+	field.SetPattern("^a")
+	if field.Pattern != "^a" {
+		t.Errorf("SetPattern failed, got %v, want ^a", field.Pattern)
+	}
+
+	field.SetOptions([]string{"travel", "meal"})
+	if len(field.Options) != 2 {
+		t.Errorf("SetOptions failed, got length %v, want 2", len(field.Options))
+	}
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/victorwong171/go-utils/utils"
+)
+
+// FragmentNext 片段内NextStep指向片段之后步骤的默认出口，由Include.Next连接
+const FragmentNext = "$next"
+
+// Fragment 可在多个模板中复用的步骤片段，如 manager → director → finance
+// 片段内NextStep.Step只能是片段内步骤或以$开头的出口，出口在引用时连接到模板步骤
+type Fragment struct {
+	Name   string        `json:"name"`   // 片段名
+	Entry  string        `json:"entry"`  // 入口步骤
+	Config []*StepConfig `json:"config"` // 片段内步骤配置
+}
+
+// Getter methods for Fragment
+func (f *Fragment) GetName() string {
+	return utils.TernaryOperator(f == nil, "", f.Name)
+}
+
+func (f *Fragment) GetEntry() string {
+	return utils.TernaryOperator(f == nil, "", f.Entry)
+}
+
+func (f *Fragment) GetConfig() []*StepConfig {
+	return utils.TernaryOperator(f == nil, nil, f.Config)
+}
+
+// Setter methods for Fragment
+func (f *Fragment) SetName(name string) {
+	if f != nil {
+		f.Name = name
+	}
+}
+
+func (f *Fragment) SetEntry(entry string) {
+	if f != nil {
+		f.Entry = entry
+	}
+}
+
+func (f *Fragment) SetConfig(config []*StepConfig) {
+	if f != nil {
+		f.Config = config
+	}
+}
+
+// Include 模板对片段的引用，展开后片段内步骤名为 Prefix.步骤名
+// 模板中以Prefix作为步骤名的引用在展开时指向片段入口
+type Include struct {
+	Fragment  string              `json:"fragment"`  // 片段名
+	Prefix    string              `json:"prefix"`    // 展开后的步骤名前缀，默认为片段名
+	Operators map[string][]string `json:"operators"` // 按片段内步骤名覆盖预设操作人
+	Next      string              `json:"next"`      // $next出口连接的步骤
+	Exits     map[string]string   `json:"exits"`     // 其他出口连接的步骤，如 $reject: apply
+}
+
+// Getter methods for Include
+func (i *Include) GetFragment() string {
+	return utils.TernaryOperator(i == nil, "", i.Fragment)
+}
+
+func (i *Include) GetOperators() map[string][]string {
+	return utils.TernaryOperator(i == nil, nil, i.Operators)
+}
+
+func (i *Include) GetNext() string {
+	return utils.TernaryOperator(i == nil, "", i.Next)
+}
+
+func (i *Include) GetExits() map[string]string {
+	return utils.TernaryOperator(i == nil, nil, i.Exits)
+}
+
+// GetPrefix 返回展开后的步骤名前缀，未设置时为片段名
+func (i *Include) GetPrefix() string {
+	if i == nil {
+		return ""
+	}
+	return utils.TernaryOperator(len(i.Prefix) == 0, i.Fragment, i.Prefix)
+}
+
+// Setter methods for Include
+func (i *Include) SetFragment(fragment string) {
+	if i != nil {
+		i.Fragment = fragment
+	}
+}
+
+func (i *Include) SetPrefix(prefix string) {
+	if i != nil {
+		i.Prefix = prefix
+	}
+}
+
+func (i *Include) SetOperators(operators map[string][]string) {
+	if i != nil {
+		i.Operators = operators
+	}
+}
+
+func (i *Include) SetNext(next string) {
+	if i != nil {
+		i.Next = next
+	}
+}
+
+func (i *Include) SetExits(exits map[string]string) {
+	if i != nil {
+		i.Exits = exits
+	}
+}
+
+// Exit 返回出口连接的步骤，出口未连接时返回false
+func (i *Include) Exit(exit string) (string, bool) {
+	if i == nil {
+		return "", false
+	}
+	if exit == FragmentNext && len(i.Next) > 0 {
+		return i.Next, true
+	}
+	step, ok := i.Exits[exit]
+	return step, ok && len(step) > 0
+}
+
+// IsFragmentExit 步骤名是否为片段出口
+func IsFragmentExit(step string) bool {
+	return strings.HasPrefix(step, "$")
+}
@@ -0,0 +1,62 @@
+package models
+
+import "github.com/victorwong171/go-utils/utils"
+
+// LoopLimit 返工环路的进入次数限制，MaxVisits为0表示不限制
+type LoopLimit struct {
+	MaxVisits  int    `json:"max_visits"`  // 最多进入次数，发起工单时位于开始步骤不计入
+	OnExceeded string `json:"on_exceeded"` // 超过次数时的处理，reject/escalate，默认reject
+	Step       string `json:"step"`        // 仅escalate时使用，超过次数后转入的步骤
+}
+
+// Getter methods for LoopLimit
+func (l *LoopLimit) GetMaxVisits() int {
+	return utils.TernaryOperator(l == nil, 0, l.MaxVisits)
+}
+
+func (l *LoopLimit) GetOnExceeded() string {
+	return utils.TernaryOperator(l == nil, "", l.OnExceeded)
+}
+
+func (l *LoopLimit) GetStep() string {
+	return utils.TernaryOperator(l == nil, "", l.Step)
+}
+
+// Setter methods for LoopLimit
+func (l *LoopLimit) SetMaxVisits(maxVisits int) {
+	if l != nil {
+		l.MaxVisits = maxVisits
+	}
+}
+
+func (l *LoopLimit) SetOnExceeded(onExceeded string) {
+	if l != nil {
+		l.OnExceeded = onExceeded
+	}
+}
+
+func (l *LoopLimit) SetStep(step string) {
+	if l != nil {
+		l.Step = step
+	}
+}
+
+// Enabled 是否配置了次数限制
+func (l *LoopLimit) Enabled() bool {
+	return l != nil && l.MaxVisits > 0
+}
+
+// Exceeded visits次进入后是否超过限制
+func (l *LoopLimit) Exceeded(visits int) bool {
+	return l.Enabled() && visits > l.MaxVisits
+}
+
+// Escalates 超过次数时是否转入其他步骤
+func (l *LoopLimit) Escalates() bool {
+	return l != nil && l.OnExceeded == LimitEscalate
+}
+
+// EdgeKey Ticket.Traversals中from到to的流转的键
+func EdgeKey(from, to string) string {
+	return from + "->" + to
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"github.com/victorwong171/go-utils/desc/set"
+	"github.com/victorwong171/go-utils/utils"
+)
+
+// ReopenPolicy 模板的重新打开策略，Statuses为空时不允许重新打开
+type ReopenPolicy struct {
+	Statuses  []string `json:"statuses"`  // 允许重新打开的结束状态，如 passed/rejected
+	Operators []string `json:"operators"` // 允许重新打开的用户，为空时不限制
+	MaxTimes  int      `json:"max_times"` // 最多重新打开次数，0表示不限制
+}
+
+// Getter methods for ReopenPolicy
+func (p *ReopenPolicy) GetStatuses() []string {
+	return utils.TernaryOperator(p == nil, nil, p.Statuses)
+}
+
+func (p *ReopenPolicy) GetOperators() []string {
+	return utils.TernaryOperator(p == nil, nil, p.Operators)
+}
+
+func (p *ReopenPolicy) GetMaxTimes() int {
+	return utils.TernaryOperator(p == nil, 0, p.MaxTimes)
+}
+
+// Setter methods for ReopenPolicy
+func (p *ReopenPolicy) SetStatuses(statuses []string) {
+	if p != nil {
+		p.Statuses = statuses
+	}
+}
+
+func (p *ReopenPolicy) SetOperators(operators []string) {
+	if p != nil {
+		p.Operators = operators
+	}
+}
+
+func (p *ReopenPolicy) SetMaxTimes(maxTimes int) {
+	if p != nil {
+		p.MaxTimes = maxTimes
+	}
+}
+
+// Allows 处于status的工单是否允许重新打开
+func (p *ReopenPolicy) Allows(status string) bool {
+	if p == nil {
+		return false
+	}
+	return set.Setify(p.Statuses...).HasKey(status)
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/victorwong171/go-utils/utils"
+)
+
+// ServiceTask 自动步骤配置
+type ServiceTask struct {
+	Handler   string        `json:"handler"`    // 已注册的处理器名，如 create-jira-issue
+	Retries   int           `json:"retries"`    // 失败后的重试次数
+	Backoff   time.Duration `json:"backoff"`    // 首次重试前的等待时间，之后每次翻倍
+	ErrorStep string        `json:"error_step"` // 重试耗尽后进入的步骤，为空时返回错误
+}
+
+// Getter methods for ServiceTask
+func (s *ServiceTask) GetHandler() string {
+	return utils.TernaryOperator(s == nil, "", s.Handler)
+}
+
+func (s *ServiceTask) GetRetries() int {
+	return utils.TernaryOperator(s == nil, 0, s.Retries)
+}
+
+func (s *ServiceTask) GetBackoff() time.Duration {
+	return utils.TernaryOperator(s == nil, 0, s.Backoff)
+}
+
+func (s *ServiceTask) GetErrorStep() string {
+	return utils.TernaryOperator(s == nil, "", s.ErrorStep)
+}
+
+// Setter methods for ServiceTask
+func (s *ServiceTask) SetHandler(handler string) {
+	if s != nil {
+		s.Handler = handler
+	}
+}
+
+func (s *ServiceTask) SetRetries(retries int) {
+	if s != nil {
+		s.Retries = retries
+	}
+}
+
+func (s *ServiceTask) SetBackoff(backoff time.Duration) {
+	if s != nil {
+		s.Backoff = backoff
+	}
+}
+
+func (s *ServiceTask) SetErrorStep(errorStep string) {
+	if s != nil {
+		s.ErrorStep = errorStep
+	}
+}
+
+// IsService 是否为自动步骤
+func (sc *StepConfig) IsService() bool {
+	return sc != nil && sc.Kind == StepKindService
+}
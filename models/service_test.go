@@ -0,0 +1,46 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServiceTask_GetterSetter(t *testing.T) {
+	s := &ServiceTask{}
+	s.SetHandler("create-jira-issue")
+	s.SetRetries(3)
+	s.SetBackoff(time.Second)
+	s.SetErrorStep("manual")
+
+	if got := s.GetHandler(); got != "create-jira-issue" {
+		t.Errorf("ServiceTask.GetHandler() = %v, want create-jira-issue", got)
+	}
+	if got := s.GetRetries(); got != 3 {
+		t.Errorf("ServiceTask.GetRetries() = %v, want 3", got)
+	}
+	if got := s.GetBackoff(); got != time.Second {
+		t.Errorf("ServiceTask.GetBackoff() = %v, want 1s", got)
+	}
+	if got := s.GetErrorStep(); got != "manual" {
+		t.Errorf("ServiceTask.GetErrorStep() = %v, want manual", got)
+	}
+}
+
+func TestStepConfig_IsService(t *testing.T) {
+	tests := []struct {
+		name string
+		sc   *StepConfig
+		want bool
+	}{
+		{name: "service", sc: &StepConfig{Kind: StepKindService}, want: true},
+		{name: "approval", sc: &StepConfig{}, want: false},
+		{name: "nil", sc: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sc.IsService(); got != tt.want {
+				t.Errorf("IsService() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
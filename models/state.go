@@ -0,0 +1,30 @@
+package models
+
+import "github.com/victorwong171/go-utils/desc/set"
+
+// GroupByState 按业务状态对工单分组，未声明业务状态的工单归入空字符串分组
+func GroupByState(tickets []*Ticket) map[string][]*Ticket {
+	groups := make(map[string][]*Ticket)
+	for _, t := range tickets {
+		if t == nil {
+			continue
+		}
+		groups[t.State] = append(groups[t.State], t)
+	}
+	return groups
+}
+
+// FilterByState 返回处于任一指定业务状态的工单，保持原有顺序
+func FilterByState(tickets []*Ticket, states ...string) []*Ticket {
+	wanted := set.Setify(states...)
+	var result []*Ticket
+	for _, t := range tickets {
+		if t == nil {
+			continue
+		}
+		if wanted.HasKey(t.State) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGroupByState(t *testing.T) {
+	tickets := []*Ticket{
+		{Uid: "t1", State: "in_review"},
+		{Uid: "t2", State: "awaiting_payment"},
+		nil,
+		{Uid: "t3", State: "in_review"},
+		{Uid: "t4"},
+	}
+	got := make(map[string][]string)
+	for state, group := range GroupByState(tickets) {
+		for _, t := range group {
+			got[state] = append(got[state], t.Uid)
+		}
+	}
+	want := map[string][]string{
+		"in_review":        {"t1", "t3"},
+		"awaiting_payment": {"t2"},
+		"":                 {"t4"},
+	}
+	if diff := cmp.Diff(got, want); len(diff) > 0 {
+		t.Errorf("GroupByState() diff = %v", diff)
+	}
+
+	var uids []string
+	for _, t := range FilterByState(tickets, "in_review", "awaiting_payment") {
+		uids = append(uids, t.Uid)
+	}
+	if diff := cmp.Diff(uids, []string{"t1", "t2", "t3"}); len(diff) > 0 {
+		t.Errorf("FilterByState() diff = %v", diff)
+	}
+}
+
+func TestTicketTemplate_StepsInState(t *testing.T) {
+	tpl := &TicketTemplate{Config: []*StepConfig{
+		{Step: "leader", State: "in_review"},
+		{Step: "pay", State: "awaiting_payment"},
+		{Step: "hr", State: "in_review"},
+	}}
+	if diff := cmp.Diff(tpl.StepsInState("in_review"), []string{"leader", "hr"}); len(diff) > 0 {
+		t.Errorf("StepsInState() diff = %v", diff)
+	}
+	var nilTpl *TicketTemplate
+	if got := nilTpl.StepsInState("in_review"); got != nil {
+		t.Errorf("nil StepsInState() = %v, want nil", got)
+	}
+}
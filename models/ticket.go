@@ -1,16 +1,32 @@
 package models
 
-import "github.com/victorwong171/go-utils/utils"
+import (
+	"github.com/victorwong171/go-utils/desc/set"
+	"github.com/victorwong171/go-utils/utils"
+)
 
 type Ticket struct {
-	OrderNum     string   `json:"order_num"`     // 工单号
-	Name         string   `json:"name"`          // 工单名称
-	Status       string   `json:"status"`        // running/passed/rejected
-	Uid          string   `json:"uid"`           // 工单唯一标识
-	Step         string   `json:"step"`          // 当前步骤
-	Operator     []string `json:"operator"`      // 操作人列表
-	OperatedUser []string `json:"operated_user"` // 在Disposal.SignType为jointly_sign/serial_sign时使用
-	Memo         string   `json:"memo"`          // 备注
+	OrderNum     string         `json:"order_num"`     // 工单号
+	Name         string         `json:"name"`          // 工单名称
+	Status       string         `json:"status"`        // running/passed/rejected/cancelled或模板自定义的结束状态
+	Uid          string         `json:"uid"`           // 工单唯一标识
+	Step         string         `json:"step"`          // 当前步骤
+	State        string         `json:"state"`         // 当前步骤所属的业务状态，取自StepConfig.State
+	Operator     []string       `json:"operator"`      // 操作人列表
+	OperatedUser []string       `json:"operated_user"` // 在Disposal.SignType为jointly_sign/serial_sign时使用
+	RejectedUser []string       `json:"rejected_user"` // 在Disposal.SignType为jointly_sign时记录投反对票的用户
+	Tally        *Tally         `json:"tally"`         // 多人审批步骤中每名签署人的选择及计票结果
+	Memo         string         `json:"memo"`          // 备注
+	FormData     map[string]any `json:"form_data"`     // 表单数据，结构由TicketTemplate.Form定义
+	History      []*Action      `json:"history"`       // 操作记录
+	CC           []string       `json:"cc"`            // 抄送用户，仅可查看，无审批权限
+	CCRoles      []string       `json:"cc_roles"`      // 抄送角色
+	Template     string         `json:"template"`      // 所属模板唯一标识
+	Parent       string         `json:"parent"`        // 父工单唯一标识，仅子流程创建的工单使用
+	Children     []string       `json:"children"`      // 子流程创建的子工单唯一标识
+	Reopened     int            `json:"reopened"`      // 结束后被重新打开的次数
+	Visits       map[string]int `json:"visits"`        // 各步骤的进入次数
+	Traversals   map[string]int `json:"traversals"`    // 各流转的经过次数，键为EdgeKey(from, to)
 }
 
 // Getter methods for Ticket
@@ -46,6 +62,26 @@ func (t *Ticket) GetMemo() string {
 	return utils.TernaryOperator(t == nil, "", t.Memo)
 }
 
+func (t *Ticket) GetFormData() map[string]any {
+	return utils.TernaryOperator(t == nil, nil, t.FormData)
+}
+
+func (t *Ticket) GetHistory() []*Action {
+	return utils.TernaryOperator(t == nil, nil, t.History)
+}
+
+func (t *Ticket) GetCC() []string {
+	return utils.TernaryOperator(t == nil, nil, t.CC)
+}
+
+func (t *Ticket) GetCCRoles() []string {
+	return utils.TernaryOperator(t == nil, nil, t.CCRoles)
+}
+
+func (t *Ticket) GetTemplate() string {
+	return utils.TernaryOperator(t == nil, "", t.Template)
+}
+
 // Setter methods for Ticket
 func (t *Ticket) SetName(name string) {
 	if t != nil {
@@ -83,6 +119,96 @@ func (t *Ticket) SetOperator(operator []string) {
 	}
 }
 
+func (t *Ticket) GetRejectedUser() []string {
+	return utils.TernaryOperator(t == nil, nil, t.RejectedUser)
+}
+
+func (t *Ticket) GetState() string {
+	return utils.TernaryOperator(t == nil, "", t.State)
+}
+
+func (t *Ticket) SetState(state string) {
+	if t != nil {
+		t.State = state
+	}
+}
+
+func (t *Ticket) GetTally() *Tally {
+	return utils.TernaryOperator(t == nil, nil, t.Tally)
+}
+
+func (t *Ticket) SetTally(tally *Tally) {
+	if t != nil {
+		t.Tally = tally
+	}
+}
+
+func (t *Ticket) GetParent() string {
+	return utils.TernaryOperator(t == nil, "", t.Parent)
+}
+
+func (t *Ticket) GetReopened() int {
+	return utils.TernaryOperator(t == nil, 0, t.Reopened)
+}
+
+func (t *Ticket) SetReopened(reopened int) {
+	if t != nil {
+		t.Reopened = reopened
+	}
+}
+
+func (t *Ticket) GetVisits() map[string]int {
+	return utils.TernaryOperator(t == nil, nil, t.Visits)
+}
+
+func (t *Ticket) GetTraversals() map[string]int {
+	return utils.TernaryOperator(t == nil, nil, t.Traversals)
+}
+
+// Visit 记录从from进入to，返回to的进入次数与该流转的经过次数
+func (t *Ticket) Visit(from, to string) (visits, traversals int) {
+	if t == nil {
+		return 0, 0
+	}
+	if t.Visits == nil {
+		t.Visits = make(map[string]int)
+	}
+	if t.Traversals == nil {
+		t.Traversals = make(map[string]int)
+	}
+	t.Visits[to]++
+	t.Traversals[EdgeKey(from, to)]++
+	return t.Visits[to], t.Traversals[EdgeKey(from, to)]
+}
+
+func (t *Ticket) GetChildren() []string {
+	return utils.TernaryOperator(t == nil, nil, t.Children)
+}
+
+func (t *Ticket) SetParent(parent string) {
+	if t != nil {
+		t.Parent = parent
+	}
+}
+
+func (t *Ticket) SetChildren(children []string) {
+	if t != nil {
+		t.Children = children
+	}
+}
+
+func (t *Ticket) AddChildren(children ...string) {
+	if t != nil {
+		t.Children = append(t.Children, children...)
+	}
+}
+
+func (t *Ticket) SetRejectedUser(rejectedUser []string) {
+	if t != nil {
+		t.RejectedUser = rejectedUser
+	}
+}
+
 func (t *Ticket) SetOperatedUser(operatedUser []string) {
 	if t != nil {
 		t.OperatedUser = operatedUser
@@ -95,6 +221,36 @@ func (t *Ticket) SetMemo(memo string) {
 	}
 }
 
+func (t *Ticket) SetFormData(formData map[string]any) {
+	if t != nil {
+		t.FormData = formData
+	}
+}
+
+func (t *Ticket) SetHistory(history []*Action) {
+	if t != nil {
+		t.History = history
+	}
+}
+
+func (t *Ticket) SetCC(cc []string) {
+	if t != nil {
+		t.CC = cc
+	}
+}
+
+func (t *Ticket) SetCCRoles(roles []string) {
+	if t != nil {
+		t.CCRoles = roles
+	}
+}
+
+func (t *Ticket) SetTemplate(template string) {
+	if t != nil {
+		t.Template = template
+	}
+}
+
 // Add methods for slice fields
 func (t *Ticket) AddOperator(operator ...string) {
 	if t != nil {
@@ -108,9 +264,33 @@ func (t *Ticket) AddOperatedUser(operatedUser ...string) {
 	}
 }
 
+func (t *Ticket) AddHistory(action ...*Action) {
+	if t != nil {
+		t.History = append(t.History, action...)
+	}
+}
+
+// AddCC 添加抄送用户，已存在的用户不重复添加
+func (t *Ticket) AddCC(cc ...string) {
+	if t != nil {
+		t.CC = appendUnique(t.CC, cc...)
+	}
+}
+
+// AddCCRoles 添加抄送角色，已存在的角色不重复添加
+func (t *Ticket) AddCCRoles(roles ...string) {
+	if t != nil {
+		t.CCRoles = appendUnique(t.CCRoles, roles...)
+	}
+}
+
 type Disposal struct {
-	SignType      string  `json:"sign_type"`       // jointly_sign/serial_sign/anyone_sign
-	JointSignRate float32 `json:"joint_sign_rate"` // 仅jointly_sign时使用
+	SignType        string  `json:"sign_type"`        // jointly_sign/serial_sign/anyone_sign/weighted_sign
+	JointSignRate   float32 `json:"joint_sign_rate"`  // 仅jointly_sign时使用
+	JointSignCount  int     `json:"joint_sign_count"` // 仅jointly_sign时使用，同意人数阈值，大于0时优先于JointSignRate
+	Veto            bool    `json:"veto"`             // 仅jointly_sign时使用，任一操作人驳回即驳回本步骤
+	WeightThreshold int     `json:"weight_threshold"` // 仅weighted_sign时使用，通过所需的同意权重
+	Resolution      string  `json:"resolution"`       // 多人审批时签署人选择不同下一步骤的处理方式，last/majority/strictest，默认last
 }
 
 // Getter methods for Disposal
@@ -122,6 +302,22 @@ func (d *Disposal) GetJointSignRate() float32 {
 	return utils.TernaryOperator(d == nil, 0.0, d.JointSignRate)
 }
 
+func (d *Disposal) GetJointSignCount() int {
+	return utils.TernaryOperator(d == nil, 0, d.JointSignCount)
+}
+
+func (d *Disposal) GetVeto() bool {
+	return utils.TernaryOperator(d == nil, false, d.Veto)
+}
+
+func (d *Disposal) GetWeightThreshold() int {
+	return utils.TernaryOperator(d == nil, 0, d.WeightThreshold)
+}
+
+func (d *Disposal) GetResolution() string {
+	return utils.TernaryOperator(d == nil, "", d.Resolution)
+}
+
 // Setter methods for Disposal
 func (d *Disposal) SetSignType(signType string) {
 	if d != nil {
@@ -135,14 +331,44 @@ func (d *Disposal) SetJointSignRate(rate float32) {
 	}
 }
 
+func (d *Disposal) SetJointSignCount(count int) {
+	if d != nil {
+		d.JointSignCount = count
+	}
+}
+
+func (d *Disposal) SetVeto(veto bool) {
+	if d != nil {
+		d.Veto = veto
+	}
+}
+
+func (d *Disposal) SetWeightThreshold(threshold int) {
+	if d != nil {
+		d.WeightThreshold = threshold
+	}
+}
+
+func (d *Disposal) SetResolution(resolution string) {
+	if d != nil {
+		d.Resolution = resolution
+	}
+}
+
 // 发起工单时 可以直接使用模版 或者自定义模版 自定义模版需要
 type TicketTemplate struct {
-	Uid       string        `json:"uid"`        // 模板唯一标识
-	Name      string        `json:"name"`       // 模板名称
-	EndStep   []string      `json:"end_step"`   // 结束节点
-	StartStep string        `json:"start_step"` // 开始节点
-	Config    []*StepConfig `json:"config"`     // 配置
-	Builtin   bool          `json:"builtin"`    // 是否内置
+	Uid            string        `json:"uid"`              // 模板唯一标识
+	Name           string        `json:"name"`             // 模板名称
+	EndStep        []string      `json:"end_step"`         // 结束节点
+	StartStep      string        `json:"start_step"`       // 开始节点
+	Config         []*StepConfig `json:"config"`           // 配置
+	Builtin        bool          `json:"builtin"`          // 是否内置
+	OrderNumPrefix string        `json:"order_num_prefix"` // 工单号前缀，如 HR
+	Form           []*FormField  `json:"form"`             // 表单定义
+	Statuses       []string      `json:"statuses"`         // 模板自定义的工单结束状态，如 closed_as_duplicate
+	States         []string      `json:"states"`           // 步骤可用的业务状态，如 in_review/awaiting_payment
+	Reopen         ReopenPolicy  `json:"reopen"`           // 重新打开策略，默认不允许
+	Includes       []*Include    `json:"includes"`         // 引用的步骤片段，加载时由template.Expand展开
 }
 
 // Getter methods for TicketTemplate
@@ -170,6 +396,14 @@ func (tt *TicketTemplate) GetBuiltin() bool {
 	return utils.TernaryOperator(tt == nil, false, tt.Builtin)
 }
 
+func (tt *TicketTemplate) GetOrderNumPrefix() string {
+	return utils.TernaryOperator(tt == nil, "", tt.OrderNumPrefix)
+}
+
+func (tt *TicketTemplate) GetForm() []*FormField {
+	return utils.TernaryOperator(tt == nil, nil, tt.Form)
+}
+
 // Setter methods for TicketTemplate
 func (tt *TicketTemplate) SetName(name string) {
 	if tt != nil {
@@ -207,6 +441,18 @@ func (tt *TicketTemplate) SetBuiltin(builtin bool) {
 	}
 }
 
+func (tt *TicketTemplate) SetOrderNumPrefix(prefix string) {
+	if tt != nil {
+		tt.OrderNumPrefix = prefix
+	}
+}
+
+func (tt *TicketTemplate) SetForm(form []*FormField) {
+	if tt != nil {
+		tt.Form = form
+	}
+}
+
 // Add methods for slice fields
 func (tt *TicketTemplate) AddEndStep(endStep ...string) {
 	if tt != nil {
@@ -220,12 +466,123 @@ func (tt *TicketTemplate) AddConfig(config ...*StepConfig) {
 	}
 }
 
+func (tt *TicketTemplate) AddForm(form ...*FormField) {
+	if tt != nil {
+		tt.Form = append(tt.Form, form...)
+	}
+}
+
+func (tt *TicketTemplate) GetStatuses() []string {
+	return utils.TernaryOperator(tt == nil, nil, tt.Statuses)
+}
+
+func (tt *TicketTemplate) SetStatuses(statuses []string) {
+	if tt != nil {
+		tt.Statuses = statuses
+	}
+}
+
+func (tt *TicketTemplate) AddStatuses(statuses ...string) {
+	if tt != nil {
+		tt.Statuses = append(tt.Statuses, statuses...)
+	}
+}
+
+func (tt *TicketTemplate) GetStates() []string {
+	return utils.TernaryOperator(tt == nil, nil, tt.States)
+}
+
+func (tt *TicketTemplate) SetStates(states []string) {
+	if tt != nil {
+		tt.States = states
+	}
+}
+
+func (tt *TicketTemplate) AddStates(states ...string) {
+	if tt != nil {
+		tt.States = append(tt.States, states...)
+	}
+}
+
+func (tt *TicketTemplate) GetReopen() ReopenPolicy {
+	return utils.TernaryOperator(tt == nil, ReopenPolicy{}, tt.Reopen)
+}
+
+func (tt *TicketTemplate) SetReopen(reopen ReopenPolicy) {
+	if tt != nil {
+		tt.Reopen = reopen
+	}
+}
+
+func (tt *TicketTemplate) GetIncludes() []*Include {
+	return utils.TernaryOperator(tt == nil, nil, tt.Includes)
+}
+
+func (tt *TicketTemplate) SetIncludes(includes []*Include) {
+	if tt != nil {
+		tt.Includes = includes
+	}
+}
+
+func (tt *TicketTemplate) AddIncludes(includes ...*Include) {
+	if tt != nil {
+		tt.Includes = append(tt.Includes, includes...)
+	}
+}
+
+// StepsInState 返回属于指定业务状态的步骤，按配置顺序排列
+func (tt *TicketTemplate) StepsInState(state string) []string {
+	if tt == nil {
+		return nil
+	}
+	var steps []string
+	for _, c := range tt.Config {
+		if c != nil && c.State == state {
+			steps = append(steps, c.Step)
+		}
+	}
+	return steps
+}
+
+// TicketStatus 模板可用的工单状态，包括内置状态与模板自定义状态
+func (tt *TicketTemplate) TicketStatus() set.Set[string] {
+	statuses := set.Setify(TicketStatus.ToSlice()...)
+	if tt != nil {
+		statuses.Set(tt.Statuses...)
+	}
+	return statuses
+}
+
+// StepConfigMap 按步骤名索引步骤配置
+func (tt *TicketTemplate) StepConfigMap() map[string]*StepConfig {
+	if tt == nil {
+		return map[string]*StepConfig{}
+	}
+	stepMap := make(map[string]*StepConfig, len(tt.Config))
+	for _, c := range tt.Config {
+		if c != nil {
+			stepMap[c.Step] = c
+		}
+	}
+	return stepMap
+}
+
 type StepConfig struct {
-	Step     string      `json:"step"`     // 步骤名
-	State    string      `json:"state"`    // 步骤所属状态
-	Operator []string    `json:"operator"` // 预设操作人
-	Next     []*NextStep `json:"next"`     // 下一节点
-	Disposal Disposal    `json:"disposal"` // 处置方式
+	Step       string         `json:"step"`        // 步骤名
+	State      string         `json:"state"`       // 步骤所属状态
+	Operator   []string       `json:"operator"`    // 预设操作人
+	Next       []*NextStep    `json:"next"`        // 下一节点
+	Disposal   Disposal       `json:"disposal"`    // 处置方式
+	Editable   []string       `json:"editable"`    // 本步骤可编辑的表单字段
+	Hidden     []string       `json:"hidden"`      // 本步骤不可见的表单字段
+	CC         CarbonCopy     `json:"cc"`          // 抄送配置
+	Weights    map[string]int `json:"weights"`     // 仅weighted_sign时使用，操作人投票权重，未配置的操作人权重为1
+	Kind       string         `json:"kind"`        // approval/sub_process/service，默认approval
+	Template   string         `json:"template"`    // 仅sub_process时使用，子流程模板唯一标识；Next的Operation为子工单结束状态passed/rejected
+	Service    ServiceTask    `json:"service"`     // 仅service时使用，Next的Operation为处理器返回的结果
+	EndStatus  string         `json:"end_status"`  // 仅结束步骤使用，到达时工单的状态，默认passed
+	Limit      LoopLimit      `json:"limit"`       // 进入本步骤的次数限制，用于返工环路
+	NoOverride bool           `json:"no_override"` // 禁止管理员越权操作本步骤，敏感步骤只能由处理人审批
 }
 
 // Getter methods for StepConfig
@@ -234,7 +591,10 @@ func (sc *StepConfig) GetStep() string {
 }
 
 func (sc *StepConfig) GetState() string {
-	return utils.TernaryOperator(sc == nil, "", sc.State)
+	if sc == nil {
+		return ""
+	}
+	return sc.State
 }
 
 func (sc *StepConfig) GetOperator() []string {
@@ -249,6 +609,57 @@ func (sc *StepConfig) GetDisposal() Disposal {
 	return utils.TernaryOperator(sc == nil, Disposal{}, sc.Disposal)
 }
 
+func (sc *StepConfig) GetEditable() []string {
+	if sc == nil {
+		return nil
+	}
+	return sc.Editable
+}
+
+func (sc *StepConfig) GetHidden() []string {
+	if sc == nil {
+		return nil
+	}
+	return sc.Hidden
+}
+
+func (sc *StepConfig) GetCC() CarbonCopy {
+	return utils.TernaryOperator(sc == nil, CarbonCopy{}, sc.CC)
+}
+
+func (sc *StepConfig) GetWeights() map[string]int {
+	return utils.TernaryOperator(sc == nil, nil, sc.Weights)
+}
+
+func (sc *StepConfig) GetKind() string {
+	return utils.TernaryOperator(sc == nil, "", sc.Kind)
+}
+
+func (sc *StepConfig) GetTemplate() string {
+	return utils.TernaryOperator(sc == nil, "", sc.Template)
+}
+
+func (sc *StepConfig) GetService() ServiceTask {
+	return utils.TernaryOperator(sc == nil, ServiceTask{}, sc.Service)
+}
+
+func (sc *StepConfig) GetEndStatus() string {
+	return utils.TernaryOperator(sc == nil, "", sc.EndStatus)
+}
+
+// TerminalStatus 到达本结束步骤时工单的状态，未声明时为passed
+func (sc *StepConfig) TerminalStatus() string {
+	if sc == nil || len(sc.EndStatus) == 0 {
+		return Passed
+	}
+	return sc.EndStatus
+}
+
+// IsSubProcess 是否为子流程步骤
+func (sc *StepConfig) IsSubProcess() bool {
+	return sc != nil && sc.Kind == StepKindSubProcess
+}
+
 // Setter methods for StepConfig
 func (sc *StepConfig) SetStep(step string) {
 	if sc != nil {
@@ -280,6 +691,74 @@ func (sc *StepConfig) SetDisposal(disposal Disposal) {
 	}
 }
 
+func (sc *StepConfig) SetEditable(editable []string) {
+	if sc != nil {
+		sc.Editable = editable
+	}
+}
+
+func (sc *StepConfig) SetHidden(hidden []string) {
+	if sc != nil {
+		sc.Hidden = hidden
+	}
+}
+
+func (sc *StepConfig) SetCC(cc CarbonCopy) {
+	if sc != nil {
+		sc.CC = cc
+	}
+}
+
+func (sc *StepConfig) SetWeights(weights map[string]int) {
+	if sc != nil {
+		sc.Weights = weights
+	}
+}
+
+func (sc *StepConfig) SetKind(kind string) {
+	if sc != nil {
+		sc.Kind = kind
+	}
+}
+
+func (sc *StepConfig) SetTemplate(template string) {
+	if sc != nil {
+		sc.Template = template
+	}
+}
+
+func (sc *StepConfig) SetService(service ServiceTask) {
+	if sc != nil {
+		sc.Service = service
+	}
+}
+
+func (sc *StepConfig) GetNoOverride() bool {
+	return utils.TernaryOperator(sc == nil, false, sc.NoOverride)
+}
+
+func (sc *StepConfig) SetNoOverride(noOverride bool) {
+	if sc != nil {
+		sc.NoOverride = noOverride
+	}
+}
+
+func (sc *StepConfig) SetEndStatus(status string) {
+	if sc != nil {
+		sc.EndStatus = status
+	}
+}
+
+// SetWeight 设置单个操作人的投票权重
+func (sc *StepConfig) SetWeight(operator string, weight int) {
+	if sc != nil {
+		if sc.Weights == nil {
+			sc.Weights = make(map[string]int)
+		}
+		sc.Weights[operator] = weight
+	}
+}
+
 // Add methods for slice fields
 func (sc *StepConfig) AddOperator(operator ...string) {
 	if sc != nil {
@@ -293,9 +772,22 @@ func (sc *StepConfig) AddNext(next ...*NextStep) {
 	}
 }
 
+func (sc *StepConfig) AddEditable(editable ...string) {
+	if sc != nil {
+		sc.Editable = append(sc.Editable, editable...)
+	}
+}
+
+func (sc *StepConfig) AddHidden(hidden ...string) {
+	if sc != nil {
+		sc.Hidden = append(sc.Hidden, hidden...)
+	}
+}
+
 type NextStep struct {
-	Step      string `json:"step"`      // 步骤名
-	Operation string `json:"operation"` // 操作名
+	Step      string    `json:"step"`      // 步骤名
+	Operation string    `json:"operation"` // 操作名
+	Limit     LoopLimit `json:"limit"`     // 经过该流转的次数限制
 }
 
 // Getter methods for NextStep
@@ -2,6 +2,8 @@ package models
 
 import (
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestTicket_GetterMethods(t *testing.T) {
@@ -190,11 +192,12 @@ func TestDisposal_SetterMethods(t *testing.T) {
 
 func TestTicketTemplate_GetterMethods(t *testing.T) {
 	template := &TicketTemplate{
-		Uid:       "template-001",
-		EndStep:   []string{"approved", "rejected"},
-		StartStep: "submit",
-		Config:    []*StepConfig{{Step: "step1", State: "state1"}},
-		Builtin:   true,
+		Uid:            "template-001",
+		EndStep:        []string{"approved", "rejected"},
+		StartStep:      "submit",
+		Config:         []*StepConfig{{Step: "step1", State: "state1"}},
+		Builtin:        true,
+		OrderNumPrefix: "HR",
 	}
 
 	// Test getters with valid template
@@ -207,6 +210,9 @@ func TestTicketTemplate_GetterMethods(t *testing.T) {
 	if got := template.GetBuiltin(); got != true {
 		t.Errorf("TicketTemplate.GetBuiltin() = %v, want true", got)
 	}
+	if got := template.GetOrderNumPrefix(); got != "HR" {
+		t.Errorf("TicketTemplate.GetOrderNumPrefix() = %v, want HR", got)
+	}
 
 	endSteps := template.GetEndStep()
 	if len(endSteps) != 2 {
@@ -259,6 +265,12 @@ func TestTicketTemplate_SetterMethods(t *testing.T) {
 	if template.Builtin != true {
 		t.Errorf("SetBuiltin failed, got %v, want true", template.Builtin)
 	}
+
+	// Test SetOrderNumPrefix
+	template.SetOrderNumPrefix("HR")
+	if template.OrderNumPrefix != "HR" {
+		t.Errorf("SetOrderNumPrefix failed, got %v, want HR", template.OrderNumPrefix)
+	}
 }
 
 func TestTicketTemplate_AddMethods(t *testing.T) {
@@ -413,3 +425,49 @@ func TestNextStep_SetterMethods(t *testing.T) {
 		t.Errorf("SetOperation failed, got %v, want approve", nextStep.Operation)
 	}
 }
+
+func TestFormRelatedFields(t *testing.T) {
+	ticket := &Ticket{}
+	ticket.SetFormData(map[string]any{"amount": 100})
+	if got := ticket.GetFormData(); got["amount"] != 100 {
+		t.Errorf("Ticket.GetFormData() = %v, want amount 100", got)
+	}
+
+	template := &TicketTemplate{}
+	template.SetForm([]*FormField{{Name: "reason", Type: FieldString}})
+	template.AddForm(&FormField{Name: "amount", Type: FieldNumber})
+	if got := template.GetForm(); len(got) != 2 {
+		t.Errorf("TicketTemplate.GetForm() length = %v, want 2", len(got))
+	}
+
+	stepConfig := &StepConfig{}
+	stepConfig.SetEditable([]string{"reason"})
+	stepConfig.AddEditable("amount")
+	if got := stepConfig.GetEditable(); len(got) != 2 {
+		t.Errorf("StepConfig.GetEditable() length = %v, want 2", len(got))
+	}
+	stepConfig.SetHidden([]string{"reason"})
+	stepConfig.AddHidden("amount")
+	if got := stepConfig.GetHidden(); len(got) != 2 {
+		t.Errorf("StepConfig.GetHidden() length = %v, want 2", len(got))
+	}
+}
+
+func TestTicketTemplate_StepConfigMap(t *testing.T) {
+	a, b := &StepConfig{Step: "a"}, &StepConfig{Step: "b"}
+	tests := []struct {
+		name string
+		tt   *TicketTemplate
+		want map[string]*StepConfig
+	}{
+		{name: "nil", tt: nil, want: map[string]*StepConfig{}},
+		{name: "all is ok", tt: &TicketTemplate{Config: []*StepConfig{a, b}}, want: map[string]*StepConfig{"a": a, "b": b}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.tt.StepConfigMap(), tt.want); len(diff) > 0 {
+				t.Errorf("StepConfigMap() diff = %v", diff)
+			}
+		})
+	}
+}
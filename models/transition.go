@@ -0,0 +1,58 @@
+package models
+
+import "github.com/victorwong171/go-utils/utils"
+
+// Transition 一次操作引起的工单流转
+type Transition struct {
+	Ticket   *Ticket   `json:"ticket"`   // 流转后的工单
+	Previous *Ticket   `json:"previous"` // 流转前的工单
+	From     string    `json:"from"`     // 操作前所在步骤
+	To       string    `json:"to"`       // 操作后所在步骤
+	Action   *Action   `json:"action"`   // 引起流转的操作
+	Events   []string  `json:"events"`   // 流转事件，如 step_left/step_entered/passed
+	CC       []string  `json:"cc"`       // 本次流转需抄送的用户
+	CCRoles  []string  `json:"cc_roles"` // 本次流转需抄送的角色
+	Children []*Ticket `json:"children"` // 本次流转进入子流程步骤时创建的子工单
+}
+
+// Getter methods for Transition
+func (tr *Transition) GetTicket() *Ticket {
+	return utils.TernaryOperator(tr == nil, nil, tr.Ticket)
+}
+
+func (tr *Transition) GetPrevious() *Ticket {
+	return utils.TernaryOperator(tr == nil, nil, tr.Previous)
+}
+
+func (tr *Transition) GetFrom() string {
+	return utils.TernaryOperator(tr == nil, "", tr.From)
+}
+
+func (tr *Transition) GetTo() string {
+	return utils.TernaryOperator(tr == nil, "", tr.To)
+}
+
+func (tr *Transition) GetAction() *Action {
+	return utils.TernaryOperator(tr == nil, nil, tr.Action)
+}
+
+func (tr *Transition) GetEvents() []string {
+	return utils.TernaryOperator(tr == nil, nil, tr.Events)
+}
+
+func (tr *Transition) GetCC() []string {
+	return utils.TernaryOperator(tr == nil, nil, tr.CC)
+}
+
+func (tr *Transition) GetCCRoles() []string {
+	return utils.TernaryOperator(tr == nil, nil, tr.CCRoles)
+}
+
+func (tr *Transition) GetChildren() []*Ticket {
+	return utils.TernaryOperator(tr == nil, nil, tr.Children)
+}
+
+// HasEvent 是否包含指定流转事件
+func (tr *Transition) HasEvent(event string) bool {
+	return tr != nil && utils.Contain(tr.Events, event)
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestTransition_GetterMethods(t *testing.T) {
+	tr := &Transition{
+		Ticket:   &Ticket{Uid: "t1"},
+		Previous: &Ticket{Uid: "t1", Step: "a"},
+		From:     "a",
+		To:       "b",
+		Action:   &Action{Operator: "u1"},
+		Events:   []string{EventStepLeft, EventStepEntered},
+		CC:       []string{"u2"},
+		CCRoles:  []string{"hr"},
+	}
+
+	if got := tr.GetTicket(); got.Uid != "t1" {
+		t.Errorf("Transition.GetTicket() = %v, want t1", got)
+	}
+	if got := tr.GetPrevious(); got.Step != "a" {
+		t.Errorf("Transition.GetPrevious() = %v, want step a", got)
+	}
+	if got := tr.GetFrom(); got != "a" {
+		t.Errorf("Transition.GetFrom() = %v, want a", got)
+	}
+	if got := tr.GetTo(); got != "b" {
+		t.Errorf("Transition.GetTo() = %v, want b", got)
+	}
+	if got := tr.GetAction(); got.Operator != "u1" {
+		t.Errorf("Transition.GetAction() = %v, want u1", got)
+	}
+	if got := tr.GetEvents(); len(got) != 2 {
+		t.Errorf("Transition.GetEvents() length = %v, want 2", len(got))
+	}
+	if got := tr.GetCC(); len(got) != 1 {
+		t.Errorf("Transition.GetCC() length = %v, want 1", len(got))
+	}
+	if got := tr.GetCCRoles(); len(got) != 1 {
+		t.Errorf("Transition.GetCCRoles() length = %v, want 1", len(got))
+	}
+}
+
+func TestTransition_HasEvent(t *testing.T) {
+	tr := &Transition{Events: []string{EventPassed}}
+	if !tr.HasEvent(EventPassed) {
+		t.Errorf("HasEvent(%v) = false, want true", EventPassed)
+	}
+	if tr.HasEvent(EventRejected) {
+		t.Errorf("HasEvent(%v) = true, want false", EventRejected)
+	}
+	var nilTr *Transition
+	if nilTr.HasEvent(EventPassed) {
+		t.Errorf("nil HasEvent() = true, want false")
+	}
+}
+
+func TestTicket_Template(t *testing.T) {
+	ticket := &Ticket{}
+	ticket.SetTemplate("expense")
+	if got := ticket.GetTemplate(); got != "expense" {
+		t.Errorf("Ticket.GetTemplate() = %v, want expense", got)
+	}
+}
+
+func TestTicket_Children(t *testing.T) {
+	ticket := &Ticket{}
+	ticket.SetParent("p1")
+	ticket.AddChildren("c1", "c2")
+	if got := ticket.GetParent(); got != "p1" {
+		t.Errorf("Ticket.GetParent() = %v, want p1", got)
+	}
+	if got := ticket.GetChildren(); len(got) != 2 {
+		t.Errorf("Ticket.GetChildren() length = %v, want 2", len(got))
+	}
+	c := ticket.Clone()
+	c.Children[0] = "changed"
+	if ticket.Children[0] != "c1" {
+		t.Errorf("Clone() shares children with original")
+	}
+	if !(&StepConfig{Kind: StepKindSubProcess}).IsSubProcess() {
+		t.Errorf("IsSubProcess() = false, want true")
+	}
+}
+
+func TestStepConfig_TerminalStatus(t *testing.T) {
+	if got := (&StepConfig{}).TerminalStatus(); got != Passed {
+		t.Errorf("TerminalStatus() = %v, want %v", got, Passed)
+	}
+	if got := (&StepConfig{EndStatus: Cancelled}).TerminalStatus(); got != Cancelled {
+		t.Errorf("TerminalStatus() = %v, want %v", got, Cancelled)
+	}
+	tpl := &TicketTemplate{}
+	tpl.AddStatuses("withdrawn")
+	statuses := tpl.TicketStatus()
+	for _, status := range []string{Running, Passed, Rejected, Cancelled, "withdrawn"} {
+		if !statuses.HasKey(status) {
+			t.Errorf("TicketStatus() missing %v", status)
+		}
+	}
+}
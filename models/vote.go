@@ -0,0 +1,116 @@
+package models
+
+import "github.com/victorwong171/go-utils/utils"
+
+// Vote 一名操作人在多人审批步骤中的投票
+type Vote struct {
+	Operator  string `json:"operator"`  // 操作人
+	Operation string `json:"operation"` // 选择的操作
+	Next      string `json:"next"`      // 选择的下一步骤
+	Weight    int    `json:"weight"`    // 计入的权重
+}
+
+// Tally 当前（或最近一次）多人审批步骤的计票结果，jointly_sign/serial_sign的权重均为1
+type Tally struct {
+	Step      string  `json:"step"`      // 计票所属步骤
+	Threshold int     `json:"threshold"` // 通过所需权重
+	Approved  int     `json:"approved"`  // 已同意的权重
+	Rejected  int     `json:"rejected"`  // 已驳回的权重
+	Votes     []*Vote `json:"votes"`     // 全部投票，按时间排序
+}
+
+// Getter methods for Vote
+func (v *Vote) GetOperator() string {
+	return utils.TernaryOperator(v == nil, "", v.Operator)
+}
+
+func (v *Vote) GetOperation() string {
+	return utils.TernaryOperator(v == nil, "", v.Operation)
+}
+
+func (v *Vote) GetNext() string {
+	return utils.TernaryOperator(v == nil, "", v.Next)
+}
+
+func (v *Vote) GetWeight() int {
+	return utils.TernaryOperator(v == nil, 0, v.Weight)
+}
+
+// Getter methods for Tally
+func (t *Tally) GetStep() string {
+	return utils.TernaryOperator(t == nil, "", t.Step)
+}
+
+func (t *Tally) GetThreshold() int {
+	return utils.TernaryOperator(t == nil, 0, t.Threshold)
+}
+
+func (t *Tally) GetApproved() int {
+	return utils.TernaryOperator(t == nil, 0, t.Approved)
+}
+
+func (t *Tally) GetRejected() int {
+	return utils.TernaryOperator(t == nil, 0, t.Rejected)
+}
+
+func (t *Tally) GetVotes() []*Vote {
+	return utils.TernaryOperator(t == nil, nil, t.Votes)
+}
+
+// AddVote 记录投票并累计权重，operation为reject时计入驳回权重
+func (t *Tally) AddVote(vote *Vote) {
+	if t == nil || vote == nil {
+		return
+	}
+	t.Votes = append(t.Votes, vote)
+	if vote.Operation == Reject {
+		t.Rejected += vote.Weight
+	} else {
+		t.Approved += vote.Weight
+	}
+}
+
+// Clone 深拷贝计票结果
+func (t *Tally) Clone() *Tally {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	if t.Votes != nil {
+		c.Votes = make([]*Vote, 0, len(t.Votes))
+		for _, v := range t.Votes {
+			if v == nil {
+				c.Votes = append(c.Votes, nil)
+				continue
+			}
+			copied := *v
+			c.Votes = append(c.Votes, &copied)
+		}
+	}
+	return &c
+}
+
+// WeightOf 操作人在本步骤的投票权重，未配置时为1
+func (sc *StepConfig) WeightOf(operator string) int {
+	if sc == nil {
+		return 1
+	}
+	if w, ok := sc.Weights[operator]; ok {
+		return w
+	}
+	return 1
+}
+
+// CountByNext 按选择的下一步骤汇总权重
+func (t *Tally) CountByNext() map[string]int {
+	counts := make(map[string]int)
+	if t == nil {
+		return counts
+	}
+	for _, v := range t.Votes {
+		if v != nil {
+			counts[v.Next] += v.Weight
+		}
+	}
+	return counts
+}
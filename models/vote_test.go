@@ -0,0 +1,73 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTally_AddVote(t *testing.T) {
+	tally := &Tally{Step: "board", Threshold: 3}
+	tally.AddVote(&Vote{Operator: "chair", Operation: "approve", Next: "end", Weight: 2})
+	tally.AddVote(&Vote{Operator: "m1", Operation: Reject, Next: "back", Weight: 1})
+	tally.AddVote(nil)
+
+	if tally.GetApproved() != 2 || tally.GetRejected() != 1 || len(tally.GetVotes()) != 2 {
+		t.Errorf("AddVote() tally = %+v", tally)
+	}
+	var nilTally *Tally
+	nilTally.AddVote(&Vote{Weight: 1})
+	if nilTally.Clone() != nil {
+		t.Errorf("nil Clone() should return nil")
+	}
+}
+
+func TestTally_Clone(t *testing.T) {
+	tally := &Tally{Step: "board", Threshold: 3, Votes: []*Vote{{Operator: "chair", Weight: 2}}}
+	c := tally.Clone()
+	if diff := cmp.Diff(c, tally); len(diff) > 0 {
+		t.Errorf("Clone() diff = %v", diff)
+	}
+	c.Votes[0].Weight = 5
+	if tally.Votes[0].Weight != 2 {
+		t.Errorf("Clone() shares votes with original")
+	}
+}
+
+func TestStepConfig_WeightOf(t *testing.T) {
+	sc := &StepConfig{}
+	sc.SetWeight("chair", 3)
+	tests := []struct {
+		name     string
+		sc       *StepConfig
+		operator string
+		want     int
+	}{
+		{name: "configured", sc: sc, operator: "chair", want: 3},
+		{name: "default", sc: sc, operator: "member", want: 1},
+		{name: "nil", sc: nil, operator: "chair", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sc.WeightOf(tt.operator); got != tt.want {
+				t.Errorf("WeightOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTally_CountByNext(t *testing.T) {
+	tally := &Tally{Votes: []*Vote{
+		{Operator: "u1", Next: "finance", Weight: 1},
+		{Operator: "u2", Next: "end", Weight: 2},
+		{Operator: "u3", Next: "finance", Weight: 1},
+	}}
+	want := map[string]int{"finance": 2, "end": 2}
+	if diff := cmp.Diff(tally.CountByNext(), want); len(diff) > 0 {
+		t.Errorf("CountByNext() diff = %v", diff)
+	}
+	var nilTally *Tally
+	if got := nilTally.CountByNext(); len(got) != 0 {
+		t.Errorf("nil CountByNext() = %v, want empty", got)
+	}
+}
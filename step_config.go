@@ -2,6 +2,7 @@ package punched_tape
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/victorwong171/punched-tape/models"
 	"gopkg.in/errgo.v2/errors"
@@ -21,6 +22,12 @@ func NewNextStepBuilder(step, operation string) *NextStepBuilder {
 	}
 }
 
+// SetLimit 设置经过该流转的次数限制，onExceeded为reject/escalate，step为升级步骤
+func (b *NextStepBuilder) SetLimit(maxVisits int, onExceeded, step string) *NextStepBuilder {
+	b.option.Limit = models.LoopLimit{MaxVisits: maxVisits, OnExceeded: onExceeded, Step: step}
+	return b
+}
+
 // Build 构建NextStep对象，包含验证
 func (b *NextStepBuilder) Build() (*models.NextStep, error) {
 	// 验证必填字段
@@ -67,14 +74,39 @@ func (b *DisposalBuilder) SetJointSignRate(rate float32) *DisposalBuilder {
 	return b
 }
 
+// SetJointSignCount 设置联合签名同意人数阈值，大于0时优先于比例
+func (b *DisposalBuilder) SetJointSignCount(count int) *DisposalBuilder {
+	b.option.JointSignCount = count
+	return b
+}
+
+// SetVeto 设置联合签名是否一票否决
+func (b *DisposalBuilder) SetVeto(veto bool) *DisposalBuilder {
+	b.option.Veto = veto
+	return b
+}
+
+// SetWeightThreshold 设置加权投票通过所需的权重
+func (b *DisposalBuilder) SetWeightThreshold(threshold int) *DisposalBuilder {
+	b.option.WeightThreshold = threshold
+	return b
+}
+
+// SetResolution 设置多人审批时签署人选择不同下一步骤的处理方式
+func (b *DisposalBuilder) SetResolution(resolution string) *DisposalBuilder {
+	b.option.Resolution = resolution
+	return b
+}
+
 // Build 构建Disposal对象，包含验证
 func (b *DisposalBuilder) Build() (*models.Disposal, error) {
 	// 验证签名类型
 	if b.option.SignType != "" {
 		validSignTypes := map[string]bool{
-			models.JointlySign: true,
-			models.SerialSign:  true,
-			models.AnyoneSign:  true,
+			models.JointlySign:  true,
+			models.SerialSign:   true,
+			models.AnyoneSign:   true,
+			models.WeightedSign: true,
 		}
 		if !validSignTypes[b.option.SignType] {
 			return nil, fmt.Errorf("invalid sign_type: %s", b.option.SignType)
@@ -82,10 +114,19 @@ func (b *DisposalBuilder) Build() (*models.Disposal, error) {
 
 		// 如果是联合签名，验证比例
 		if b.option.SignType == models.JointlySign {
-			if b.option.JointSignRate <= 0 || b.option.JointSignRate > 1 {
+			if b.option.JointSignCount < 0 {
+				return nil, fmt.Errorf("joint_sign_count must not be negative")
+			}
+			if b.option.JointSignCount == 0 && (b.option.JointSignRate <= 0 || b.option.JointSignRate > 1) {
 				return nil, fmt.Errorf("joint_sign_rate must be between 0 and 1")
 			}
 		}
+		if len(b.option.Resolution) > 0 && !models.Resolution.HasKey(b.option.Resolution) {
+			return nil, fmt.Errorf("invalid resolution: %s", b.option.Resolution)
+		}
+		if b.option.SignType == models.WeightedSign && b.option.WeightThreshold <= 0 {
+			return nil, fmt.Errorf("weight_threshold must be positive")
+		}
 	}
 
 	return &b.option, nil
@@ -168,16 +209,131 @@ func (b *StepConfigBuilder) SetDisposalJointSignRate(rate float32) *StepConfigBu
 	return b
 }
 
+// SetDisposalJointSignCount 设置联合签名同意人数阈值
+func (b *StepConfigBuilder) SetDisposalJointSignCount(count int) *StepConfigBuilder {
+	b.option.Disposal.JointSignCount = count
+	return b
+}
+
+// SetDisposalVeto 设置联合签名是否一票否决
+func (b *StepConfigBuilder) SetDisposalVeto(veto bool) *StepConfigBuilder {
+	b.option.Disposal.Veto = veto
+	return b
+}
+
+// SetDisposalWeightThreshold 设置加权投票通过所需的权重
+func (b *StepConfigBuilder) SetDisposalWeightThreshold(threshold int) *StepConfigBuilder {
+	b.option.Disposal.WeightThreshold = threshold
+	return b
+}
+
+// SetWeight 设置操作人的投票权重
+func (b *StepConfigBuilder) SetWeight(operator string, weight int) *StepConfigBuilder {
+	b.option.SetWeight(operator, weight)
+	return b
+}
+
+// SetDisposalResolution 设置多人审批时签署人选择不同下一步骤的处理方式
+func (b *StepConfigBuilder) SetDisposalResolution(resolution string) *StepConfigBuilder {
+	b.option.Disposal.Resolution = resolution
+	return b
+}
+
+// SetSubProcess 将步骤设置为子流程，进入时按template模板创建子工单
+func (b *StepConfigBuilder) SetSubProcess(template string) *StepConfigBuilder {
+	b.option.Kind = models.StepKindSubProcess
+	b.option.Template = template
+	return b
+}
+
+// SetService 将步骤设置为自动步骤，进入时执行名为handler的处理器
+func (b *StepConfigBuilder) SetService(handler string, retries int, backoff time.Duration, errorStep string) *StepConfigBuilder {
+	b.option.Kind = models.StepKindService
+	b.option.Service = models.ServiceTask{
+		Handler:   handler,
+		Retries:   retries,
+		Backoff:   backoff,
+		ErrorStep: errorStep,
+	}
+	return b
+}
+
+// SetLimit 设置进入本步骤的次数限制，onExceeded为reject/escalate，step为升级步骤
+func (b *StepConfigBuilder) SetLimit(maxVisits int, onExceeded, step string) *StepConfigBuilder {
+	b.option.Limit = models.LoopLimit{MaxVisits: maxVisits, OnExceeded: onExceeded, Step: step}
+	return b
+}
+
+// SetNoOverride 设置是否禁止管理员越权操作本步骤
+func (b *StepConfigBuilder) SetNoOverride(noOverride bool) *StepConfigBuilder {
+	b.option.NoOverride = noOverride
+	return b
+}
+
+// SetEndStatus 设置结束步骤到达时工单的状态
+func (b *StepConfigBuilder) SetEndStatus(status string) *StepConfigBuilder {
+	b.option.EndStatus = status
+	return b
+}
+
+// SetEditable 设置本步骤可编辑的表单字段
+func (b *StepConfigBuilder) SetEditable(field ...string) *StepConfigBuilder {
+	b.option.Editable = field
+	return b
+}
+
+// SetHidden 设置本步骤不可见的表单字段
+func (b *StepConfigBuilder) SetHidden(field ...string) *StepConfigBuilder {
+	b.option.Hidden = field
+	return b
+}
+
+// SetCC 设置抄送用户、角色及触发时机
+func (b *StepConfigBuilder) SetCC(users, roles []string, trigger string) *StepConfigBuilder {
+	b.option.CC = models.CarbonCopy{
+		Users:   users,
+		Roles:   roles,
+		Trigger: trigger,
+	}
+	return b
+}
+
 // Build 构建StepConfig对象，包含验证
 func (b *StepConfigBuilder) Build() (*models.StepConfig, error) {
-	// 验证处置方式
-	if !models.DisposalSignType.HasKey(b.option.Disposal.SignType) {
+	if b.option.IsSubProcess() && len(b.option.Template) == 0 {
+		return nil, errors.New("sub process step requires a template")
+	}
+	if b.option.IsService() && (len(b.option.Service.Handler) == 0 || b.option.Service.Retries < 0 || b.option.Service.Backoff < 0) {
+		return nil, errors.New(fmt.Sprintf("invalid service task: %+v", b.option.Service))
+	}
+	// 验证处置方式，子流程步骤与自动步骤无需签署
+	automatic := b.option.IsSubProcess() || b.option.IsService()
+	if !automatic && !models.DisposalSignType.HasKey(b.option.Disposal.SignType) {
 		return nil, errors.New(fmt.Sprintf("invalid disposal sign type: %s", b.option.Disposal.SignType))
 	}
 	if b.option.Disposal.SignType == models.JointlySign {
 		if b.option.Disposal.JointSignRate < 0 || b.option.Disposal.JointSignRate > 1 {
 			return nil, errors.New(fmt.Sprintf("invalid disposal joint sign rate: %f", b.option.Disposal.JointSignRate))
 		}
+		if b.option.Disposal.JointSignCount < 0 {
+			return nil, errors.New(fmt.Sprintf("invalid disposal joint sign count: %d", b.option.Disposal.JointSignCount))
+		}
+	}
+	if len(b.option.Disposal.Resolution) > 0 && !models.Resolution.HasKey(b.option.Disposal.Resolution) {
+		return nil, errors.New(fmt.Sprintf("invalid disposal resolution: %s", b.option.Disposal.Resolution))
+	}
+	if b.option.Disposal.SignType == models.WeightedSign {
+		if b.option.Disposal.WeightThreshold <= 0 {
+			return nil, errors.New(fmt.Sprintf("invalid disposal weight threshold: %d", b.option.Disposal.WeightThreshold))
+		}
+		for operator, weight := range b.option.Weights {
+			if weight < 0 {
+				return nil, errors.New(fmt.Sprintf("invalid weight of %s: %d", operator, weight))
+			}
+		}
+	}
+	if len(b.option.CC.Trigger) > 0 && !models.CCTrigger.HasKey(b.option.CC.Trigger) {
+		return nil, errors.New(fmt.Sprintf("invalid cc trigger: %s", b.option.CC.Trigger))
 	}
 
 	return b.option, nil
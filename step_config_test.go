@@ -2,6 +2,7 @@ package punched_tape
 
 import (
 	"testing"
+	"time"
 
 	"github.com/victorwong171/punched-tape/models"
 )
@@ -330,6 +331,35 @@ func TestDisposalBuilder_Build(t *testing.T) {
 	}
 }
 
+func TestDisposalBuilder_SetJointSignCount(t *testing.T) {
+	tests := []struct {
+		name        string
+		count       int
+		rate        float32
+		expectError bool
+	}{
+		{name: "count without rate", count: 2, rate: 0},
+		{name: "negative count", count: -1, rate: 0.5, expectError: true},
+		{name: "neither count nor rate", count: 0, rate: 0, expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disposal, err := NewDisposalBuilder().
+				SetSignType(models.JointlySign).
+				SetJointSignRate(tt.rate).
+				SetJointSignCount(tt.count).
+				SetVeto(true).
+				Build()
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Build() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err == nil && (disposal.JointSignCount != tt.count || !disposal.Veto) {
+				t.Errorf("Build() = %v, want count %d and veto", disposal, tt.count)
+			}
+		})
+	}
+}
+
 func TestDisposalBuilder_BuildOrPanic(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -663,6 +693,168 @@ func TestStepConfigBuilder_SetDisposalJointSignRate(t *testing.T) {
 	}
 }
 
+func TestStepConfigBuilder_SetEditableHidden(t *testing.T) {
+	builder := NewStepConfigBuilder("review", "pending")
+
+	if result := builder.SetEditable("amount", "reason"); result != builder {
+		t.Errorf("SetEditable() should return builder instance")
+	}
+	if result := builder.SetHidden("salary"); result != builder {
+		t.Errorf("SetHidden() should return builder instance")
+	}
+	if len(builder.option.Editable) != 2 {
+		t.Errorf("SetEditable() length = %v, want 2", len(builder.option.Editable))
+	}
+	if len(builder.option.Hidden) != 1 || builder.option.Hidden[0] != "salary" {
+		t.Errorf("SetHidden() = %v, want [salary]", builder.option.Hidden)
+	}
+}
+
+func TestStepConfigBuilder_SetCC(t *testing.T) {
+	builder := NewStepConfigBuilder("review", "pending").SetDisposalSignType(models.AnyoneSign)
+
+	if result := builder.SetCC([]string{"u1"}, []string{"hr"}, models.CCOnBoth); result != builder {
+		t.Errorf("SetCC() should return builder instance")
+	}
+	if builder.option.CC.Trigger != models.CCOnBoth || len(builder.option.CC.Users) != 1 || len(builder.option.CC.Roles) != 1 {
+		t.Errorf("SetCC() = %v, want users [u1] roles [hr] trigger both", builder.option.CC)
+	}
+	if _, err := builder.Build(); err != nil {
+		t.Errorf("Build() error = %v", err)
+	}
+
+	builder.SetCC([]string{"u1"}, nil, "sometimes")
+	if _, err := builder.Build(); err == nil {
+		t.Errorf("Build() expected error for invalid cc trigger")
+	}
+}
+
+func TestStepConfigBuilder_SetDisposalJointSignCount(t *testing.T) {
+	builder := NewStepConfigBuilder("review", "pending").SetDisposalSignType(models.JointlySign)
+
+	if result := builder.SetDisposalJointSignCount(2).SetDisposalVeto(true); result != builder {
+		t.Errorf("SetDisposalJointSignCount() should return builder instance")
+	}
+	if builder.option.Disposal.JointSignCount != 2 || !builder.option.Disposal.Veto {
+		t.Errorf("Disposal = %v, want count 2 and veto", builder.option.Disposal)
+	}
+	if _, err := builder.Build(); err != nil {
+		t.Errorf("Build() error = %v", err)
+	}
+
+	builder.SetDisposalJointSignCount(-1)
+	if _, err := builder.Build(); err == nil {
+		t.Errorf("Build() expected error for negative joint sign count")
+	}
+}
+
+func TestStepConfigBuilder_SetWeight(t *testing.T) {
+	builder := NewStepConfigBuilder("board", "pending").
+		SetDisposalSignType(models.WeightedSign).
+		SetDisposalWeightThreshold(3).
+		SetWeight("chair", 2)
+
+	config, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if config.WeightOf("chair") != 2 || config.Disposal.WeightThreshold != 3 {
+		t.Errorf("Build() = %v, want chair weight 2 and threshold 3", config)
+	}
+
+	builder.SetWeight("m1", -1)
+	if _, err = builder.Build(); err == nil {
+		t.Errorf("Build() expected error for negative weight")
+	}
+	builder.SetWeight("m1", 1).SetDisposalWeightThreshold(0)
+	if _, err = builder.Build(); err == nil {
+		t.Errorf("Build() expected error for zero weight threshold")
+	}
+}
+
+func TestStepConfigBuilder_SetDisposalResolution(t *testing.T) {
+	builder := NewStepConfigBuilder("review", "pending").
+		SetDisposalSignType(models.SerialSign).
+		SetDisposalResolution(models.ResolveMajority)
+	if _, err := builder.Build(); err != nil {
+		t.Errorf("Build() error = %v", err)
+	}
+	builder.SetDisposalResolution("random")
+	if _, err := builder.Build(); err == nil {
+		t.Errorf("Build() expected error for invalid resolution")
+	}
+	if _, err := NewDisposalBuilder().SetSignType(models.SerialSign).SetResolution("random").Build(); err == nil {
+		t.Errorf("DisposalBuilder.Build() expected error for invalid resolution")
+	}
+}
+
+func TestStepConfigBuilder_SetSubProcess(t *testing.T) {
+	config, err := NewStepConfigBuilder("background_check", "pending").
+		SetSubProcess("background").
+		AddNextStep("done", models.Passed).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if !config.IsSubProcess() || config.Template != "background" {
+		t.Errorf("Build() = %v, want sub process of background", config)
+	}
+	if _, err = NewStepConfigBuilder("x", "pending").SetSubProcess("").Build(); err == nil {
+		t.Errorf("Build() expected error for sub process without template")
+	}
+}
+
+func TestStepConfigBuilder_SetService(t *testing.T) {
+	config, err := NewStepConfigBuilder("jira", "pending").
+		SetService("create-jira-issue", 3, time.Second, "manual").
+		AddNextStep("done", "created").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if !config.IsService() || config.Service.Handler != "create-jira-issue" || config.Service.Retries != 3 {
+		t.Errorf("Build() = %v, want service create-jira-issue", config)
+	}
+	if _, err = NewStepConfigBuilder("x", "pending").SetService("", 0, 0, "").Build(); err == nil {
+		t.Errorf("Build() expected error for service without handler")
+	}
+}
+
+func TestStepConfigBuilder_SetLimit(t *testing.T) {
+	builder := NewStepConfigBuilder("review", "pending")
+	if result := builder.SetLimit(3, models.LimitEscalate, "director"); result != builder {
+		t.Errorf("SetLimit() should return builder instance")
+	}
+	want := models.LoopLimit{MaxVisits: 3, OnExceeded: models.LimitEscalate, Step: "director"}
+	if builder.option.Limit != want {
+		t.Errorf("SetLimit() = %+v, want %+v", builder.option.Limit, want)
+	}
+	next := NewNextStepBuilder("review", "resubmit").SetLimit(2, models.LimitReject, "").BuildOrPanic()
+	if next.Limit.MaxVisits != 2 || next.Limit.OnExceeded != models.LimitReject {
+		t.Errorf("NextStepBuilder.SetLimit() = %+v", next.Limit)
+	}
+}
+
+func TestStepConfigBuilder_SetNoOverride(t *testing.T) {
+	builder := NewStepConfigBuilder("payout", "pending")
+	if result := builder.SetNoOverride(true); result != builder {
+		t.Errorf("SetNoOverride() should return builder instance")
+	}
+	if !builder.option.GetNoOverride() {
+		t.Errorf("SetNoOverride() = false, want true")
+	}
+}
+
+func TestStepConfigBuilder_SetEndStatus(t *testing.T) {
+	builder := NewStepConfigBuilder("cancel", "pending")
+	if result := builder.SetEndStatus(models.Cancelled); result != builder {
+		t.Errorf("SetEndStatus() should return builder instance")
+	}
+	if got := builder.option.TerminalStatus(); got != models.Cancelled {
+		t.Errorf("TerminalStatus() = %v, want %v", got, models.Cancelled)
+	}
+}
+
 func TestStepConfigBuilder_Build(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -6,7 +6,11 @@ import (
 )
 
 type TemplateBuilder struct {
-	option models.TicketTemplate
+	option    models.TicketTemplate
+	strict    bool
+	warnings  []*template.Warning
+	options   []template.Option
+	fragments map[string]*models.Fragment
 }
 
 // NewTemplateBuilder 创建模板构建器，必填字段在构造函数中指定
@@ -40,6 +44,65 @@ func (b *TemplateBuilder) SetBuiltin(builtin bool) *TemplateBuilder {
 	return b
 }
 
+// SetOrderNumPrefix 设置工单号前缀
+func (b *TemplateBuilder) SetOrderNumPrefix(prefix string) *TemplateBuilder {
+	b.option.OrderNumPrefix = prefix
+	return b
+}
+
+// AddFormField 添加表单字段定义
+func (b *TemplateBuilder) AddFormField(field ...*models.FormField) *TemplateBuilder {
+	b.option.Form = append(b.option.Form, field...)
+	return b
+}
+
+// AddStatus 添加模板自定义的工单结束状态
+func (b *TemplateBuilder) AddStatus(status ...string) *TemplateBuilder {
+	b.option.Statuses = append(b.option.Statuses, status...)
+	return b
+}
+
+// AddState 添加步骤可用的业务状态
+func (b *TemplateBuilder) AddState(state ...string) *TemplateBuilder {
+	b.option.States = append(b.option.States, state...)
+	return b
+}
+
+// SetReopen 设置重新打开策略，statuses为允许重新打开的结束状态，operators为空时不限制操作人，maxTimes为0时不限制次数
+func (b *TemplateBuilder) SetReopen(statuses, operators []string, maxTimes int) *TemplateBuilder {
+	b.option.Reopen = models.ReopenPolicy{Statuses: statuses, Operators: operators, MaxTimes: maxTimes}
+	return b
+}
+
+// SetStrict 设置严格模式，严格模式下Build遇到警告时返回错误
+func (b *TemplateBuilder) SetStrict(strict bool) *TemplateBuilder {
+	b.strict = strict
+	return b
+}
+
+// AddValidatorOption 添加Build时使用的校验器选项，如组织自定义的校验规则
+func (b *TemplateBuilder) AddValidatorOption(opts ...template.Option) *TemplateBuilder {
+	b.options = append(b.options, opts...)
+	return b
+}
+
+// AddFragment 注册可被引用的步骤片段
+func (b *TemplateBuilder) AddFragment(fragments ...*models.Fragment) *TemplateBuilder {
+	if b.fragments == nil {
+		b.fragments = make(map[string]*models.Fragment, len(fragments))
+	}
+	for _, f := range fragments {
+		b.fragments[f.GetName()] = f
+	}
+	return b
+}
+
+// AddInclude 引用步骤片段，Build时展开
+func (b *TemplateBuilder) AddInclude(includes ...*models.Include) *TemplateBuilder {
+	b.option.Includes = append(b.option.Includes, includes...)
+	return b
+}
+
 // SetConfig 设置步骤配置列表
 func (b *TemplateBuilder) SetConfig(config []*models.StepConfig) *TemplateBuilder {
 	b.option.Config = config
@@ -66,13 +129,33 @@ func (b *TemplateBuilder) AddStepConfig(step, state string, operator []string) *
 
 // Build 构建TicketTemplate对象，包含验证
 func (b *TemplateBuilder) Build() (*models.TicketTemplate, error) {
+	// 展开片段引用
+	tpl := &b.option
+	if len(b.option.Includes) > 0 {
+		expanded, err := template.Expand(tpl, func(name string) *models.Fragment {
+			return b.fragments[name]
+		})
+		if err != nil {
+			return nil, err
+		}
+		tpl = expanded
+	}
 	// 验证配置
-	validator := template.NewValidator()
-	if err := validator.Validate(b.option); err != nil {
+	validator := template.NewValidator(b.options...)
+	if err := validator.Validate(*tpl); err != nil {
 		return nil, err
 	}
+	b.warnings = template.Warnings(*tpl)
+	if b.strict && len(b.warnings) > 0 {
+		return nil, b.warnings[0]
+	}
+
+	return tpl, nil
+}
 
-	return &b.option, nil
+// Warnings 返回最近一次Build检查出的警告
+func (b *TemplateBuilder) Warnings() []*template.Warning {
+	return b.warnings
 }
 
 // BuildOrPanic 构建TicketTemplate对象，验证失败时panic
@@ -1,9 +1,11 @@
 package punched_tape
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/victorwong171/punched-tape/models"
+	"github.com/victorwong171/punched-tape/ticket/template"
 )
 
 func TestNewTemplateBuilder(t *testing.T) {
@@ -155,6 +157,180 @@ func TestTemplateBuilder_SetBuiltin(t *testing.T) {
 	}
 }
 
+func TestTemplateBuilder_SetOrderNumPrefix(t *testing.T) {
+	builder := NewTemplateBuilder("template-001", "submit")
+
+	result := builder.SetOrderNumPrefix("HR")
+	if result != builder {
+		t.Errorf("SetOrderNumPrefix() should return builder instance")
+	}
+	if builder.option.OrderNumPrefix != "HR" {
+		t.Errorf("SetOrderNumPrefix() = %v, want HR", builder.option.OrderNumPrefix)
+	}
+}
+
+func TestTemplateBuilder_AddStatus(t *testing.T) {
+	builder := NewTemplateBuilder("template-001", "submit")
+
+	result := builder.AddStatus("withdrawn").AddStatus("expired")
+	if result != builder {
+		t.Errorf("AddStatus() should return builder instance")
+	}
+	if len(builder.option.Statuses) != 2 || builder.option.Statuses[1] != "expired" {
+		t.Errorf("AddStatus() = %v, want [withdrawn expired]", builder.option.Statuses)
+	}
+}
+
+func TestTemplateBuilder_AddState(t *testing.T) {
+	builder := NewTemplateBuilder("template-001", "submit")
+	if result := builder.AddState("in_review", "awaiting_payment"); result != builder {
+		t.Errorf("AddState() should return builder instance")
+	}
+	if len(builder.option.States) != 2 {
+		t.Errorf("AddState() length = %v, want 2", len(builder.option.States))
+	}
+	_, err := builder.AddEndStep("end").
+		AddStepConfig("submit", "paid", []string{"u1"}).
+		Build()
+	if err == nil {
+		t.Errorf("Build() with undeclared state should return error")
+	}
+}
+
+func TestTemplateBuilder_SetReopen(t *testing.T) {
+	builder := NewTemplateBuilder("template-001", "submit")
+	if result := builder.SetReopen([]string{models.Rejected}, []string{"u1"}, 2); result != builder {
+		t.Errorf("SetReopen() should return builder instance")
+	}
+	if !builder.option.Reopen.Allows(models.Rejected) || builder.option.Reopen.MaxTimes != 2 {
+		t.Errorf("SetReopen() = %+v", builder.option.Reopen)
+	}
+	_, err := builder.SetReopen([]string{models.Running}, nil, 0).
+		AddEndStep("end").
+		AddStepConfig("submit", "pending", []string{"u1"}).
+		Build()
+	if err == nil {
+		t.Errorf("Build() with running reopen status should return error")
+	}
+}
+
+func TestTemplateBuilder_Warnings(t *testing.T) {
+	newBuilder := func() *TemplateBuilder {
+		return NewTemplateBuilder("template-001", "submit").
+			AddEndStep("end").
+			AddConfig(
+				&models.StepConfig{
+					Step:     "submit",
+					Operator: []string{"u1"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "approve", Step: "end"}},
+				},
+				&models.StepConfig{
+					Step:     "end",
+					Operator: []string{"u1"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+				},
+			)
+	}
+
+	builder := newBuilder()
+	if _, err := builder.Build(); err != nil {
+		t.Fatalf("Build() unexpected error: %v", err)
+	}
+	if got := len(builder.Warnings()); got != 1 {
+		t.Errorf("Warnings() length = %v, want 1", got)
+	}
+
+	if _, err := newBuilder().SetStrict(true).Build(); err == nil {
+		t.Errorf("Build() in strict mode should return error on warnings")
+	}
+}
+
+func TestTemplateBuilder_AddValidatorOption(t *testing.T) {
+	builder := NewTemplateBuilder("template-001", "submit").
+		AddEndStep("end").
+		AddConfig(
+			&models.StepConfig{
+				Step:     "submit",
+				Operator: []string{"u1"},
+				Disposal: models.Disposal{SignType: models.AnyoneSign},
+				Next:     []*models.NextStep{{Operation: "approve", Step: "end"}},
+			},
+			&models.StepConfig{Step: "end", Disposal: models.Disposal{SignType: models.AnyoneSign}},
+		)
+	if _, err := builder.Build(); err != nil {
+		t.Fatalf("Build() unexpected error: %v", err)
+	}
+	builder.option.Config[0].Operator = nil
+	if _, err := builder.Build(); !errors.Is(err, template.ErrNoOperator) {
+		t.Errorf("Build() without operator error = %v, want %v", err, template.ErrNoOperator)
+	}
+	if _, err := builder.AddValidatorOption(template.AllowNoOperator()).Build(); err != nil {
+		t.Errorf("Build() with AllowNoOperator unexpected error: %v", err)
+	}
+	if result := builder.AddValidatorOption(template.WithMaxSteps(1)); result != builder {
+		t.Errorf("AddValidatorOption() should return builder instance")
+	}
+	if _, err := builder.Build(); err == nil {
+		t.Errorf("Build() with max steps 1 should return error")
+	}
+}
+
+func TestTemplateBuilder_AddInclude(t *testing.T) {
+	fragment := &models.Fragment{
+		Name:  "chain",
+		Entry: "manager",
+		Config: []*models.StepConfig{
+			{
+				Step:     "manager",
+				Operator: []string{"m1"},
+				Disposal: models.Disposal{SignType: models.AnyoneSign},
+				Next:     []*models.NextStep{{Operation: "approve", Step: models.FragmentNext}},
+			},
+		},
+	}
+	builder := NewTemplateBuilder("template-001", "submit").
+		AddEndStep("end").
+		AddConfig(
+			&models.StepConfig{
+				Step:     "submit",
+				Operator: []string{"u1"},
+				Disposal: models.Disposal{SignType: models.AnyoneSign},
+				Next:     []*models.NextStep{{Operation: "submit", Step: "chain"}},
+			},
+			&models.StepConfig{Step: "end", Disposal: models.Disposal{SignType: models.AnyoneSign}},
+		)
+	if result := builder.AddFragment(fragment).AddInclude(&models.Include{Fragment: "chain", Next: "end"}); result != builder {
+		t.Errorf("AddInclude() should return builder instance")
+	}
+	tpl, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build() unexpected error: %v", err)
+	}
+	if len(tpl.Config) != 3 || tpl.StepConfigMap()["chain.manager"] == nil {
+		t.Errorf("Build() config = %v, want chain.manager expanded", tpl.Config)
+	}
+	if _, err = NewTemplateBuilder("template-002", "submit").
+		AddInclude(&models.Include{Fragment: "missing"}).
+		AddStepConfig("submit", "pending", []string{"u1"}).
+		Build(); err == nil {
+		t.Errorf("Build() with unknown fragment should return error")
+	}
+}
+
+func TestTemplateBuilder_AddFormField(t *testing.T) {
+	builder := NewTemplateBuilder("template-001", "submit")
+
+	result := builder.AddFormField(&models.FormField{Name: "reason", Type: models.FieldString}).
+		AddFormField(&models.FormField{Name: "amount", Type: models.FieldNumber})
+	if result != builder {
+		t.Errorf("AddFormField() should return builder instance")
+	}
+	if len(builder.option.Form) != 2 {
+		t.Errorf("AddFormField() length = %v, want 2", len(builder.option.Form))
+	}
+}
+
 func TestTemplateBuilder_SetConfig(t *testing.T) {
 	builder := NewTemplateBuilder("template-001", "submit")
 
@@ -242,8 +418,8 @@ func TestTemplateBuilder_Build(t *testing.T) {
 			uid:       "template-001",
 			startStep: "submit",
 			configs: []*models.StepConfig{
-				{Step: "submit", State: "pending", Next: []*models.NextStep{{Step: "review", Operation: "submit"}}, Disposal: models.Disposal{SignType: models.AnyoneSign}},
-				{Step: "review", State: "reviewing", Next: []*models.NextStep{{Step: "approved", Operation: "approve"}, {Step: "rejected", Operation: "reject"}}, Disposal: models.Disposal{SignType: models.JointlySign, JointSignRate: 0.5}},
+				{Step: "submit", State: "pending", Operator: []string{"u1"}, Next: []*models.NextStep{{Step: "review", Operation: "submit"}}, Disposal: models.Disposal{SignType: models.AnyoneSign}},
+				{Step: "review", State: "reviewing", Operator: []string{"m1", "m2"}, Next: []*models.NextStep{{Step: "approved", Operation: "approve"}, {Step: "rejected", Operation: "reject"}}, Disposal: models.Disposal{SignType: models.JointlySign, JointSignRate: 0.5}},
 				{Step: "approved", State: "approved", Disposal: models.Disposal{SignType: models.AnyoneSign}},
 				{Step: "rejected", State: "rejected", Disposal: models.Disposal{SignType: models.AnyoneSign}},
 			},
@@ -352,8 +528,8 @@ func TestTemplateBuilder_BuildOrPanic(t *testing.T) {
 			uid:       "template-001",
 			startStep: "submit",
 			configs: []*models.StepConfig{
-				{Step: "submit", State: "pending", Next: []*models.NextStep{{Step: "review", Operation: "submit"}}, Disposal: models.Disposal{SignType: models.AnyoneSign}},
-				{Step: "review", State: "reviewing", Next: []*models.NextStep{{Step: "approved", Operation: "approve"}, {Step: "rejected", Operation: "reject"}}, Disposal: models.Disposal{SignType: models.JointlySign, JointSignRate: 0.5}},
+				{Step: "submit", State: "pending", Operator: []string{"u1"}, Next: []*models.NextStep{{Step: "review", Operation: "submit"}}, Disposal: models.Disposal{SignType: models.AnyoneSign}},
+				{Step: "review", State: "reviewing", Operator: []string{"m1", "m2"}, Next: []*models.NextStep{{Step: "approved", Operation: "approve"}, {Step: "rejected", Operation: "reject"}}, Disposal: models.Disposal{SignType: models.JointlySign, JointSignRate: 0.5}},
 				{Step: "approved", State: "approved", Disposal: models.Disposal{SignType: models.AnyoneSign}},
 				{Step: "rejected", State: "rejected", Disposal: models.Disposal{SignType: models.AnyoneSign}},
 			},
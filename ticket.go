@@ -4,11 +4,13 @@ import (
 	"fmt"
 
 	"github.com/victorwong171/punched-tape/models"
+	"github.com/victorwong171/punched-tape/ticket/form"
 	"gopkg.in/errgo.v2/errors"
 )
 
 type TicketBuilder struct {
-	option models.Ticket
+	option   models.Ticket
+	template *models.TicketTemplate
 }
 
 // NewTicketBuilder 创建工单构建器，必填字段在构造函数中指定
@@ -38,6 +40,12 @@ func (b *TicketBuilder) SetStatus(status string) *TicketBuilder {
 	return b
 }
 
+// SetState 设置工单当前的业务状态，通常取自开始步骤的StepConfig.State
+func (b *TicketBuilder) SetState(state string) *TicketBuilder {
+	b.option.State = state
+	return b
+}
+
 // SetOperator 设置操作人列表
 func (b *TicketBuilder) SetOperator(operator []string) *TicketBuilder {
 	b.option.Operator = operator
@@ -68,12 +76,41 @@ func (b *TicketBuilder) SetMemo(memo string) *TicketBuilder {
 	return b
 }
 
+// SetTemplate 设置所属模板唯一标识
+func (b *TicketBuilder) SetTemplate(template string) *TicketBuilder {
+	b.option.Template = template
+	return b
+}
+
+// SetFormData 设置表单数据
+func (b *TicketBuilder) SetFormData(formData map[string]any) *TicketBuilder {
+	b.option.FormData = formData
+	return b
+}
+
+// SetTicketTemplate 指定工单所属模板，同时设置模板标识；Build按模板声明的状态校验工单状态，并按模板表单定义校验表单数据
+// 未调用SetState时，Build取当前步骤StepConfig.State作为业务状态
+func (b *TicketBuilder) SetTicketTemplate(tpl *models.TicketTemplate) *TicketBuilder {
+	b.template = tpl
+	b.option.Template = tpl.GetUid()
+	return b
+}
+
 // Build 构建Ticket对象，包含验证
 func (b *TicketBuilder) Build() (*models.Ticket, error) {
-	// 验证状态值
-	if !models.TicketStatus.HasKey(b.option.Status) {
+	// 验证状态值，未指定模板时只允许内置状态
+	if !b.template.TicketStatus().HasKey(b.option.Status) {
 		return nil, errors.New(fmt.Sprintf("invalid status: %s", b.option.Status))
 	}
+	// 发起时所有字段均可填写，必填字段必须提供
+	if b.template != nil {
+		if err := form.Validate(b.template.Form, b.option.FormData); err != nil {
+			return nil, err
+		}
+		if len(b.option.State) == 0 {
+			b.option.State = b.template.StepConfigMap()[b.option.Step].GetState()
+		}
+	}
 
 	return &b.option, nil
 }
@@ -0,0 +1,23 @@
+package blob
+
+import (
+	"errors"
+	"io"
+
+	"github.com/victorwong171/punched-tape/models"
+)
+
+// BlobStore 附件内容存储，工单中仅保存Put返回的附件引用
+type BlobStore interface {
+	// Put 保存附件内容，返回包含大小、内容哈希与URI的附件引用
+	Put(name string, r io.Reader) (*models.Attachment, error)
+	// Open 按URI读取附件内容，调用方负责关闭
+	Open(uri string) (io.ReadCloser, error)
+	// Delete 删除一次Put产生的附件引用，相同内容的其他引用不受影响
+	Delete(uri string) error
+}
+
+var (
+	ErrBadURI   = errors.New("bad blob uri")
+	ErrNotFound = errors.New("blob not found")
+)
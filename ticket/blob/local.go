@@ -0,0 +1,152 @@
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/victorwong171/punched-tape/models"
+)
+
+const hashPrefix = "sha256:"
+
+type localStore struct {
+	mu   sync.Mutex
+	root string
+}
+
+// NewLocalStore 创建基于本地文件系统的BlobStore，内容按sha256寻址保存在root目录下
+// 相同内容只保存一份，按Put次数计数引用，Delete在最后一个引用删除时才删除文件
+func NewLocalStore(root string) (BlobStore, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &localStore{root: root}, nil
+}
+
+func (s *localStore) Put(name string, r io.Reader) (*models.Attachment, error) {
+	tmp, err := os.CreateTemp(s.root, ".upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	path := filepath.Join(s.root, sum)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	refs, err := s.refs(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	if err = writeRefs(path, refs+1); err != nil {
+		return nil, err
+	}
+	return &models.Attachment{
+		Name: name,
+		Size: size,
+		Hash: hashPrefix + sum,
+		URI:  (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String(),
+	}, nil
+}
+
+func (s *localStore) Open(uri string) (io.ReadCloser, error) {
+	path, err := s.resolve(uri)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *localStore) Delete(uri string) error {
+	path, err := s.resolve(uri)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	refs, err := s.refs(path)
+	if err != nil {
+		return err
+	}
+	if refs == 0 {
+		return ErrNotFound
+	}
+	if refs > 1 {
+		return writeRefs(path, refs-1)
+	}
+	if err = os.Remove(path); err != nil {
+		return err
+	}
+	if err = os.Remove(refsPath(path)); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// refs 读取内容的引用次数，内容不存在时为0；没有计数文件的内容视为一次引用
+func (s *localStore) refs(path string) (int, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	b, err := os.ReadFile(refsPath(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(b)))
+}
+
+func writeRefs(path string, refs int) error {
+	return os.WriteFile(refsPath(path), []byte(strconv.Itoa(refs)), 0o644)
+}
+
+// refsPath 引用计数文件，以.开头，无法通过URI访问
+func refsPath(path string) string {
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".refs")
+}
+
+// resolve 将URI转换为root下的文件路径，拒绝root之外的路径
+func (s *localStore) resolve(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", ErrBadURI
+	}
+	path := filepath.Clean(filepath.FromSlash(u.Path))
+	if filepath.Dir(path) != s.root || strings.HasPrefix(filepath.Base(path), ".") {
+		return "", ErrBadURI
+	}
+	return path, nil
+}
@@ -0,0 +1,116 @@
+package blob
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStore_PutOpenDelete(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewLocalStore(root)
+	if err != nil {
+		t.Fatalf("NewLocalStore() error = %v", err)
+	}
+
+	got, err := s.Put("hello.txt", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got.Name != "hello.txt" || got.Size != 5 {
+		t.Errorf("Put() = %+v, want name hello.txt size 5", got)
+	}
+	wantHash := "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if got.Hash != wantHash {
+		t.Errorf("Put() hash = %v, want %v", got.Hash, wantHash)
+	}
+
+	rc, err := s.Open(got.URI)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	content, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	if string(content) != "hello" {
+		t.Errorf("Open() content = %v, want hello", string(content))
+	}
+
+	// 相同内容寻址到同一位置
+	again, err := s.Put("copy.txt", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if again.URI != got.URI {
+		t.Errorf("Put() uri = %v, want %v", again.URI, got.URI)
+	}
+
+	// 仍被另一个附件引用时保留内容
+	if err = s.Delete(got.URI); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if rc, err = s.Open(again.URI); err != nil {
+		t.Fatalf("Open() after first Delete() error = %v", err)
+	}
+	_ = rc.Close()
+	if err = s.Delete(again.URI); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err = s.Open(got.URI); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open() error = %v, wantErr %v", err, ErrNotFound)
+	}
+	if err = s.Delete(got.URI); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() error = %v, wantErr %v", err, ErrNotFound)
+	}
+	if left, _ := filepath.Glob(filepath.Join(root, "*")); len(left) != 0 {
+		t.Errorf("Delete() left files behind: %v", left)
+	}
+	if hidden, _ := filepath.Glob(filepath.Join(root, ".*")); len(hidden) != 0 {
+		t.Errorf("Delete() left files behind: %v", hidden)
+	}
+}
+
+func TestLocalStore_BadURI(t *testing.T) {
+	root := t.TempDir()
+	s, _ := NewLocalStore(root)
+	tests := []struct {
+		name string
+		uri  string
+	}{
+		{name: "bad scheme", uri: "http://example.com/a"},
+		{name: "outside root", uri: "file://" + filepath.ToSlash(filepath.Join(filepath.Dir(root), "other"))},
+		{name: "traversal", uri: "file://" + filepath.ToSlash(root) + "/../x"},
+		{name: "temp file", uri: "file://" + filepath.ToSlash(filepath.Join(root, ".upload-1"))},
+		{name: "unparsable", uri: "file://%zz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Open(tt.uri); !errors.Is(err, ErrBadURI) {
+				t.Errorf("Open() error = %v, wantErr %v", err, ErrBadURI)
+			}
+			if err := s.Delete(tt.uri); !errors.Is(err, ErrBadURI) {
+				t.Errorf("Delete() error = %v, wantErr %v", err, ErrBadURI)
+			}
+		})
+	}
+}
+
+type failReader struct{}
+
+func (failReader) Read(_ []byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestLocalStore_PutReadError(t *testing.T) {
+	root := t.TempDir()
+	s, _ := NewLocalStore(root)
+	if _, err := s.Put("broken", failReader{}); err == nil {
+		t.Errorf("Put() error = nil, want error")
+	}
+	matches, _ := filepath.Glob(filepath.Join(root, "*"))
+	hidden, _ := filepath.Glob(filepath.Join(root, ".*"))
+	if len(matches)+len(hidden) != 0 {
+		t.Errorf("Put() left files behind: %v %v", matches, hidden)
+	}
+}
@@ -0,0 +1,54 @@
+package eventsource
+
+import (
+	"time"
+
+	"github.com/victorwong171/punched-tape/models"
+)
+
+const (
+	KindOpened        = "opened"          // 发起工单
+	KindApproval      = "approval"        // 审批/驳回操作
+	KindService       = "service"         // 自动步骤执行结果
+	KindJump          = "jump"            // 管理员跳转
+	KindRollback      = "rollback"        // 管理员回退
+	KindReopen        = "reopen"          // 重新打开已结束的工单
+	KindReassign      = "reassign"        // 更换待处理人
+	KindChildComplete = "child_completed" // 子工单结束
+)
+
+// Command 操作的全部输入，事件回放时原样交给审批引擎
+// 各事件类型使用的字段：
+//   - approval: Next、Operation、Operator、Admin、Comment、Attachments、CC、FormData
+//   - service: Operation为处理器结果或models.ServiceError，Comment为失败原因
+//   - jump/rollback/reopen: Next为目标步骤，Operator、Comment为原因、CC
+//   - reassign: Operator、Operators、Comment、CC
+//   - child_completed: Child为子工单标识，Operation为子工单结束状态
+type Command struct {
+	Next        string               `json:"next"`        // 下一步骤
+	Operation   string               `json:"operation"`   // 操作名
+	Operator    string               `json:"operator"`    // 操作人
+	Admin       bool                 `json:"admin"`       // 是否以管理员身份操作
+	Comment     string               `json:"comment"`     // 审批意见
+	Attachments []*models.Attachment `json:"attachments"` // 附件
+	CC          []string             `json:"cc"`          // 追加抄送
+	Operators   []string             `json:"operators"`   // 更换后的待处理人
+	Child       string               `json:"child"`       // 子工单标识
+	FormData    map[string]any       `json:"form_data"`   // 修改后的完整表单数据，为nil时不修改
+}
+
+// Event 不可变的工单事件，工单状态是事件序列的投影
+type Event struct {
+	Seq       int64          `json:"seq"`        // 工单内序号，从1开始连续递增
+	Ticket    string         `json:"ticket"`     // 工单唯一标识
+	Kind      string         `json:"kind"`       // 事件类型，见Kind*
+	Opened    *models.Ticket `json:"opened"`     // 发起时的工单，仅opened事件使用
+	Command   *Command       `json:"command"`    // 操作输入，opened以外的事件使用
+	CreatedAt time.Time      `json:"created_at"` // 事件时间
+}
+
+// Snapshot 工单在某一事件序号处的状态，用于加速长历史的回放
+type Snapshot struct {
+	Seq    int64          `json:"seq"`    // 快照包含的最后一个事件序号
+	Ticket *models.Ticket `json:"ticket"` // 快照时的工单
+}
@@ -0,0 +1,125 @@
+package eventsource
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/victorwong171/punched-tape/models"
+	"github.com/victorwong171/punched-tape/ticket/ticket"
+)
+
+var (
+	ErrBadEvent    = errors.New("bad event")
+	ErrEventOrder  = errors.New("event out of order")
+	ErrNotOpened   = errors.New("ticket not opened")
+	ErrBadTemplate = errors.New("bad template")
+)
+
+// Replay 从事件序列重建工单，相同模板与事件总是得到相同结果
+func Replay(tpl *models.TicketTemplate, events []*Event) (*models.Ticket, error) {
+	return ReplayFrom(tpl, nil, events)
+}
+
+// ReplayFrom 从快照开始重建工单，events应为快照之后的事件；snapshot为nil时从头回放
+func ReplayFrom(tpl *models.TicketTemplate, snapshot *Snapshot, events []*Event) (*models.Ticket, error) {
+	if tpl == nil {
+		return nil, ErrBadTemplate
+	}
+	var (
+		current *models.Ticket
+		seq     int64
+	)
+	if snapshot != nil {
+		current, seq = snapshot.Ticket.Clone(), snapshot.Seq
+	}
+	helper := replayHelper()
+	stepConfig := tpl.StepConfigMap()
+	for _, e := range events {
+		next, err := apply(helper, tpl, stepConfig, current, seq, e)
+		if err != nil {
+			return nil, err
+		}
+		current, seq = next, e.Seq
+	}
+	if current == nil {
+		return nil, ErrNotOpened
+	}
+	return current, nil
+}
+
+// replayHelper 回放使用的审批引擎，不注册监听器，避免重复通知
+// 不注册处理器，自动步骤的结果来自service事件；记录的操作已通过权限校验，回放时不再判定管理员能力
+func replayHelper() *ticket.Helper {
+	helper := &ticket.Helper{}
+	helper.SetAuthorizer(ticket.AuthorizerFunc(func(string, string, *models.Ticket, *models.StepConfig, string) bool {
+		return true
+	}))
+	return helper
+}
+
+func apply(
+	helper *ticket.Helper,
+	tpl *models.TicketTemplate,
+	stepConfig map[string]*models.StepConfig,
+	current *models.Ticket,
+	seq int64,
+	e *Event) (*models.Ticket, error) {
+	if e == nil {
+		return nil, ErrBadEvent
+	}
+	if e.Seq != seq+1 {
+		return nil, fmt.Errorf("%w: expect %d, got %d", ErrEventOrder, seq+1, e.Seq)
+	}
+	if e.Kind == KindOpened {
+		if current != nil || e.Opened == nil {
+			return nil, fmt.Errorf("%w: seq %d", ErrBadEvent, e.Seq)
+		}
+		return e.Opened.Clone(), nil
+	}
+	tr, err := run(helper, tpl, stepConfig, current, e.Kind, e.Command, ticket.WithTime(e.CreatedAt))
+	if err != nil {
+		return nil, err
+	}
+	return tr.Ticket, nil
+}
+
+// run 将操作交给审批引擎执行，记录与回放共用，保证两者结果一致
+func run(
+	helper *ticket.Helper,
+	tpl *models.TicketTemplate,
+	stepConfig map[string]*models.StepConfig,
+	current *models.Ticket,
+	kind string,
+	c *Command,
+	opts ...ticket.ApprovalOption) (*models.Transition, error) {
+	if current == nil {
+		return nil, ErrNotOpened
+	}
+	if c == nil {
+		return nil, fmt.Errorf("%w: %s without command", ErrBadEvent, kind)
+	}
+	opts = append(opts, ticket.WithComment(c.Comment), ticket.WithCC(c.CC...))
+	switch kind {
+	case KindApproval:
+		opts = append(opts, ticket.WithAttachments(c.Attachments...))
+		if c.FormData != nil {
+			opts = append(opts, ticket.WithFormData(tpl.Form, c.FormData))
+		}
+		return helper.Transit(c.Next, c.Operation, c.Operator, c.Admin, tpl.EndStep, current, stepConfig, opts...)
+	case KindService:
+		return helper.CompleteService(c.Operation, c.Comment, tpl.EndStep, current, stepConfig, opts...)
+	case KindJump:
+		return helper.Jump(c.Operator, c.Next, c.Comment, tpl.EndStep, current, stepConfig, opts...)
+	case KindRollback:
+		return helper.Rollback(c.Operator, c.Next, c.Comment, tpl.EndStep, current, stepConfig, opts...)
+	case KindReopen:
+		return helper.Reopen(c.Operator, c.Next, c.Comment, tpl, current, opts...)
+	case KindReassign:
+		return helper.Reassign(c.Operator, c.Operators, current, stepConfig, opts...)
+	case KindChildComplete:
+		child := &models.Ticket{Uid: c.Child, Parent: current.Uid, Status: c.Operation}
+		return helper.CompleteChild(current, child, tpl.EndStep, stepConfig, opts...)
+	default:
+		return nil, fmt.Errorf("%w: unknown kind %s", ErrBadEvent, kind)
+	}
+}
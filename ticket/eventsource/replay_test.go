@@ -0,0 +1,150 @@
+package eventsource
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func testTemplate() *models.TicketTemplate {
+	return &models.TicketTemplate{
+		Uid:       "leave",
+		StartStep: "apply",
+		EndStep:   []string{"end"},
+		Config: []*models.StepConfig{
+			{
+				Step:     "apply",
+				Operator: []string{"alice"},
+				Disposal: models.Disposal{SignType: models.AnyoneSign},
+				Next:     []*models.NextStep{{Operation: "submit", Step: "review"}},
+			},
+			{
+				Step:     "review",
+				Operator: []string{"bob", "carol"},
+				Disposal: models.Disposal{SignType: models.SerialSign},
+				Next:     []*models.NextStep{{Operation: "approve", Step: "end"}},
+			},
+			{Step: "end"},
+		},
+	}
+}
+
+func testEvents(at time.Time) []*Event {
+	return []*Event{
+		{
+			Seq: 1, Ticket: "t1", Kind: KindOpened, CreatedAt: at,
+			Opened: &models.Ticket{Uid: "t1", Status: models.Running, Step: "apply", Operator: []string{"alice"}},
+		},
+		{
+			Seq: 2, Ticket: "t1", Kind: KindApproval, CreatedAt: at.Add(time.Minute),
+			Command: &Command{Next: "review", Operation: "submit", Operator: "alice", Comment: "please"},
+		},
+		{
+			Seq: 3, Ticket: "t1", Kind: KindApproval, CreatedAt: at.Add(2 * time.Minute),
+			Command: &Command{Next: "end", Operation: "approve", Operator: "bob"},
+		},
+	}
+}
+
+func TestReplay(t *testing.T) {
+	at := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	events := testEvents(at)
+	tests := []struct {
+		name    string
+		events  []*Event
+		want    *models.Ticket
+		wantErr error
+	}{
+		{
+			name:   "all is ok",
+			events: events,
+			want: &models.Ticket{
+				Uid:          "t1",
+				Status:       models.Running,
+				Step:         "review",
+				Operator:     []string{"carol"},
+				OperatedUser: []string{"bob"},
+				Visits:       map[string]int{"review": 1},
+				Traversals:   map[string]int{"apply->review": 1},
+				Tally: &models.Tally{Step: "review", Approved: 1, Votes: []*models.Vote{
+					{Operator: "bob", Operation: "approve", Next: "end", Weight: 1},
+				}},
+				History: []*models.Action{
+					{Operator: "alice", Operation: "submit", Step: "apply", Next: "review", Comment: "please", CreatedAt: at.Add(time.Minute)},
+					{Operator: "bob", Operation: "approve", Step: "review", Next: "end", CreatedAt: at.Add(2 * time.Minute)},
+				},
+			},
+		},
+		{
+			name:    "no events",
+			events:  nil,
+			wantErr: ErrNotOpened,
+		},
+		{
+			name:    "gap in sequence",
+			events:  []*Event{events[0], events[2]},
+			wantErr: ErrEventOrder,
+		},
+		{
+			name:    "approval before opened",
+			events:  []*Event{{Seq: 1, Kind: KindApproval, Command: events[1].Command}},
+			wantErr: ErrNotOpened,
+		},
+		{
+			name:    "unknown kind",
+			events:  []*Event{events[0], {Seq: 2, Kind: "unknown"}},
+			wantErr: ErrBadEvent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Replay(testTemplate(), tt.events)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Replay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); len(diff) > 0 {
+				t.Errorf("Replay() diff = %v", diff)
+			}
+		})
+	}
+}
+
+func TestReplay_Deterministic(t *testing.T) {
+	events := testEvents(time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC))
+	first, err := Replay(testTemplate(), events)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	second, err := Replay(testTemplate(), events)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if diff := cmp.Diff(first, second); len(diff) > 0 {
+		t.Errorf("Replay() not deterministic, diff = %v", diff)
+	}
+	if events[0].Opened.Step != "apply" {
+		t.Errorf("Replay() mutated opened event: %+v", events[0].Opened)
+	}
+}
+
+func TestReplayFrom(t *testing.T) {
+	events := testEvents(time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC))
+	full, err := Replay(testTemplate(), events)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	partial, err := Replay(testTemplate(), events[:2])
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	got, err := ReplayFrom(testTemplate(), &Snapshot{Seq: 2, Ticket: partial}, events[2:])
+	if err != nil {
+		t.Fatalf("ReplayFrom() error = %v", err)
+	}
+	if diff := cmp.Diff(got, full); len(diff) > 0 {
+		t.Errorf("ReplayFrom() diff = %v", diff)
+	}
+}
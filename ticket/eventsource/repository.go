@@ -0,0 +1,207 @@
+package eventsource
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/victorwong171/punched-tape/models"
+	"github.com/victorwong171/punched-tape/ticket/form"
+	"github.com/victorwong171/punched-tape/ticket/ticket"
+)
+
+// Repository 事件溯源模式的工单仓库，工单状态只通过事件变更
+type Repository struct {
+	store         EventStore
+	helper        *ticket.Helper
+	snapshotEvery int64
+	now           func() time.Time
+}
+
+// RepositoryOption Repository的可选参数
+type RepositoryOption func(*Repository)
+
+// WithSnapshotEvery 每追加n个事件保存一次快照，n<=0时不保存快照
+func WithSnapshotEvery(n int64) RepositoryOption {
+	return func(r *Repository) {
+		r.snapshotEvery = n
+	}
+}
+
+// WithHelper 指定审批引擎，可用于注册监听器
+func WithHelper(helper *ticket.Helper) RepositoryOption {
+	return func(r *Repository) {
+		r.helper = helper
+	}
+}
+
+// NewRepository 创建工单仓库
+func NewRepository(store EventStore, opts ...RepositoryOption) *Repository {
+	r := &Repository{
+		store:         store,
+		helper:        &ticket.Helper{},
+		snapshotEvery: 50,
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Open 发起工单，按模板表单定义校验表单数据后记录opened事件；未指定业务状态时取当前步骤StepConfig.State
+func (r *Repository) Open(tpl *models.TicketTemplate, t *models.Ticket) (*models.Ticket, error) {
+	if tpl == nil {
+		return nil, ErrBadTemplate
+	}
+	if t == nil || len(t.Uid) == 0 {
+		return nil, ticket.ErrBadArguments
+	}
+	if err := form.Validate(tpl.Form, t.FormData); err != nil {
+		return nil, fmt.Errorf("%w: %w", ticket.ErrBadFormData, err)
+	}
+	opened := t.Clone()
+	if len(opened.State) == 0 {
+		opened.State = tpl.StepConfigMap()[opened.Step].GetState()
+	}
+	e := &Event{Seq: 1, Ticket: t.Uid, Kind: KindOpened, Opened: opened.Clone(), CreatedAt: r.now()}
+	if err := r.store.Append(t.Uid, 0, e); err != nil {
+		return nil, err
+	}
+	return opened, nil
+}
+
+// Load 读取工单当前状态，优先从快照开始回放
+func (r *Repository) Load(tpl *models.TicketTemplate, uid string) (*models.Ticket, error) {
+	current, _, err := r.load(tpl, uid)
+	return current, err
+}
+
+// Approve 执行审批操作，成功后记录approval事件；并发修改时返回ErrConcurrentModification
+func (r *Repository) Approve(tpl *models.TicketTemplate, uid string, cmd *Command) (*models.Ticket, error) {
+	return r.execute(tpl, uid, KindApproval, cmd)
+}
+
+// Jump 管理员跳转到任意步骤，cmd.Next为目标步骤
+func (r *Repository) Jump(tpl *models.TicketTemplate, uid string, cmd *Command) (*models.Ticket, error) {
+	return r.execute(tpl, uid, KindJump, cmd)
+}
+
+// Rollback 管理员回退到已经过的步骤，cmd.Next为空时回退到上一步骤，事件中记录实际的目标步骤
+func (r *Repository) Rollback(tpl *models.TicketTemplate, uid string, cmd *Command) (*models.Ticket, error) {
+	return r.execute(tpl, uid, KindRollback, cmd)
+}
+
+// Reopen 重新打开已结束的工单，cmd.Next为目标步骤
+func (r *Repository) Reopen(tpl *models.TicketTemplate, uid string, cmd *Command) (*models.Ticket, error) {
+	return r.execute(tpl, uid, KindReopen, cmd)
+}
+
+// Reassign 更换当前步骤的待处理人，cmd.Operators为新的待处理人
+func (r *Repository) Reassign(tpl *models.TicketTemplate, uid string, cmd *Command) (*models.Ticket, error) {
+	return r.execute(tpl, uid, KindReassign, cmd)
+}
+
+// CompleteChild 子工单结束后推进父工单
+func (r *Repository) CompleteChild(tpl *models.TicketTemplate, uid string, child *models.Ticket) (*models.Ticket, error) {
+	if child == nil {
+		return nil, ticket.ErrBadArguments
+	}
+	return r.execute(tpl, uid, KindChildComplete, &Command{Operation: child.Status, Child: child.Uid})
+}
+
+// RunService 执行工单当前所在的自动步骤，处理器结果记录为service事件
+func (r *Repository) RunService(tpl *models.TicketTemplate, uid string) (*models.Ticket, error) {
+	return r.execute(tpl, uid, KindService, nil)
+}
+
+// execute 以配置的审批引擎执行一次操作，将操作与随后连续执行的自动步骤结果记录为事件
+// 回放只读取记录的结果，不重新执行处理器；事件落盘后再通知监听器
+// 处理器的副作用在落盘前已经发生，并发修改导致落盘失败时由处理器自行保证幂等
+func (r *Repository) execute(tpl *models.TicketTemplate, uid, kind string, cmd *Command) (*models.Ticket, error) {
+	if cmd == nil && kind != KindService {
+		return nil, ticket.ErrBadArguments
+	}
+	current, seq, err := r.load(tpl, uid)
+	if err != nil {
+		return nil, err
+	}
+	now := r.now()
+	opts := []ticket.ApprovalOption{ticket.WithTime(now), ticket.WithoutNotify()}
+	var tr *models.Transition
+	if kind == KindService {
+		tr, err = r.helper.RunService(current, tpl.EndStep, tpl.StepConfigMap(), opts...)
+	} else {
+		tr, err = run(r.helper, tpl, tpl.StepConfigMap(), current, kind, cmd, opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	actions := tr.Ticket.History[len(current.History):]
+	events := make([]*Event, 0, len(actions))
+	for i, a := range actions {
+		e := &Event{Seq: seq + int64(i) + 1, Ticket: uid, Kind: KindService, Command: serviceCommand(a), CreatedAt: now}
+		if i == 0 && kind != KindService {
+			e.Kind, e.Command = kind, recorded(kind, cmd, a)
+		}
+		events = append(events, e)
+	}
+	if err = r.store.Append(uid, seq, events...); err != nil {
+		return nil, err
+	}
+	last := events[len(events)-1].Seq
+	if r.snapshotEvery > 0 && last/r.snapshotEvery > seq/r.snapshotEvery {
+		if err = r.store.SaveSnapshot(uid, &Snapshot{Seq: last, Ticket: tr.Ticket.Clone()}); err != nil {
+			return nil, err
+		}
+	}
+	r.helper.Notify(tr)
+	return tr.Ticket, nil
+}
+
+// serviceCommand 自动步骤的执行结果
+func serviceCommand(a *models.Action) *Command {
+	return &Command{Operation: a.Operation, Comment: a.Comment}
+}
+
+// recorded 记录的操作输入，回退未指定目标时记录引擎选择的步骤，保证回放结果不依赖回退规则
+func recorded(kind string, cmd *Command, a *models.Action) *Command {
+	if kind != KindRollback || len(cmd.Next) > 0 {
+		return cmd
+	}
+	c := *cmd
+	c.Next = a.Next
+	return &c
+}
+
+// Events 读取工单全部事件
+func (r *Repository) Events(uid string) ([]*Event, error) {
+	return r.store.Load(uid, 0)
+}
+
+func (r *Repository) load(tpl *models.TicketTemplate, uid string) (*models.Ticket, int64, error) {
+	snapshot, err := r.store.LoadSnapshot(uid)
+	if err != nil {
+		return nil, 0, err
+	}
+	var after int64
+	if snapshot != nil {
+		after = snapshot.Seq
+	}
+	events, err := r.store.Load(uid, after)
+	if err != nil {
+		return nil, 0, err
+	}
+	if snapshot == nil && len(events) == 0 {
+		return nil, 0, ErrTicketNotFound
+	}
+	current, err := ReplayFrom(tpl, snapshot, events)
+	if err != nil {
+		return nil, 0, err
+	}
+	seq := after
+	if len(events) > 0 {
+		seq = events[len(events)-1].Seq
+	}
+	return current, seq, nil
+}
@@ -0,0 +1,308 @@
+package eventsource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/victorwong171/punched-tape/models"
+	"github.com/victorwong171/punched-tape/ticket/ticket"
+)
+
+func TestRepository(t *testing.T) {
+	var transitions int
+	helper := &ticket.Helper{}
+	helper.Register(ticket.ListenerFunc(func(*models.Transition) { transitions++ }))
+	store := NewMemoryStore()
+	repo := NewRepository(store, WithHelper(helper), WithSnapshotEvery(2))
+	at := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return at }
+	tpl := testTemplate()
+
+	if _, err := repo.Load(tpl, "t1"); !errors.Is(err, ErrTicketNotFound) {
+		t.Fatalf("Load() error = %v, want %v", err, ErrTicketNotFound)
+	}
+	if _, err := repo.Open(tpl, &models.Ticket{Uid: "t1", Status: models.Running, Step: "apply", Operator: []string{"alice"}}); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, err := repo.Approve(tpl, "t1", &Command{Next: "end", Operation: "approve", Operator: "alice"}); !errors.Is(err, ticket.ErrInvalidStep) {
+		t.Fatalf("Approve() error = %v, want %v", err, ticket.ErrInvalidStep)
+	}
+	if _, err := repo.Approve(tpl, "t1", &Command{Next: "review", Operation: "submit", Operator: "alice"}); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	got, err := repo.Approve(tpl, "t1", &Command{Next: "end", Operation: "approve", Operator: "bob"})
+	if err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+
+	events, err := repo.Events("t1")
+	if err != nil {
+		t.Fatalf("Events() error = %v", err)
+	}
+	if len(events) != 3 {
+		t.Errorf("Events() len = %d, want 3 (rejected command must not be recorded)", len(events))
+	}
+	if transitions != 2 {
+		t.Errorf("listener received %d transitions, want 2", transitions)
+	}
+	snapshot, _ := store.LoadSnapshot("t1")
+	if snapshot == nil || snapshot.Seq != 2 {
+		t.Errorf("LoadSnapshot() = %+v, want seq 2", snapshot)
+	}
+	loaded, err := repo.Load(tpl, "t1")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if diff := cmp.Diff(loaded, got); len(diff) > 0 {
+		t.Errorf("Load() diff = %v", diff)
+	}
+	replayed, err := Replay(tpl, events)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if diff := cmp.Diff(replayed, got); len(diff) > 0 {
+		t.Errorf("Replay() diff = %v", diff)
+	}
+}
+
+func TestRepository_OpenState(t *testing.T) {
+	tpl := testTemplate()
+	tpl.States = []string{"drafting", "in_review"}
+	tpl.Config[0].State = "drafting"
+	repo := NewRepository(NewMemoryStore())
+	opened := &models.Ticket{Uid: "t1", Status: models.Running, Step: "apply", Operator: []string{"alice"}}
+
+	got, err := repo.Open(tpl, opened)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got.State != "drafting" || len(opened.State) != 0 {
+		t.Errorf("Open() state = %q, input state = %q, want drafting and unchanged input", got.State, opened.State)
+	}
+	loaded, err := repo.Load(tpl, "t1")
+	if err != nil || loaded.State != "drafting" {
+		t.Errorf("Load() = %+v, error = %v, want state drafting", loaded, err)
+	}
+}
+
+func TestRepository_FormData(t *testing.T) {
+	tpl := testTemplate()
+	tpl.Form = []*models.FormField{
+		{Name: "days", Type: models.FieldNumber, Required: true},
+		{Name: "reason", Type: models.FieldString},
+	}
+	tpl.Config[0].Editable = []string{"days"}
+	repo := NewRepository(NewMemoryStore())
+	opened := &models.Ticket{Uid: "t1", Status: models.Running, Step: "apply", Operator: []string{"alice"}}
+
+	if _, err := repo.Open(nil, opened); !errors.Is(err, ErrBadTemplate) {
+		t.Errorf("Open() without template error = %v, want %v", err, ErrBadTemplate)
+	}
+	if _, err := repo.Open(tpl, opened); !errors.Is(err, ticket.ErrBadFormData) {
+		t.Fatalf("Open() without required field error = %v, want %v", err, ticket.ErrBadFormData)
+	}
+	opened.FormData = map[string]any{"days": 3, "reason": "trip"}
+	if _, err := repo.Open(tpl, opened); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, err := repo.Approve(tpl, "t1", &Command{
+		Next: "review", Operation: "submit", Operator: "alice", FormData: map[string]any{"days": 3, "reason": "holiday"},
+	}); !errors.Is(err, ticket.ErrBadFormData) {
+		t.Fatalf("Approve() editing read-only field error = %v, want %v", err, ticket.ErrBadFormData)
+	}
+	got, err := repo.Approve(tpl, "t1", &Command{
+		Next: "review", Operation: "submit", Operator: "alice", FormData: map[string]any{"days": 2, "reason": "trip"},
+	})
+	if err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	if diff := cmp.Diff(got.FormData, map[string]any{"days": 2, "reason": "trip"}); len(diff) > 0 {
+		t.Errorf("Approve() form data diff = %v", diff)
+	}
+	loaded, err := repo.Load(tpl, "t1")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if diff := cmp.Diff(loaded, got); len(diff) > 0 {
+		t.Errorf("Load() diff = %v", diff)
+	}
+}
+
+func TestMemoryStore_Append(t *testing.T) {
+	store := NewMemoryStore()
+	if err := store.Append("t1", 0, &Event{Seq: 1}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	tests := []struct {
+		name        string
+		expectedSeq int64
+		events      []*Event
+		wantErr     error
+	}{
+		{name: "stale", expectedSeq: 0, events: []*Event{{Seq: 1}}, wantErr: ErrConcurrentModification},
+		{name: "bad seq", expectedSeq: 1, events: []*Event{{Seq: 3}}, wantErr: ErrConcurrentModification},
+		{name: "ok", expectedSeq: 1, events: []*Event{{Seq: 2}, {Seq: 3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := store.Append("t1", tt.expectedSeq, tt.events...); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Append() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	events, _ := store.Load("t1", 1)
+	if len(events) != 2 || events[0].Seq != 2 {
+		t.Errorf("Load() = %+v", events)
+	}
+}
+
+func TestRepository_Operations(t *testing.T) {
+	tpl := &models.TicketTemplate{
+		Uid:       "purchase",
+		StartStep: "apply",
+		EndStep:   []string{"end"},
+		Reopen:    models.ReopenPolicy{Statuses: []string{models.Passed}},
+		Config: []*models.StepConfig{
+			{
+				Step:     "apply",
+				Operator: []string{"alice"},
+				Disposal: models.Disposal{SignType: models.AnyoneSign},
+				Next:     []*models.NextStep{{Operation: "submit", Step: "check"}},
+			},
+			{
+				Step:    "check",
+				Kind:    models.StepKindService,
+				Service: models.ServiceTask{Handler: "risk"},
+				Next:    []*models.NextStep{{Operation: "ok", Step: "review"}},
+			},
+			{
+				Step:     "review",
+				Operator: []string{"bob"},
+				Disposal: models.Disposal{SignType: models.AnyoneSign},
+				Next:     []*models.NextStep{{Operation: "approve", Step: "sign"}},
+			},
+			{
+				Step:     "sign",
+				Kind:     models.StepKindSubProcess,
+				Template: "contract",
+				Next:     []*models.NextStep{{Operation: models.Passed, Step: "end"}},
+			},
+			{Step: "end"},
+		},
+	}
+	type op func(r *Repository) (*models.Ticket, error)
+	approve := func(cmd *Command) op {
+		return func(r *Repository) (*models.Ticket, error) { return r.Approve(tpl, "t1", cmd) }
+	}
+	submit := approve(&Command{Next: "check", Operation: "submit", Operator: "alice"})
+	tests := []struct {
+		name       string
+		ops        []op
+		wantStep   string
+		wantStatus string
+		wantKinds  []string
+		wantCalls  int
+	}{
+		{
+			name:       "service result is recorded",
+			ops:        []op{submit},
+			wantStep:   "review",
+			wantStatus: models.Running,
+			wantKinds:  []string{KindOpened, KindApproval, KindService},
+			wantCalls:  1,
+		},
+		{
+			name: "jump rollback and reassign",
+			ops: []op{
+				submit,
+				func(r *Repository) (*models.Ticket, error) {
+					return r.Jump(tpl, "t1", &Command{Operator: "root", Next: "sign", Comment: "skip"})
+				},
+				func(r *Repository) (*models.Ticket, error) {
+					return r.Rollback(tpl, "t1", &Command{Operator: "root", Next: "review", Comment: "redo"})
+				},
+				func(r *Repository) (*models.Ticket, error) {
+					return r.Reassign(tpl, "t1", &Command{Operator: "root", Operators: []string{"dave"}})
+				},
+			},
+			wantStep:   "review",
+			wantStatus: models.Running,
+			wantKinds:  []string{KindOpened, KindApproval, KindService, KindJump, KindRollback, KindReassign},
+			wantCalls:  1,
+		},
+		{
+			name: "child completed and reopened",
+			ops: []op{
+				submit,
+				approve(&Command{Next: "sign", Operation: "approve", Operator: "bob"}),
+				func(r *Repository) (*models.Ticket, error) {
+					return r.CompleteChild(tpl, "t1", &models.Ticket{Uid: "t1-sign-1", Parent: "t1", Status: models.Passed})
+				},
+				func(r *Repository) (*models.Ticket, error) {
+					return r.Reopen(tpl, "t1", &Command{Operator: "root", Next: "apply", Comment: "amend"})
+				},
+				submit,
+			},
+			wantStep:   "review",
+			wantStatus: models.Running,
+			wantKinds: []string{
+				KindOpened, KindApproval, KindService, KindApproval, KindChildComplete, KindReopen, KindApproval, KindService,
+			},
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			helper := &ticket.Helper{}
+			helper.RegisterHandler("risk", ticket.ServiceHandlerFunc(func(context.Context, *models.Ticket) (string, error) {
+				calls++
+				return "ok", nil
+			}))
+			helper.SetAuthorizer(ticket.Capabilities{"root": {models.CapJump, models.CapRollback, models.CapReassign}})
+			repo := NewRepository(NewMemoryStore(), WithHelper(helper), WithSnapshotEvery(3))
+			repo.now = func() time.Time { return time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC) }
+			if _, err := repo.Open(tpl, &models.Ticket{Uid: "t1", Status: models.Running, Step: "apply", Operator: []string{"alice"}}); err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			var got *models.Ticket
+			for i, o := range tt.ops {
+				var err error
+				if got, err = o(repo); err != nil {
+					t.Fatalf("operation %d error = %v", i, err)
+				}
+			}
+			if got.Step != tt.wantStep || got.Status != tt.wantStatus {
+				t.Errorf("got step %s status %s, want %s %s", got.Step, got.Status, tt.wantStep, tt.wantStatus)
+			}
+			loaded, err := repo.Load(tpl, "t1")
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if diff := cmp.Diff(loaded, got); len(diff) > 0 {
+				t.Errorf("Load() diff = %v", diff)
+			}
+			events, _ := repo.Events("t1")
+			kinds := make([]string, 0, len(events))
+			for _, e := range events {
+				kinds = append(kinds, e.Kind)
+			}
+			if diff := cmp.Diff(kinds, tt.wantKinds); len(diff) > 0 {
+				t.Errorf("Events() kinds diff = %v", diff)
+			}
+			replayed, err := Replay(tpl, events)
+			if err != nil {
+				t.Fatalf("Replay() error = %v", err)
+			}
+			if diff := cmp.Diff(replayed, got); len(diff) > 0 {
+				t.Errorf("Replay() diff = %v", diff)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
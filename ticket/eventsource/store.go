@@ -0,0 +1,79 @@
+package eventsource
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	ErrConcurrentModification = errors.New("concurrent modification")
+	ErrTicketNotFound         = errors.New("ticket not found")
+)
+
+// EventStore 事件存储，事件只能追加
+type EventStore interface {
+	// Append 追加事件，当前最大序号不等于expectedSeq时返回ErrConcurrentModification
+	Append(ticket string, expectedSeq int64, events ...*Event) error
+	// Load 读取序号大于afterSeq的事件，按序号排序
+	Load(ticket string, afterSeq int64) ([]*Event, error)
+	// SaveSnapshot 保存快照
+	SaveSnapshot(ticket string, snapshot *Snapshot) error
+	// LoadSnapshot 读取最新快照，不存在时返回nil
+	LoadSnapshot(ticket string) (*Snapshot, error)
+}
+
+type memoryStore struct {
+	mu        sync.RWMutex
+	events    map[string][]*Event
+	snapshots map[string]*Snapshot
+}
+
+// NewMemoryStore 创建基于内存的事件存储
+func NewMemoryStore() EventStore {
+	return &memoryStore{
+		events:    make(map[string][]*Event),
+		snapshots: make(map[string]*Snapshot),
+	}
+}
+
+func (s *memoryStore) Append(ticket string, expectedSeq int64, events ...*Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if int64(len(s.events[ticket])) != expectedSeq {
+		return ErrConcurrentModification
+	}
+	for i, e := range events {
+		if e.Seq != expectedSeq+int64(i)+1 {
+			return ErrConcurrentModification
+		}
+	}
+	s.events[ticket] = append(s.events[ticket], events...)
+	return nil
+}
+
+func (s *memoryStore) Load(ticket string, afterSeq int64) ([]*Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	events := s.events[ticket]
+	if afterSeq >= int64(len(events)) {
+		return []*Event{}, nil
+	}
+	return append([]*Event(nil), events[max(afterSeq, 0):]...), nil
+}
+
+func (s *memoryStore) SaveSnapshot(ticket string, snapshot *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots[ticket] = &Snapshot{Seq: snapshot.Seq, Ticket: snapshot.Ticket.Clone()}
+	return nil
+}
+
+func (s *memoryStore) LoadSnapshot(ticket string) (*Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snapshot, ok := s.snapshots[ticket]
+	if !ok {
+		return nil, nil
+	}
+	return &Snapshot{Seq: snapshot.Seq, Ticket: snapshot.Ticket.Clone()}, nil
+}
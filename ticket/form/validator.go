@@ -0,0 +1,231 @@
+package form
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"github.com/victorwong171/go-utils/desc/set"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+var (
+	ErrUnknownField     = errors.New("unknown form field")
+	ErrFieldRequired    = errors.New("form field is required")
+	ErrFieldType        = errors.New("form field type mismatch")
+	ErrFieldOutOfRange  = errors.New("form field out of range")
+	ErrFieldPattern     = errors.New("form field does not match pattern")
+	ErrFieldOption      = errors.New("form field not in options")
+	ErrFieldNotEditable = errors.New("form field is not editable in current step")
+)
+
+// dateLayouts date类型字段可接受的字符串格式
+var dateLayouts = []string{time.DateOnly, time.RFC3339}
+
+// Validate 校验表单数据，在发起工单时使用，所有字段均可填写
+func Validate(schema []*models.FormField, data map[string]any) error {
+	fields := make(map[string]*models.FormField, len(schema))
+	for _, f := range schema {
+		fields[f.Name] = f
+	}
+	for name := range data {
+		if _, ok := fields[name]; !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownField, name)
+		}
+	}
+	for _, f := range schema {
+		if err := validateField(f, data[f.Name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateStep 校验步骤处理时提交的表单数据
+// 仅允许修改step.Editable中声明的字段，修改后的完整数据仍需满足schema；step为nil时不允许修改任何字段
+// 提交的数据通常来自Visible，隐藏字段按MergeHidden取原值后再比较
+func ValidateStep(schema []*models.FormField, step *models.StepConfig, before, after map[string]any) error {
+	after = MergeHidden(step, before, after)
+	editable := set.Setify(step.GetEditable()...)
+	for _, f := range schema {
+		oldVal, oldOk := before[f.Name]
+		newVal, newOk := after[f.Name]
+		if oldOk == newOk && reflect.DeepEqual(oldVal, newVal) {
+			continue
+		}
+		if !editable.HasKey(f.Name) {
+			return fmt.Errorf("%w: %s", ErrFieldNotEditable, f.Name)
+		}
+	}
+	return Validate(schema, after)
+}
+
+// MergeHidden 将当前步骤隐藏字段的原值合并回提交的数据，提交中的隐藏字段被忽略，不修改原数据
+func MergeHidden(step *models.StepConfig, before, after map[string]any) map[string]any {
+	hidden := step.GetHidden()
+	if len(hidden) == 0 {
+		return after
+	}
+	merged := make(map[string]any, len(after)+len(hidden))
+	for k, v := range after {
+		merged[k] = v
+	}
+	for _, name := range hidden {
+		if v, ok := before[name]; ok {
+			merged[name] = v
+		} else {
+			delete(merged, name)
+		}
+	}
+	return merged
+}
+
+// Visible 返回当前步骤可见的表单数据，隐藏字段被剔除，不修改原数据
+func Visible(step *models.StepConfig, data map[string]any) map[string]any {
+	hidden := set.Setify(step.GetHidden()...)
+	visible := make(map[string]any, len(data))
+	for k, v := range data {
+		if !hidden.HasKey(k) {
+			visible[k] = v
+		}
+	}
+	return visible
+}
+
+func validateField(f *models.FormField, val any) error {
+	if isEmpty(val) {
+		if f.Required {
+			return fmt.Errorf("%w: %s", ErrFieldRequired, f.Name)
+		}
+		return nil
+	}
+
+	switch f.Type {
+	case models.FieldString, models.FieldUser:
+		s, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrFieldType, f.Name)
+		}
+		if err := checkRange(f, float64(utf8.RuneCountInString(s))); err != nil {
+			return err
+		}
+		if len(f.Pattern) > 0 {
+			matched, err := regexp.MatchString(f.Pattern, s)
+			if err != nil {
+				return err
+			}
+			if !matched {
+				return fmt.Errorf("%w: %s", ErrFieldPattern, f.Name)
+			}
+		}
+	case models.FieldNumber:
+		n, ok := toNumber(val)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrFieldType, f.Name)
+		}
+		return checkRange(f, n)
+	case models.FieldDate:
+		if !isDate(val) {
+			return fmt.Errorf("%w: %s", ErrFieldType, f.Name)
+		}
+	case models.FieldEnum:
+		s, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrFieldType, f.Name)
+		}
+		if !set.Setify(f.Options...).HasKey(s) {
+			return fmt.Errorf("%w: %s", ErrFieldOption, f.Name)
+		}
+	case models.FieldAttachment:
+		refs, ok := toRefs(val)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrFieldType, f.Name)
+		}
+		return checkRange(f, float64(len(refs)))
+	default:
+		return fmt.Errorf("%w: %s", ErrFieldType, f.Name)
+	}
+	return nil
+}
+
+func checkRange(f *models.FormField, n float64) error {
+	if f.Min != nil && n < *f.Min {
+		return fmt.Errorf("%w: %s", ErrFieldOutOfRange, f.Name)
+	}
+	if f.Max != nil && n > *f.Max {
+		return fmt.Errorf("%w: %s", ErrFieldOutOfRange, f.Name)
+	}
+	return nil
+}
+
+func isEmpty(val any) bool {
+	switch v := val.(type) {
+	case nil:
+		return true
+	case string:
+		return len(v) == 0
+	case []string:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
+
+func toNumber(val any) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func isDate(val any) bool {
+	switch v := val.(type) {
+	case time.Time:
+		return !v.IsZero()
+	case string:
+		for _, layout := range dateLayouts {
+			if _, err := time.Parse(layout, v); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// toRefs 附件字段的值为附件引用（URI）或其列表
+func toRefs(val any) ([]string, bool) {
+	switch v := val.(type) {
+	case string:
+		return []string{v}, true
+	case []string:
+		return v, true
+	case []any:
+		refs := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			refs = append(refs, s)
+		}
+		return refs, true
+	}
+	return nil, false
+}
@@ -0,0 +1,251 @@
+package form
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func ptr(f float64) *float64 {
+	return &f
+}
+
+var testSchema = []*models.FormField{
+	{Name: "reason", Type: models.FieldString, Required: true, Min: ptr(2), Max: ptr(20)},
+	{Name: "amount", Type: models.FieldNumber, Required: true, Min: ptr(0), Max: ptr(10000)},
+	{Name: "date", Type: models.FieldDate},
+	{Name: "kind", Type: models.FieldEnum, Options: []string{"travel", "meal"}},
+	{Name: "approver", Type: models.FieldUser, Pattern: `^u\d+$`},
+	{Name: "receipt", Type: models.FieldAttachment, Max: ptr(2)},
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]any
+		wantErr error
+	}{
+		{
+			name: "all is ok",
+			data: map[string]any{
+				"reason":   "business trip",
+				"amount":   1200.5,
+				"date":     "2026-10-17",
+				"kind":     "travel",
+				"approver": "u1001",
+				"receipt":  []any{"file://a", "file://b"},
+			},
+		},
+		{
+			name: "number types",
+			data: map[string]any{"reason": "ok", "amount": json.Number("12")},
+		},
+		{
+			name: "int and time",
+			data: map[string]any{"reason": "ok", "amount": 12, "date": time.Now(), "receipt": "file://a"},
+		},
+		{
+			name:    "unknown field",
+			data:    map[string]any{"reason": "ok", "amount": 1, "foo": "bar"},
+			wantErr: ErrUnknownField,
+		},
+		{
+			name:    "required missing",
+			data:    map[string]any{"amount": 1},
+			wantErr: ErrFieldRequired,
+		},
+		{
+			name:    "required empty string",
+			data:    map[string]any{"reason": "", "amount": 1},
+			wantErr: ErrFieldRequired,
+		},
+		{
+			name:    "string too short",
+			data:    map[string]any{"reason": "a", "amount": 1},
+			wantErr: ErrFieldOutOfRange,
+		},
+		{
+			name:    "string type mismatch",
+			data:    map[string]any{"reason": 1, "amount": 1},
+			wantErr: ErrFieldType,
+		},
+		{
+			name:    "number type mismatch",
+			data:    map[string]any{"reason": "ok", "amount": "1"},
+			wantErr: ErrFieldType,
+		},
+		{
+			name:    "number too large",
+			data:    map[string]any{"reason": "ok", "amount": 10001},
+			wantErr: ErrFieldOutOfRange,
+		},
+		{
+			name:    "bad date",
+			data:    map[string]any{"reason": "ok", "amount": 1, "date": "17/10/2026"},
+			wantErr: ErrFieldType,
+		},
+		{
+			name:    "enum not in options",
+			data:    map[string]any{"reason": "ok", "amount": 1, "kind": "hotel"},
+			wantErr: ErrFieldOption,
+		},
+		{
+			name:    "enum type mismatch",
+			data:    map[string]any{"reason": "ok", "amount": 1, "kind": 1},
+			wantErr: ErrFieldType,
+		},
+		{
+			name:    "user pattern mismatch",
+			data:    map[string]any{"reason": "ok", "amount": 1, "approver": "alice"},
+			wantErr: ErrFieldPattern,
+		},
+		{
+			name:    "too many attachments",
+			data:    map[string]any{"reason": "ok", "amount": 1, "receipt": []string{"a", "b", "c"}},
+			wantErr: ErrFieldOutOfRange,
+		},
+		{
+			name:    "attachment type mismatch",
+			data:    map[string]any{"reason": "ok", "amount": 1, "receipt": []any{1}},
+			wantErr: ErrFieldType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(testSchema, tt.data); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_UnknownType(t *testing.T) {
+	schema := []*models.FormField{{Name: "x", Type: "unknown"}}
+	if err := Validate(schema, map[string]any{"x": 1}); !errors.Is(err, ErrFieldType) {
+		t.Errorf("Validate() error = %v, wantErr %v", err, ErrFieldType)
+	}
+}
+
+func TestValidateStep(t *testing.T) {
+	before := map[string]any{"reason": "business trip", "amount": 100}
+	step := &models.StepConfig{Step: "finance", Editable: []string{"amount"}}
+	tests := []struct {
+		name    string
+		after   map[string]any
+		wantErr error
+	}{
+		{
+			name:  "unchanged",
+			after: map[string]any{"reason": "business trip", "amount": 100},
+		},
+		{
+			name:  "edit editable field",
+			after: map[string]any{"reason": "business trip", "amount": 80},
+		},
+		{
+			name:    "edit read-only field",
+			after:   map[string]any{"reason": "holiday", "amount": 100},
+			wantErr: ErrFieldNotEditable,
+		},
+		{
+			name:    "add read-only field",
+			after:   map[string]any{"reason": "business trip", "amount": 100, "kind": "meal"},
+			wantErr: ErrFieldNotEditable,
+		},
+		{
+			name:    "editable field still validated",
+			after:   map[string]any{"reason": "business trip", "amount": -1},
+			wantErr: ErrFieldOutOfRange,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateStep(testSchema, step, before, tt.after); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateStep() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if err := ValidateStep(testSchema, nil, before, map[string]any{"reason": "business trip", "amount": 80}); !errors.Is(err, ErrFieldNotEditable) {
+		t.Errorf("ValidateStep() with nil step error = %v, want %v", err, ErrFieldNotEditable)
+	}
+}
+
+func TestMergeHidden(t *testing.T) {
+	before := map[string]any{"reason": "business trip", "amount": 100}
+	step := &models.StepConfig{Editable: []string{"reason"}, Hidden: []string{"amount"}}
+	tests := []struct {
+		name  string
+		step  *models.StepConfig
+		after map[string]any
+		want  map[string]any
+	}{
+		{
+			name:  "hidden field restored",
+			step:  step,
+			after: map[string]any{"reason": "holiday"},
+			want:  map[string]any{"reason": "holiday", "amount": 100},
+		},
+		{
+			name:  "submitted hidden field ignored",
+			step:  step,
+			after: map[string]any{"reason": "holiday", "amount": 1},
+			want:  map[string]any{"reason": "holiday", "amount": 100},
+		},
+		{
+			name:  "nil step",
+			after: map[string]any{"reason": "holiday"},
+			want:  map[string]any{"reason": "holiday"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(MergeHidden(tt.step, before, tt.after), tt.want); len(diff) > 0 {
+				t.Errorf("MergeHidden() diff = %v", diff)
+			}
+		})
+	}
+	// Visible返回的数据修改可编辑字段后可以通过校验
+	after := Visible(step, before)
+	after["reason"] = "holiday"
+	if err := ValidateStep(testSchema, step, before, after); err != nil {
+		t.Errorf("ValidateStep() on visible data error = %v", err)
+	}
+}
+
+func TestVisible(t *testing.T) {
+	data := map[string]any{"reason": "business trip", "amount": 100}
+	tests := []struct {
+		name string
+		step *models.StepConfig
+		want map[string]any
+	}{
+		{
+			name: "nothing hidden",
+			step: &models.StepConfig{},
+			want: map[string]any{"reason": "business trip", "amount": 100},
+		},
+		{
+			name: "amount hidden",
+			step: &models.StepConfig{Hidden: []string{"amount"}},
+			want: map[string]any{"reason": "business trip"},
+		},
+		{
+			name: "nil step",
+			want: map[string]any{"reason": "business trip", "amount": 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(Visible(tt.step, data), tt.want); len(diff) > 0 {
+				t.Errorf("Visible() diff = %v", diff)
+			}
+		})
+	}
+	if len(data) != 2 {
+		t.Errorf("Visible() modified input data: %v", data)
+	}
+}
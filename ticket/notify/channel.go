@@ -0,0 +1,19 @@
+package notify
+
+// Message 渲染完成、待发送的通知
+type Message struct {
+	Key      string   `json:"key"`      // 去重键
+	Event    string   `json:"event"`    // 流转事件
+	Template string   `json:"template"` // 工单模板唯一标识
+	Ticket   string   `json:"ticket"`   // 工单唯一标识
+	To       []string `json:"to"`       // 接收用户
+	ToRoles  []string `json:"to_roles"` // 接收角色
+	Subject  string   `json:"subject"`  // 标题
+	Body     string   `json:"body"`     // 正文
+}
+
+// Channel 通知渠道，如邮件、webhook、群机器人
+type Channel interface {
+	Name() string
+	Send(msg *Message) error
+}
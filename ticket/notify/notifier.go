@@ -0,0 +1,310 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/victorwong171/go-utils/desc/set"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+var (
+	ErrBadTemplate = errors.New("bad notification template")
+	ErrBadResponse = errors.New("bad response")
+)
+
+const (
+	defaultRetries     = 2
+	defaultBackoff     = 500 * time.Millisecond
+	defaultDedupWindow = 24 * time.Hour
+)
+
+// defaultTemplates 未为模板单独配置时使用的消息模板，未列出的事件默认不通知
+var defaultTemplates = map[string][2]string{
+	models.EventStepEntered: {
+		`[{{.Ticket.OrderNum}}] {{.Ticket.Name}} 待处理`,
+		`工单「{{.Ticket.Name}}」已进入步骤 {{.To}}，处理人：{{join .Ticket.Operator "、"}}`,
+	},
+	models.EventPassed: {
+		`[{{.Ticket.OrderNum}}] {{.Ticket.Name}} 已通过`,
+		`工单「{{.Ticket.Name}}」已通过，最后操作人：{{.Action.Operator}}`,
+	},
+	models.EventRejected: {
+		`[{{.Ticket.OrderNum}}] {{.Ticket.Name}} 已驳回`,
+		`工单「{{.Ticket.Name}}」已被 {{.Action.Operator}} 驳回{{if .Action.Comment}}，意见：{{.Action.Comment}}{{end}}`,
+	},
+}
+
+var funcMap = template.FuncMap{
+	"join": strings.Join,
+}
+
+// Data 消息模板的渲染数据
+type Data struct {
+	*models.Transition
+	Event string
+}
+
+type messageTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// Notifier 监听工单流转，按事件渲染消息并通过各渠道发送
+// 实现了ticket.Listener，可直接注册到Helper；OnTransition在后台按流转顺序发送，不阻塞审批
+type Notifier struct {
+	mu        sync.Mutex
+	wg        sync.WaitGroup
+	last      chan struct{}
+	channels  []Channel
+	templates map[string]*messageTemplate
+	retries   int
+	backoff   time.Duration
+	window    time.Duration
+	sent      map[string]time.Time
+	now       func() time.Time
+	sleep     func(time.Duration)
+	onError   func(err error)
+}
+
+// Option Notifier的可选配置
+type Option func(*Notifier)
+
+// WithRetry 设置发送失败后的重试次数与首次重试间隔，间隔按指数增长
+func WithRetry(retries int, backoff time.Duration) Option {
+	return func(n *Notifier) {
+		n.retries = retries
+		n.backoff = backoff
+	}
+}
+
+// WithDedupWindow 设置去重窗口，窗口内相同的通知只发送一次
+func WithDedupWindow(window time.Duration) Option {
+	return func(n *Notifier) {
+		n.window = window
+	}
+}
+
+// WithErrorHandler 设置OnTransition中发送失败时的回调，回调在后台goroutine中执行
+func WithErrorHandler(onError func(err error)) Option {
+	return func(n *Notifier) {
+		n.onError = onError
+	}
+}
+
+// NewNotifier 创建通知器
+func NewNotifier(channels []Channel, opts ...Option) *Notifier {
+	n := &Notifier{
+		channels:  channels,
+		templates: make(map[string]*messageTemplate),
+		retries:   defaultRetries,
+		backoff:   defaultBackoff,
+		window:    defaultDedupWindow,
+		sent:      make(map[string]time.Time),
+		now:       time.Now,
+		sleep:     time.Sleep,
+		onError:   func(error) {},
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	for event, tpl := range defaultTemplates {
+		if err := n.SetTemplate("", event, tpl[0], tpl[1]); err != nil {
+			panic(err)
+		}
+	}
+	return n
+}
+
+// SetTemplate 设置工单模板templateUid在event事件下的消息模板，templateUid为空时作为所有模板的默认值
+// subject与body使用text/template语法，渲染数据为Data
+func (n *Notifier) SetTemplate(templateUid, event, subject, body string) error {
+	subjectTpl, err := template.New("subject").Funcs(funcMap).Parse(subject)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBadTemplate, err)
+	}
+	bodyTpl, err := template.New("body").Funcs(funcMap).Parse(body)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBadTemplate, err)
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.templates[templateKey(templateUid, event)] = &messageTemplate{subject: subjectTpl, body: bodyTpl}
+	return nil
+}
+
+// OnTransition 实现ticket.Listener，在后台发送，发送与重试不阻塞审批操作
+// 后一次流转的消息在前一次发送结束后才发送，保证通知顺序与流转顺序一致
+func (n *Notifier) OnTransition(tr *models.Transition) {
+	n.mu.Lock()
+	prev, done := n.last, make(chan struct{})
+	n.last = done
+	n.mu.Unlock()
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		defer close(done)
+		if prev != nil {
+			<-prev
+		}
+		if err := n.Notify(tr); err != nil {
+			n.onError(err)
+		}
+	}()
+}
+
+// Wait 等待OnTransition发起的发送全部结束，可用于退出前排空通知
+func (n *Notifier) Wait() {
+	n.wg.Wait()
+}
+
+// Notify 为流转中的每个事件渲染消息并发送到所有渠道
+func (n *Notifier) Notify(tr *models.Transition) error {
+	var errs []error
+	for _, event := range tr.Events {
+		msg, err := n.render(tr, event)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if msg == nil {
+			continue
+		}
+		for i, ch := range n.channels {
+			if err = n.send(i, ch, msg); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", ch.Name(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) lookup(templateUid, event string) *messageTemplate {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if tpl, ok := n.templates[templateKey(templateUid, event)]; ok {
+		return tpl
+	}
+	return n.templates[templateKey("", event)]
+}
+
+func (n *Notifier) render(tr *models.Transition, event string) (*Message, error) {
+	// 进入结束步骤时由passed/rejected事件通知，不再发送待处理通知
+	if event == models.EventStepEntered && tr.Ticket.GetStatus() != models.Running {
+		return nil, nil
+	}
+	templateUid := tr.Ticket.GetTemplate()
+	tpl := n.lookup(templateUid, event)
+	if tpl == nil {
+		return nil, nil
+	}
+
+	data := &Data{Transition: tr, Event: event}
+	var subject, body bytes.Buffer
+	if err := tpl.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadTemplate, err)
+	}
+	if err := tpl.body.Execute(&body, data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadTemplate, err)
+	}
+	return &Message{
+		Key:      messageKey(tr, event),
+		Event:    event,
+		Template: templateUid,
+		Ticket:   tr.Ticket.GetUid(),
+		To:       recipients(tr, event),
+		ToRoles:  tr.CCRoles,
+		Subject:  subject.String(),
+		Body:     body.String(),
+	}, nil
+}
+
+// send 发送消息，失败时按指数退避重试，成功发送过的消息在去重窗口内不再发送
+// 去重键包含渠道序号，同类型的多个渠道（如两个webhook地址）分别去重
+func (n *Notifier) send(index int, ch Channel, msg *Message) error {
+	key := fmt.Sprintf("%d/%s/%s", index, ch.Name(), msg.Key)
+	if n.seen(key) {
+		return nil
+	}
+
+	var err error
+	backoff := n.backoff
+	for i := 0; i <= n.retries; i++ {
+		if i > 0 {
+			n.sleep(backoff)
+			backoff *= 2
+		}
+		if err = ch.Send(msg); err == nil {
+			n.markSent(key)
+			return nil
+		}
+	}
+	return err
+}
+
+func (n *Notifier) seen(key string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	now := n.now()
+	for k, at := range n.sent {
+		if now.Sub(at) > n.window {
+			delete(n.sent, k)
+		}
+	}
+	_, ok := n.sent[key]
+	return ok
+}
+
+func (n *Notifier) markSent(key string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent[key] = n.now()
+}
+
+func templateKey(templateUid, event string) string {
+	return templateUid + "/" + event
+}
+
+// recipients 进入步骤时通知新的处理人与抄送人，其余事件通知参与过的操作人与抄送人
+func recipients(tr *models.Transition, event string) []string {
+	var users []string
+	if event == models.EventStepEntered {
+		users = append(users, tr.Ticket.GetOperator()...)
+	} else {
+		for _, action := range tr.Ticket.GetHistory() {
+			users = append(users, action.GetOperator())
+		}
+	}
+	users = append(users, tr.CC...)
+
+	seen := set.InitSet[string](len(users))
+	result := make([]string, 0, len(users))
+	for _, u := range users {
+		if len(u) == 0 || seen.HasKey(u) {
+			continue
+		}
+		seen.Set(u)
+		result = append(result, u)
+	}
+	return result
+}
+
+// messageKey 同一工单同一操作的同一事件生成相同的键
+func messageKey(tr *models.Transition, event string) string {
+	var at int64
+	var operator string
+	if tr.Action != nil {
+		at = tr.Action.CreatedAt.UnixNano()
+		operator = tr.Action.Operator
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s|%s|%d", tr.Ticket.GetUid(), event, tr.From, tr.To, operator, at)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,316 @@
+package notify
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/victorwong171/punched-tape/models"
+	"github.com/victorwong171/punched-tape/ticket/ticket"
+)
+
+type recordChannel struct {
+	name     string
+	failures int
+	attempts int
+	messages []*Message
+}
+
+func (c *recordChannel) Name() string {
+	return c.name
+}
+
+func (c *recordChannel) Send(msg *Message) error {
+	c.attempts++
+	if c.failures > 0 {
+		c.failures--
+		return errors.New("temporary failure")
+	}
+	c.messages = append(c.messages, msg)
+	return nil
+}
+
+func newTestNotifier(channels []Channel, opts ...Option) *Notifier {
+	n := NewNotifier(channels, opts...)
+	n.sleep = func(time.Duration) {}
+	return n
+}
+
+func enteredTransition() *models.Transition {
+	return &models.Transition{
+		Ticket: &models.Ticket{
+			Uid:      "t1",
+			OrderNum: "HR-20261017-0001",
+			Status:   models.Running,
+			Name:     "报销",
+			Template: "expense",
+			Step:     "finance",
+			Operator: []string{"fin1", "fin2"},
+			History:  []*models.Action{{Operator: "boss"}},
+		},
+		From:    "manager",
+		To:      "finance",
+		Action:  &models.Action{Operator: "boss", Operation: "approve", CreatedAt: time.Unix(100, 0)},
+		Events:  []string{models.EventStepLeft, models.EventStepEntered},
+		CC:      []string{"watcher", "fin1"},
+		CCRoles: []string{"hr"},
+	}
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	ch := &recordChannel{name: "record"}
+	n := newTestNotifier([]Channel{ch})
+	if err := n.Notify(enteredTransition()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if len(ch.messages) != 1 {
+		t.Fatalf("channel received %d messages, want 1", len(ch.messages))
+	}
+	msg := ch.messages[0]
+	if msg.Event != models.EventStepEntered || msg.Ticket != "t1" || msg.Template != "expense" {
+		t.Errorf("message = %+v", msg)
+	}
+	if msg.Subject != "[HR-20261017-0001] 报销 待处理" {
+		t.Errorf("message subject = %v", msg.Subject)
+	}
+	if !strings.Contains(msg.Body, "finance") || !strings.Contains(msg.Body, "fin1、fin2") {
+		t.Errorf("message body = %v", msg.Body)
+	}
+	if diff := cmp.Diff(msg.To, []string{"fin1", "fin2", "watcher"}); len(diff) > 0 {
+		t.Errorf("message to diff = %v", diff)
+	}
+	if diff := cmp.Diff(msg.ToRoles, []string{"hr"}); len(diff) > 0 {
+		t.Errorf("message roles diff = %v", diff)
+	}
+}
+
+func TestNotifier_SetTemplate(t *testing.T) {
+	ch := &recordChannel{name: "record"}
+	n := newTestNotifier([]Channel{ch})
+	if err := n.SetTemplate("expense", models.EventStepLeft, "left {{.From}}", "{{.Event}} by {{.Action.Operator}}"); err != nil {
+		t.Fatalf("SetTemplate() error = %v", err)
+	}
+	if err := n.SetTemplate("expense", models.EventStepEntered, "entered {{.To}}", "{{.Ticket.Name}}"); err != nil {
+		t.Fatalf("SetTemplate() error = %v", err)
+	}
+	if err := n.Notify(enteredTransition()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	got := make([]string, 0, len(ch.messages))
+	for _, msg := range ch.messages {
+		got = append(got, msg.Subject+"|"+msg.Body)
+	}
+	want := []string{"left manager|step_left by boss", "entered finance|报销"}
+	if diff := cmp.Diff(got, want); len(diff) > 0 {
+		t.Errorf("messages diff = %v", diff)
+	}
+	if diff := cmp.Diff(ch.messages[0].To, []string{"boss", "watcher", "fin1"}); len(diff) > 0 {
+		t.Errorf("step_left recipients diff = %v", diff)
+	}
+}
+
+func TestNotifier_BadTemplate(t *testing.T) {
+	n := newTestNotifier(nil)
+	if err := n.SetTemplate("", models.EventPassed, "{{.Bad", "body"); !errors.Is(err, ErrBadTemplate) {
+		t.Errorf("SetTemplate() error = %v, wantErr %v", err, ErrBadTemplate)
+	}
+	if err := n.SetTemplate("", models.EventPassed, "subject", "{{end}}"); !errors.Is(err, ErrBadTemplate) {
+		t.Errorf("SetTemplate() error = %v, wantErr %v", err, ErrBadTemplate)
+	}
+
+	// 渲染时访问不存在的字段
+	ch := &recordChannel{name: "record"}
+	n = newTestNotifier([]Channel{ch})
+	_ = n.SetTemplate("", models.EventStepEntered, "{{.Missing}}", "body")
+	if err := n.Notify(enteredTransition()); !errors.Is(err, ErrBadTemplate) {
+		t.Errorf("Notify() error = %v, wantErr %v", err, ErrBadTemplate)
+	}
+	_ = n.SetTemplate("", models.EventStepEntered, "subject", "{{.Missing}}")
+	if err := n.Notify(enteredTransition()); !errors.Is(err, ErrBadTemplate) {
+		t.Errorf("Notify() error = %v, wantErr %v", err, ErrBadTemplate)
+	}
+}
+
+func TestNotifier_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		retries      int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "success after retry", failures: 2, retries: 2, wantAttempts: 3, wantErr: false},
+		{name: "retry exhausted", failures: 5, retries: 2, wantAttempts: 3, wantErr: true},
+		{name: "no retry", failures: 1, retries: 0, wantAttempts: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := &recordChannel{name: "record", failures: tt.failures}
+			var sleeps []time.Duration
+			n := newTestNotifier([]Channel{ch}, WithRetry(tt.retries, time.Second))
+			n.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+			err := n.Notify(enteredTransition())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ch.attempts != tt.wantAttempts {
+				t.Errorf("attempts = %v, want %v", ch.attempts, tt.wantAttempts)
+			}
+			for i, d := range sleeps {
+				if want := time.Second << i; d != want {
+					t.Errorf("sleep[%d] = %v, want %v", i, d, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNotifier_Dedup(t *testing.T) {
+	ch := &recordChannel{name: "record"}
+	now := time.Unix(1000, 0)
+	n := newTestNotifier([]Channel{ch}, WithDedupWindow(time.Hour))
+	n.now = func() time.Time { return now }
+
+	tr := enteredTransition()
+	_ = n.Notify(tr)
+	_ = n.Notify(tr)
+	if len(ch.messages) != 1 {
+		t.Errorf("channel received %d messages, want 1", len(ch.messages))
+	}
+
+	// 不同操作不去重
+	other := enteredTransition()
+	other.Action.CreatedAt = time.Unix(200, 0)
+	_ = n.Notify(other)
+	if len(ch.messages) != 2 {
+		t.Errorf("channel received %d messages, want 2", len(ch.messages))
+	}
+
+	// 超出去重窗口后重新发送
+	now = now.Add(2 * time.Hour)
+	_ = n.Notify(tr)
+	if len(ch.messages) != 3 {
+		t.Errorf("channel received %d messages, want 3", len(ch.messages))
+	}
+}
+
+func TestNotifier_DedupPerChannel(t *testing.T) {
+	a := newFakeWebhookServer(t, 0)
+	b := newFakeWebhookServer(t, 0)
+	n := newTestNotifier([]Channel{NewWebhookChannel(a.URL, nil), NewWebhookChannel(b.URL, nil)})
+
+	tr := enteredTransition()
+	if err := n.Notify(tr); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	_ = n.Notify(tr)
+	// 同类型的渠道分别去重，每个地址各收到一次
+	if got := []int{len(a.Bodies()), len(b.Bodies())}; !cmp.Equal(got, []int{1, 1}) {
+		t.Errorf("webhook servers received %v requests, want [1 1]", got)
+	}
+}
+
+func TestNotifier_OnTransition(t *testing.T) {
+	ch := &recordChannel{name: "record", failures: 10}
+	var handled []error
+	n := newTestNotifier([]Channel{ch}, WithRetry(0, 0), WithErrorHandler(func(err error) {
+		handled = append(handled, err)
+	}))
+	n.OnTransition(enteredTransition())
+	n.Wait()
+	if len(handled) != 1 || !strings.HasPrefix(handled[0].Error(), "record: ") {
+		t.Errorf("error handler received %v", handled)
+	}
+}
+
+type blockingChannel struct {
+	recordChannel
+	release chan struct{}
+}
+
+func (c *blockingChannel) Send(msg *Message) error {
+	<-c.release
+	return c.recordChannel.Send(msg)
+}
+
+func TestNotifier_OnTransitionAsync(t *testing.T) {
+	ch := &blockingChannel{recordChannel: recordChannel{name: "record"}, release: make(chan struct{})}
+	n := newTestNotifier([]Channel{ch})
+	passed := enteredTransition()
+	passed.Ticket.Status = models.Passed
+	passed.Events = []string{models.EventPassed}
+
+	// 渠道阻塞时OnTransition仍立即返回
+	n.OnTransition(enteredTransition())
+	n.OnTransition(passed)
+	close(ch.release)
+	n.Wait()
+
+	var events []string
+	for _, msg := range ch.messages {
+		events = append(events, msg.Event)
+	}
+	if diff := cmp.Diff(events, []string{models.EventStepEntered, models.EventPassed}); len(diff) > 0 {
+		t.Errorf("message events diff = %v", diff)
+	}
+}
+
+func TestNotifier_WithHelper(t *testing.T) {
+	smtpServer := newFakeSMTPServer(t)
+	webhookServer := newFakeWebhookServer(t, 1)
+	chatServer := newFakeWebhookServer(t, 0)
+	n := newTestNotifier([]Channel{
+		NewSMTPChannel(smtpServer.Addr(), "noreply@example.com", nil, func(user string) string {
+			return user + "@example.com"
+		}),
+		NewWebhookChannel(webhookServer.URL, nil),
+		NewChatBotChannel(chatServer.URL, nil),
+	})
+
+	h := &ticket.Helper{}
+	h.Register(n)
+	stepConfig := map[string]*models.StepConfig{
+		"start": {
+			Step:     "start",
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "submit", Step: "review"}},
+		},
+		"review": {
+			Step:     "review",
+			Operator: []string{"boss"},
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "pass", Step: "end"}},
+		},
+		"end": {Step: "end"},
+	}
+	tk := &models.Ticket{Uid: "t1", Name: "报销", Status: models.Running, Step: "start", Operator: []string{"alice"}}
+	tk, err := h.Approval("review", "submit", "alice", false, []string{"end"}, tk, stepConfig)
+	if err != nil {
+		t.Fatalf("Approval() error = %v", err)
+	}
+	if _, err = h.Approval("end", "pass", "boss", false, []string{"end"}, tk, stepConfig); err != nil {
+		t.Fatalf("Approval() error = %v", err)
+	}
+	n.Wait()
+
+	// 进入review与工单通过各一封邮件
+	mails := smtpServer.Mails()
+	if len(mails) != 2 {
+		t.Fatalf("smtp server received %d mails, want 2", len(mails))
+	}
+	if mails[0].To[0] != "boss@example.com" {
+		t.Errorf("first mail to = %v, want boss@example.com", mails[0].To)
+	}
+	if diff := cmp.Diff(mails[1].To, []string{"alice@example.com", "boss@example.com"}); len(diff) > 0 {
+		t.Errorf("second mail to diff = %v", diff)
+	}
+	// webhook首次失败后重试成功
+	if got := len(webhookServer.Bodies()); got != 2 {
+		t.Errorf("webhook server received %d requests, want 2", got)
+	}
+	if got := len(chatServer.Bodies()); got != 2 {
+		t.Errorf("chatbot server received %d requests, want 2", got)
+	}
+}
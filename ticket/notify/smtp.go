@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+)
+
+type smtpChannel struct {
+	addr      string
+	from      string
+	auth      smtp.Auth
+	addressOf func(user string) string
+	sendMail  func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPChannel 创建邮件通知渠道，addr为host:port，auth可为nil
+// addressOf将用户标识转换为邮箱地址，为nil时直接使用用户标识
+func NewSMTPChannel(addr, from string, auth smtp.Auth, addressOf func(user string) string) Channel {
+	if addressOf == nil {
+		addressOf = func(user string) string { return user }
+	}
+	return &smtpChannel{
+		addr:      addr,
+		from:      from,
+		auth:      auth,
+		addressOf: addressOf,
+		sendMail:  smtp.SendMail,
+	}
+}
+
+func (c *smtpChannel) Name() string {
+	return "smtp"
+}
+
+func (c *smtpChannel) Send(msg *Message) error {
+	to := make([]string, 0, len(msg.To))
+	for _, user := range msg.To {
+		if addr := c.addressOf(user); len(addr) > 0 {
+			to = append(to, addr)
+		}
+	}
+	if len(to) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", c.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", encodeSubject(msg.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return c.sendMail(c.addr, c.auth, c.from, to, []byte(b.String()))
+}
+
+// encodeSubject 去除换行防止注入其他邮件头，并按RFC 2047编码非ASCII字符
+func encodeSubject(subject string) string {
+	subject = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(subject)
+	return mime.QEncoding.Encode("utf-8", subject)
+}
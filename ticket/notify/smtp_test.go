@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"mime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSMTPChannel_Send(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	ch := NewSMTPChannel(server.Addr(), "noreply@example.com", nil, func(user string) string {
+		if user == "nobody" {
+			return ""
+		}
+		return user + "@example.com"
+	})
+	if ch.Name() != "smtp" {
+		t.Errorf("Name() = %v, want smtp", ch.Name())
+	}
+
+	err := ch.Send(&Message{
+		To:      []string{"alice", "nobody", "bob"},
+		Subject: "待处理",
+		Body:    "line1\nline2",
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	mails := server.Mails()
+	if len(mails) != 1 {
+		t.Fatalf("server received %d mails, want 1", len(mails))
+	}
+	if mails[0].From != "noreply@example.com" {
+		t.Errorf("mail from = %v, want noreply@example.com", mails[0].From)
+	}
+	if diff := cmp.Diff(mails[0].To, []string{"alice@example.com", "bob@example.com"}); len(diff) > 0 {
+		t.Errorf("mail to diff = %v", diff)
+	}
+	for _, want := range []string{"Subject: " + mime.QEncoding.Encode("utf-8", "待处理"), "line1\r\nline2"} {
+		if !strings.Contains(mails[0].Data, want) {
+			t.Errorf("mail data = %q, want contains %q", mails[0].Data, want)
+		}
+	}
+}
+
+func TestSMTPChannel_SubjectInjection(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	ch := NewSMTPChannel(server.Addr(), "noreply@example.com", nil, nil)
+	if err := ch.Send(&Message{To: []string{"carol@example.com"}, Subject: "hi\r\nBcc: eve@example.com\nX: y"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	mails := server.Mails()
+	if len(mails) != 1 {
+		t.Fatalf("server received %d mails, want 1", len(mails))
+	}
+	if !strings.Contains(mails[0].Data, "Subject: hi Bcc: eve@example.com X: y\r\n") || strings.Contains(mails[0].Data, "\r\nBcc:") {
+		t.Errorf("mail data = %q, want subject on a single line", mails[0].Data)
+	}
+}
+
+func TestSMTPChannel_NoRecipients(t *testing.T) {
+	ch := NewSMTPChannel("127.0.0.1:1", "noreply@example.com", nil, nil)
+	if err := ch.Send(&Message{Subject: "x"}); err != nil {
+		t.Errorf("Send() error = %v, want nil", err)
+	}
+}
+
+func TestSMTPChannel_DefaultAddress(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	ch := NewSMTPChannel(server.Addr(), "noreply@example.com", nil, nil)
+	if err := ch.Send(&Message{To: []string{"carol@example.com"}}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if mails := server.Mails(); len(mails) != 1 || mails[0].To[0] != "carol@example.com" {
+		t.Errorf("server received %v, want mail to carol@example.com", mails)
+	}
+}
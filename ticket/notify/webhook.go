@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const defaultTimeout = 10 * time.Second
+
+type webhookChannel struct {
+	name   string
+	url    string
+	client *http.Client
+	encode func(msg *Message) ([]byte, error)
+}
+
+// NewWebhookChannel 创建通用webhook通知渠道，以JSON格式POST完整的Message
+func NewWebhookChannel(url string, client *http.Client) Channel {
+	return &webhookChannel{
+		name:   "webhook",
+		url:    url,
+		client: defaultClient(client),
+		encode: func(msg *Message) ([]byte, error) {
+			return json.Marshal(msg)
+		},
+	}
+}
+
+// NewChatBotChannel 创建群机器人通知渠道，请求体为 {"msgtype":"text","text":{"content":...}}
+// 兼容钉钉、企业微信等群机器人webhook
+func NewChatBotChannel(url string, client *http.Client) Channel {
+	return &webhookChannel{
+		name:   "chatbot",
+		url:    url,
+		client: defaultClient(client),
+		encode: func(msg *Message) ([]byte, error) {
+			content := msg.Body
+			if len(msg.Subject) > 0 {
+				content = msg.Subject + "\n" + msg.Body
+			}
+			return json.Marshal(map[string]any{
+				"msgtype": "text",
+				"text":    map[string]string{"content": content},
+			})
+		},
+	}
+}
+
+func defaultClient(client *http.Client) *http.Client {
+	if client == nil {
+		return &http.Client{Timeout: defaultTimeout}
+	}
+	return client
+}
+
+func (c *webhookChannel) Name() string {
+	return c.name
+}
+
+func (c *webhookChannel) Send(msg *Message) error {
+	body, err := c.encode(msg)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Post(c.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: %s", ErrBadResponse, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWebhookChannel_Send(t *testing.T) {
+	server := newFakeWebhookServer(t, 0)
+	ch := NewWebhookChannel(server.URL, nil)
+	if ch.Name() != "webhook" {
+		t.Errorf("Name() = %v, want webhook", ch.Name())
+	}
+	if err := ch.Send(&Message{Event: "passed", Ticket: "t1", Subject: "s", Body: "b"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	bodies := server.Bodies()
+	if len(bodies) != 1 {
+		t.Fatalf("server received %d requests, want 1", len(bodies))
+	}
+	if bodies[0]["event"] != "passed" || bodies[0]["ticket"] != "t1" || bodies[0]["body"] != "b" {
+		t.Errorf("server received %v", bodies[0])
+	}
+}
+
+func TestChatBotChannel_Send(t *testing.T) {
+	server := newFakeWebhookServer(t, 0)
+	ch := NewChatBotChannel(server.URL, nil)
+	if ch.Name() != "chatbot" {
+		t.Errorf("Name() = %v, want chatbot", ch.Name())
+	}
+	if err := ch.Send(&Message{Subject: "title", Body: "content"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := ch.Send(&Message{Body: "only body"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	bodies := server.Bodies()
+	if len(bodies) != 2 {
+		t.Fatalf("server received %d requests, want 2", len(bodies))
+	}
+	tests := []string{"title\ncontent", "only body"}
+	for i, want := range tests {
+		if bodies[i]["msgtype"] != "text" {
+			t.Errorf("msgtype = %v, want text", bodies[i]["msgtype"])
+		}
+		text, _ := bodies[i]["text"].(map[string]any)
+		if text["content"] != want {
+			t.Errorf("content = %v, want %v", text["content"], want)
+		}
+	}
+}
+
+func TestWebhookChannel_BadResponse(t *testing.T) {
+	server := newFakeWebhookServer(t, 1)
+	ch := NewWebhookChannel(server.URL, nil)
+	if err := ch.Send(&Message{}); !errors.Is(err, ErrBadResponse) {
+		t.Errorf("Send() error = %v, wantErr %v", err, ErrBadResponse)
+	}
+}
+
+func TestWebhookChannel_Unreachable(t *testing.T) {
+	server := newFakeWebhookServer(t, 0)
+	url := server.URL
+	server.Close()
+	if err := NewWebhookChannel(url, nil).Send(&Message{}); err == nil {
+		t.Errorf("Send() error = nil, want error")
+	}
+}
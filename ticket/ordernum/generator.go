@@ -0,0 +1,59 @@
+package ordernum
+
+import (
+	"errors"
+
+	"github.com/victorwong171/punched-tape/models"
+)
+
+// OrderNumGenerator 工单号生成器
+type OrderNumGenerator interface {
+	Generate(tpl *models.TicketTemplate) (string, error)
+}
+
+var (
+	ErrDuplicateOrderNum = errors.New("duplicate order num")
+	ErrRetryExhausted    = errors.New("order num retry exhausted")
+	ErrBadNodeID         = errors.New("bad snowflake node id")
+	ErrClockMovedBack    = errors.New("clock moved backwards")
+)
+
+// withPrefix 按模板配置的前缀拼接工单号，未配置前缀时原样返回
+func withPrefix(tpl *models.TicketTemplate, body string) string {
+	if tpl == nil || len(tpl.OrderNumPrefix) == 0 {
+		return body
+	}
+	return tpl.OrderNumPrefix + "-" + body
+}
+
+type uniqueGenerator struct {
+	generator OrderNumGenerator
+	store     Store
+	retries   int
+}
+
+// NewUniqueGenerator 包装生成器，生成后在store中占用工单号，冲突时最多重试retries次
+func NewUniqueGenerator(generator OrderNumGenerator, store Store, retries int) OrderNumGenerator {
+	return &uniqueGenerator{
+		generator: generator,
+		store:     store,
+		retries:   retries,
+	}
+}
+
+func (g *uniqueGenerator) Generate(tpl *models.TicketTemplate) (string, error) {
+	for i := 0; i <= g.retries; i++ {
+		orderNum, err := g.generator.Generate(tpl)
+		if err != nil {
+			return "", err
+		}
+		err = g.store.Reserve(orderNum)
+		if err == nil {
+			return orderNum, nil
+		}
+		if !errors.Is(err, ErrDuplicateOrderNum) {
+			return "", err
+		}
+	}
+	return "", ErrRetryExhausted
+}
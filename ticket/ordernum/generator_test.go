@@ -0,0 +1,136 @@
+package ordernum
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/victorwong171/punched-tape/models"
+)
+
+type fixedGenerator struct {
+	results []string
+	err     error
+	calls   int
+}
+
+func (g *fixedGenerator) Generate(_ *models.TicketTemplate) (string, error) {
+	if g.err != nil {
+		return "", g.err
+	}
+	result := g.results[g.calls%len(g.results)]
+	g.calls++
+	return result, nil
+}
+
+type failStore struct {
+	err error
+}
+
+func (s *failStore) Next(_ string) (int64, error) {
+	return 0, s.err
+}
+
+func (s *failStore) Reserve(_ string) error {
+	return s.err
+}
+
+func Test_withPrefix(t *testing.T) {
+	tests := []struct {
+		name string
+		tpl  *models.TicketTemplate
+		body string
+		want string
+	}{
+		{
+			name: "with prefix",
+			tpl:  &models.TicketTemplate{OrderNumPrefix: "HR"},
+			body: "20261017-0001",
+			want: "HR-20261017-0001",
+		},
+		{
+			name: "empty prefix",
+			tpl:  &models.TicketTemplate{},
+			body: "20261017-0001",
+			want: "20261017-0001",
+		},
+		{
+			name: "nil template",
+			tpl:  nil,
+			body: "20261017-0001",
+			want: "20261017-0001",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withPrefix(tt.tpl, tt.body); got != tt.want {
+				t.Errorf("withPrefix() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUniqueGenerator_Generate(t *testing.T) {
+	errStore := errors.New("store unavailable")
+	tests := []struct {
+		name      string
+		generator *fixedGenerator
+		store     Store
+		reserved  []string
+		retries   int
+		want      string
+		wantErr   error
+	}{
+		{
+			name:      "all is ok",
+			generator: &fixedGenerator{results: []string{"HR-1"}},
+			store:     NewMemoryStore(),
+			retries:   0,
+			want:      "HR-1",
+		},
+		{
+			name:      "retry on duplicate",
+			generator: &fixedGenerator{results: []string{"HR-1", "HR-2"}},
+			store:     NewMemoryStore(),
+			reserved:  []string{"HR-1"},
+			retries:   1,
+			want:      "HR-2",
+		},
+		{
+			name:      "retry exhausted",
+			generator: &fixedGenerator{results: []string{"HR-1"}},
+			store:     NewMemoryStore(),
+			reserved:  []string{"HR-1"},
+			retries:   2,
+			wantErr:   ErrRetryExhausted,
+		},
+		{
+			name:      "generator failed",
+			generator: &fixedGenerator{err: ErrClockMovedBack},
+			store:     NewMemoryStore(),
+			wantErr:   ErrClockMovedBack,
+		},
+		{
+			name:      "store failed",
+			generator: &fixedGenerator{results: []string{"HR-1"}},
+			store:     &failStore{err: errStore},
+			retries:   3,
+			wantErr:   errStore,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, r := range tt.reserved {
+				_ = tt.store.Reserve(r)
+			}
+			g := NewUniqueGenerator(tt.generator, tt.store, tt.retries)
+			got, err := g.Generate(&models.TicketTemplate{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Generate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package ordernum
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/victorwong171/punched-tape/models"
+)
+
+const (
+	dateLayout   = "20060102"
+	defaultWidth = 4
+)
+
+type sequentialGenerator struct {
+	store Store
+	width int
+	now   func() time.Time
+}
+
+// NewSequentialGenerator 创建按日期递增的工单号生成器，如 HR-20261017-0001
+// 序号由store按"前缀+日期"计数，width为序号最小位数，<=0时使用默认值4
+func NewSequentialGenerator(store Store, width int) OrderNumGenerator {
+	if width <= 0 {
+		width = defaultWidth
+	}
+	return &sequentialGenerator{
+		store: store,
+		width: width,
+		now:   time.Now,
+	}
+}
+
+func (g *sequentialGenerator) Generate(tpl *models.TicketTemplate) (string, error) {
+	date := g.now().Format(dateLayout)
+	seq, err := g.store.Next(withPrefix(tpl, date))
+	if err != nil {
+		return "", err
+	}
+	return withPrefix(tpl, fmt.Sprintf("%s-%0*d", date, g.width, seq)), nil
+}
@@ -0,0 +1,86 @@
+package ordernum
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func TestSequentialGenerator_Generate(t *testing.T) {
+	day1 := time.Date(2026, 10, 17, 9, 0, 0, 0, time.Local)
+	day2 := time.Date(2026, 10, 18, 9, 0, 0, 0, time.Local)
+	g := NewSequentialGenerator(NewMemoryStore(), 0).(*sequentialGenerator)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		tpl  *models.TicketTemplate
+		want string
+	}{
+		{
+			name: "first of the day",
+			now:  day1,
+			tpl:  &models.TicketTemplate{OrderNumPrefix: "HR"},
+			want: "HR-20261017-0001",
+		},
+		{
+			name: "second of the day",
+			now:  day1,
+			tpl:  &models.TicketTemplate{OrderNumPrefix: "HR"},
+			want: "HR-20261017-0002",
+		},
+		{
+			name: "counter per prefix",
+			now:  day1,
+			tpl:  &models.TicketTemplate{OrderNumPrefix: "IT"},
+			want: "IT-20261017-0001",
+		},
+		{
+			name: "counter per day",
+			now:  day2,
+			tpl:  &models.TicketTemplate{OrderNumPrefix: "HR"},
+			want: "HR-20261018-0001",
+		},
+		{
+			name: "no prefix",
+			now:  day2,
+			tpl:  &models.TicketTemplate{},
+			want: "20261018-0001",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g.now = func() time.Time { return tt.now }
+			got, err := g.Generate(tt.tpl)
+			if err != nil {
+				t.Errorf("Generate() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Generate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSequentialGenerator_Width(t *testing.T) {
+	g := NewSequentialGenerator(NewMemoryStore(), 6).(*sequentialGenerator)
+	g.now = func() time.Time { return time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local) }
+	got, err := g.Generate(&models.TicketTemplate{OrderNumPrefix: "HR"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if got != "HR-20261017-000001" {
+		t.Errorf("Generate() = %v, want HR-20261017-000001", got)
+	}
+}
+
+func TestSequentialGenerator_StoreError(t *testing.T) {
+	errStore := errors.New("store unavailable")
+	g := NewSequentialGenerator(&failStore{err: errStore}, 4)
+	if _, err := g.Generate(&models.TicketTemplate{}); !errors.Is(err, errStore) {
+		t.Errorf("Generate() error = %v, wantErr %v", err, errStore)
+	}
+}
@@ -0,0 +1,70 @@
+package ordernum
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/victorwong171/punched-tape/models"
+)
+
+const (
+	nodeBits     = 10
+	sequenceBits = 12
+	maxNodeID    = -1 ^ (-1 << nodeBits)
+	maxSequence  = -1 ^ (-1 << sequenceBits)
+)
+
+// snowflakeEpoch 2024-01-01 00:00:00 UTC
+var snowflakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+type snowflakeGenerator struct {
+	mu       sync.Mutex
+	node     int64
+	lastTime int64
+	sequence int64
+	now      func() time.Time
+}
+
+// NewSnowflakeGenerator 创建雪花算法工单号生成器，node取值范围[0, 1023]
+// 组成为 41位毫秒时间戳 + 10位节点号 + 12位序号
+func NewSnowflakeGenerator(node int64) (OrderNumGenerator, error) {
+	if node < 0 || node > maxNodeID {
+		return nil, ErrBadNodeID
+	}
+	return &snowflakeGenerator{
+		node: node,
+		now:  time.Now,
+	}, nil
+}
+
+func (g *snowflakeGenerator) Generate(tpl *models.TicketTemplate) (string, error) {
+	id, err := g.nextID()
+	if err != nil {
+		return "", err
+	}
+	return withPrefix(tpl, strconv.FormatInt(id, 10)), nil
+}
+
+func (g *snowflakeGenerator) nextID() (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ts := g.now().UnixMilli() - snowflakeEpoch
+	if ts < g.lastTime {
+		return 0, ErrClockMovedBack
+	}
+	if ts == g.lastTime {
+		g.sequence = (g.sequence + 1) & maxSequence
+		if g.sequence == 0 {
+			// 当前毫秒序号耗尽，等待下一毫秒
+			for ts <= g.lastTime {
+				ts = g.now().UnixMilli() - snowflakeEpoch
+			}
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.lastTime = ts
+	return ts<<(nodeBits+sequenceBits) | g.node<<sequenceBits | g.sequence, nil
+}
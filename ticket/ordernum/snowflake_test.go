@@ -0,0 +1,78 @@
+package ordernum
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func TestNewSnowflakeGenerator(t *testing.T) {
+	tests := []struct {
+		name    string
+		node    int64
+		wantErr error
+	}{
+		{name: "min node", node: 0},
+		{name: "max node", node: maxNodeID},
+		{name: "negative node", node: -1, wantErr: ErrBadNodeID},
+		{name: "node overflow", node: maxNodeID + 1, wantErr: ErrBadNodeID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSnowflakeGenerator(tt.node); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewSnowflakeGenerator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSnowflakeGenerator_Generate(t *testing.T) {
+	g, _ := NewSnowflakeGenerator(7)
+	tpl := &models.TicketTemplate{OrderNumPrefix: "HR"}
+
+	seen := make(map[string]struct{})
+	var last int64
+	for i := 0; i < 10000; i++ {
+		got, err := g.Generate(tpl)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if !strings.HasPrefix(got, "HR-") {
+			t.Fatalf("Generate() = %v, want prefix HR-", got)
+		}
+		if _, ok := seen[got]; ok {
+			t.Fatalf("Generate() duplicated %v", got)
+		}
+		seen[got] = struct{}{}
+
+		id, err := strconv.ParseInt(strings.TrimPrefix(got, "HR-"), 10, 64)
+		if err != nil {
+			t.Fatalf("Generate() = %v, not a number", got)
+		}
+		if id <= last {
+			t.Fatalf("Generate() = %v, not increasing after %v", id, last)
+		}
+		if node := (id >> sequenceBits) & maxNodeID; node != 7 {
+			t.Fatalf("Generate() node = %v, want 7", node)
+		}
+		last = id
+	}
+}
+
+func TestSnowflakeGenerator_ClockMovedBack(t *testing.T) {
+	g, _ := NewSnowflakeGenerator(1)
+	sg := g.(*snowflakeGenerator)
+	now := time.Now()
+	sg.now = func() time.Time { return now }
+	if _, err := g.Generate(&models.TicketTemplate{}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	sg.now = func() time.Time { return now.Add(-time.Second) }
+	if _, err := g.Generate(&models.TicketTemplate{}); !errors.Is(err, ErrClockMovedBack) {
+		t.Errorf("Generate() error = %v, wantErr %v", err, ErrClockMovedBack)
+	}
+}
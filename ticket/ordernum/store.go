@@ -0,0 +1,46 @@
+package ordernum
+
+import (
+	"sync"
+
+	"github.com/victorwong171/go-utils/desc/set"
+)
+
+// Store 工单号存储，提供序号计数与唯一性校验
+type Store interface {
+	// Next 返回key对应的下一个序号，从1开始
+	Next(key string) (int64, error)
+	// Reserve 占用工单号，已被占用时返回ErrDuplicateOrderNum
+	Reserve(orderNum string) error
+}
+
+type memoryStore struct {
+	mu       sync.Mutex
+	counter  map[string]int64
+	reserved set.Set[string]
+}
+
+// NewMemoryStore 创建基于内存的Store，适用于单进程与测试场景
+func NewMemoryStore() Store {
+	return &memoryStore{
+		counter:  make(map[string]int64),
+		reserved: set.InitSet[string](0),
+	}
+}
+
+func (s *memoryStore) Next(key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counter[key]++
+	return s.counter[key], nil
+}
+
+func (s *memoryStore) Reserve(orderNum string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reserved.HasKey(orderNum) {
+		return ErrDuplicateOrderNum
+	}
+	s.reserved.Set(orderNum)
+	return nil
+}
@@ -0,0 +1,68 @@
+package ordernum
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestMemoryStore_Next(t *testing.T) {
+	s := NewMemoryStore()
+	tests := []struct {
+		name string
+		key  string
+		want int64
+	}{
+		{name: "first of a", key: "a", want: 1},
+		{name: "second of a", key: "a", want: 2},
+		{name: "first of b", key: "b", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Next(tt.key)
+			if err != nil {
+				t.Errorf("Next() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStore_NextConcurrent(t *testing.T) {
+	s := NewMemoryStore()
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = s.Next("key")
+		}()
+	}
+	wg.Wait()
+	if got, _ := s.Next("key"); got != 101 {
+		t.Errorf("Next() = %v, want 101", got)
+	}
+}
+
+func TestMemoryStore_Reserve(t *testing.T) {
+	s := NewMemoryStore()
+	tests := []struct {
+		name     string
+		orderNum string
+		wantErr  error
+	}{
+		{name: "first reserve", orderNum: "HR-1", wantErr: nil},
+		{name: "duplicate", orderNum: "HR-1", wantErr: ErrDuplicateOrderNum},
+		{name: "another", orderNum: "HR-2", wantErr: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Reserve(tt.orderNum); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Reserve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
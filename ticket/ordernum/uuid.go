@@ -0,0 +1,21 @@
+package ordernum
+
+import (
+	"github.com/google/uuid"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+type uuidV7Generator struct{}
+
+// NewUUIDv7Generator 创建基于UUIDv7的工单号生成器，生成结果按时间有序
+func NewUUIDv7Generator() OrderNumGenerator {
+	return &uuidV7Generator{}
+}
+
+func (g *uuidV7Generator) Generate(tpl *models.TicketTemplate) (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+	return withPrefix(tpl, id.String()), nil
+}
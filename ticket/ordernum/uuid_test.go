@@ -0,0 +1,49 @@
+package ordernum
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func TestUUIDv7Generator_Generate(t *testing.T) {
+	tests := []struct {
+		name   string
+		tpl    *models.TicketTemplate
+		prefix string
+	}{
+		{
+			name:   "with prefix",
+			tpl:    &models.TicketTemplate{OrderNumPrefix: "HR"},
+			prefix: "HR-",
+		},
+		{
+			name:   "no prefix",
+			tpl:    &models.TicketTemplate{},
+			prefix: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewUUIDv7Generator().Generate(tt.tpl)
+			if err != nil {
+				t.Errorf("Generate() error = %v", err)
+				return
+			}
+			if !strings.HasPrefix(got, tt.prefix) {
+				t.Errorf("Generate() = %v, want prefix %v", got, tt.prefix)
+				return
+			}
+			id, err := uuid.Parse(strings.TrimPrefix(got, tt.prefix))
+			if err != nil {
+				t.Errorf("Generate() = %v, not a uuid: %v", got, err)
+				return
+			}
+			if id.Version() != 7 {
+				t.Errorf("Generate() version = %v, want 7", id.Version())
+			}
+		})
+	}
+}
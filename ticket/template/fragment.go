@@ -0,0 +1,137 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/victorwong171/go-utils/desc/set"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+var (
+	ErrFragmentNotFound    = errors.New("fragment not found")
+	ErrBadFragment         = errors.New("bad fragment")
+	ErrBadInclude          = errors.New("bad include")
+	ErrIncludesNotExpanded = errors.New("includes are not expanded")
+)
+
+// FragmentSeparator 展开后片段前缀与片段内步骤名之间的分隔符
+const FragmentSeparator = "."
+
+// Expand 展开模板引用的片段，返回新模板，不修改传入的模板与片段
+// 片段内步骤重命名为 Prefix.步骤名，出口连接到Include指定的步骤；模板中指向Prefix的引用改为指向片段入口
+// 展开结果不包含Includes，可直接交给Validator校验
+func Expand(tpl *models.TicketTemplate, lookup func(name string) *models.Fragment) (*models.TicketTemplate, error) {
+	if tpl == nil {
+		return nil, ErrBadInclude
+	}
+	expanded := *tpl
+	expanded.Includes = nil
+	expanded.Config = make([]*models.StepConfig, 0, len(tpl.Config))
+	for _, c := range tpl.Config {
+		expanded.Config = append(expanded.Config, c.Clone())
+	}
+	if len(tpl.Includes) == 0 {
+		return &expanded, nil
+	}
+
+	steps := set.InitSet[string](len(tpl.Config))
+	for _, c := range tpl.Config {
+		steps.Set(c.GetStep())
+	}
+	// entries 引用前缀到片段入口的映射
+	entries := make(map[string]string, len(tpl.Includes))
+	var included []*models.StepConfig
+	for _, inc := range tpl.Includes {
+		prefix := inc.GetPrefix()
+		if len(prefix) == 0 || steps.HasKey(prefix) || len(entries[prefix]) > 0 {
+			return nil, fmt.Errorf("%w: prefix %q", ErrBadInclude, prefix)
+		}
+		fragment := lookup(inc.Fragment)
+		if fragment == nil {
+			return nil, fmt.Errorf("%w: %s", ErrFragmentNotFound, inc.Fragment)
+		}
+		configs, err := expandFragment(fragment, inc, prefix)
+		if err != nil {
+			return nil, err
+		}
+		entries[prefix] = prefix + FragmentSeparator + fragment.Entry
+		included = append(included, configs...)
+	}
+	expanded.Config = append(expanded.Config, included...)
+
+	resolve := func(step string) string {
+		if entry, ok := entries[step]; ok {
+			return entry
+		}
+		return step
+	}
+	expanded.StartStep = resolve(expanded.StartStep)
+	for _, c := range expanded.Config {
+		renameSteps(c, resolve)
+	}
+	return &expanded, nil
+}
+
+// expandFragment 复制片段内步骤并按前缀重命名，出口替换为Include连接的步骤
+func expandFragment(fragment *models.Fragment, inc *models.Include, prefix string) ([]*models.StepConfig, error) {
+	internal := set.InitSet[string](len(fragment.Config))
+	for _, c := range fragment.Config {
+		if c == nil || len(c.Step) == 0 || models.IsFragmentExit(c.Step) || internal.HasKey(c.Step) {
+			return nil, fmt.Errorf("%w: %s has bad step", ErrBadFragment, fragment.Name)
+		}
+		internal.Set(c.Step)
+	}
+	if !internal.HasKey(fragment.Entry) {
+		return nil, fmt.Errorf("%w: %s entry %q not found", ErrBadFragment, fragment.Name, fragment.Entry)
+	}
+	for step := range inc.Operators {
+		if !internal.HasKey(step) {
+			return nil, fmt.Errorf("%w: operators of unknown step %s", ErrBadInclude, step)
+		}
+	}
+
+	var err error
+	rename := func(step string) string {
+		switch {
+		case len(step) == 0:
+			return step
+		case internal.HasKey(step):
+			return prefix + FragmentSeparator + step
+		case models.IsFragmentExit(step):
+			if target, ok := inc.Exit(step); ok {
+				return target
+			}
+			err = fmt.Errorf("%w: exit %s of %s is not connected", ErrBadInclude, step, prefix)
+		default:
+			err = fmt.Errorf("%w: %s refers to %s outside the fragment", ErrBadFragment, fragment.Name, step)
+		}
+		return step
+	}
+	configs := make([]*models.StepConfig, 0, len(fragment.Config))
+	for _, c := range fragment.Config {
+		expanded := c.Clone()
+		if operators, ok := inc.Operators[c.Step]; ok {
+			expanded.Operator = append([]string(nil), operators...)
+		}
+		expanded.Step = rename(c.Step)
+		renameSteps(expanded, rename)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, expanded)
+	}
+	return configs, nil
+}
+
+// renameSteps 替换步骤配置中引用的其他步骤名
+func renameSteps(c *models.StepConfig, rename func(string) string) {
+	for _, next := range c.Next {
+		if next != nil {
+			next.Step = rename(next.Step)
+			next.Limit.Step = rename(next.Limit.Step)
+		}
+	}
+	c.Limit.Step = rename(c.Limit.Step)
+	c.Service.ErrorStep = rename(c.Service.ErrorStep)
+}
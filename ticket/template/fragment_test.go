@@ -0,0 +1,171 @@
+package template
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func chainFragment() *models.Fragment {
+	return &models.Fragment{
+		Name:  "chain",
+		Entry: "manager",
+		Config: []*models.StepConfig{
+			{
+				Step:     "manager",
+				Operator: []string{"m1"},
+				Disposal: models.Disposal{SignType: models.AnyoneSign},
+				Next: []*models.NextStep{
+					{Operation: "approve", Step: "director"},
+					{Operation: models.Reject, Step: "$reject"},
+				},
+			},
+			{
+				Step:     "director",
+				Operator: []string{"d1"},
+				Disposal: models.Disposal{SignType: models.AnyoneSign},
+				Next: []*models.NextStep{
+					{Operation: "approve", Step: models.FragmentNext},
+					{Operation: models.Reject, Step: "manager"},
+				},
+			},
+		},
+	}
+}
+
+func fragmentTemplate(includes ...*models.Include) *models.TicketTemplate {
+	return &models.TicketTemplate{
+		Uid:       "expense",
+		StartStep: "apply",
+		EndStep:   []string{"end"},
+		Includes:  includes,
+		Config: []*models.StepConfig{
+			{
+				Step:     "apply",
+				Operator: []string{"applicant"},
+				Disposal: models.Disposal{SignType: models.AnyoneSign},
+				Next:     []*models.NextStep{{Operation: "submit", Step: "approval"}},
+			},
+			{Step: "end", Disposal: models.Disposal{SignType: models.AnyoneSign}},
+		},
+	}
+}
+
+func TestExpand(t *testing.T) {
+	lookup := func(name string) *models.Fragment {
+		if name == "chain" {
+			return chainFragment()
+		}
+		return nil
+	}
+	tpl := fragmentTemplate(&models.Include{
+		Fragment:  "chain",
+		Prefix:    "approval",
+		Operators: map[string][]string{"director": {"cfo"}},
+		Next:      "end",
+		Exits:     map[string]string{"$reject": "apply"},
+	})
+	got, err := Expand(tpl, lookup)
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if err = NewValidator().Validate(*got); err != nil {
+		t.Errorf("Validate() expanded template error = %v", err)
+	}
+	if got.Includes != nil || len(tpl.Includes) != 1 {
+		t.Errorf("Expand() includes = %v, original = %v", got.Includes, tpl.Includes)
+	}
+	stepMap := got.StepConfigMap()
+	if diff := cmp.Diff(stepMap["apply"].Next[0].Step, "approval.manager"); len(diff) > 0 {
+		t.Errorf("apply next diff = %v", diff)
+	}
+	wantNext := []*models.NextStep{
+		{Operation: "approve", Step: "end"},
+		{Operation: models.Reject, Step: "approval.manager"},
+	}
+	if diff := cmp.Diff(stepMap["approval.director"].Next, wantNext); len(diff) > 0 {
+		t.Errorf("director next diff = %v", diff)
+	}
+	if diff := cmp.Diff(stepMap["approval.director"].Operator, []string{"cfo"}); len(diff) > 0 {
+		t.Errorf("director operator diff = %v", diff)
+	}
+	if got := stepMap["approval.manager"].Next[1].Step; got != "apply" {
+		t.Errorf("manager reject = %v, want apply", got)
+	}
+	if tpl.Config[0].Next[0].Step != "approval" || chainFragment().Config[1].Operator[0] != "d1" {
+		t.Errorf("Expand() modified its inputs")
+	}
+
+	if err = NewValidator().Validate(*tpl); !errors.Is(err, ErrIncludesNotExpanded) {
+		t.Errorf("Validate() unexpanded error = %v, wantErr %v", err, ErrIncludesNotExpanded)
+	}
+}
+
+func TestExpand_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		include  *models.Include
+		fragment *models.Fragment
+		wantErr  error
+	}{
+		{
+			name:     "fragment not found",
+			include:  &models.Include{Fragment: "missing", Next: "end"},
+			fragment: chainFragment(),
+			wantErr:  ErrFragmentNotFound,
+		},
+		{
+			name:     "exit not connected",
+			include:  &models.Include{Fragment: "chain", Next: "end"},
+			fragment: chainFragment(),
+			wantErr:  ErrBadInclude,
+		},
+		{
+			name:     "prefix collides with step",
+			include:  &models.Include{Fragment: "chain", Prefix: "apply", Next: "end", Exits: map[string]string{"$reject": "apply"}},
+			fragment: chainFragment(),
+			wantErr:  ErrBadInclude,
+		},
+		{
+			name:     "operators of unknown step",
+			include:  &models.Include{Fragment: "chain", Operators: map[string][]string{"ceo": {"u1"}}},
+			fragment: chainFragment(),
+			wantErr:  ErrBadInclude,
+		},
+		{
+			name:    "bad entry",
+			include: &models.Include{Fragment: "chain", Next: "end"},
+			fragment: func() *models.Fragment {
+				f := chainFragment()
+				f.Entry = "ceo"
+				return f
+			}(),
+			wantErr: ErrBadFragment,
+		},
+		{
+			name:    "refers outside",
+			include: &models.Include{Fragment: "chain", Next: "end", Exits: map[string]string{"$reject": "apply"}},
+			fragment: func() *models.Fragment {
+				f := chainFragment()
+				f.Config[0].Next[1].Step = "apply"
+				return f
+			}(),
+			wantErr: ErrBadFragment,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookup := func(name string) *models.Fragment {
+				if name == tt.fragment.Name {
+					return tt.fragment
+				}
+				return nil
+			}
+			if _, err := Expand(fragmentTemplate(tt.include), lookup); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
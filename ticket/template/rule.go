@@ -0,0 +1,188 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/victorwong171/go-utils/desc/set"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+var (
+	ErrTooManySteps = errors.New("too many steps")
+	ErrBadStepName  = errors.New("step name does not match convention")
+	ErrCycle        = errors.New("template contains a cycle")
+	ErrNoRejectPath = errors.New("step has no reject path")
+)
+
+// Rule 组织自定义的模板校验规则，在内置校验通过后执行，Check返回的错误会附带规则名
+type Rule interface {
+	Name() string
+	Check(tpl *models.TicketTemplate) error
+}
+
+type rule struct {
+	name  string
+	check func(tpl *models.TicketTemplate) error
+}
+
+func (r *rule) Name() string {
+	return r.name
+}
+
+func (r *rule) Check(tpl *models.TicketTemplate) error {
+	return r.check(tpl)
+}
+
+// NewRule 使用函数创建规则
+func NewRule(name string, check func(tpl *models.TicketTemplate) error) Rule {
+	return &rule{name: name, check: check}
+}
+
+// Option 校验器选项
+type Option func(*validator)
+
+// WithSignTypes 限制允许的会签类型，默认为models.DisposalSignType；审批引擎不支持的类型被忽略
+func WithSignTypes(signTypes ...string) Option {
+	return func(v *validator) {
+		v.signTypeSet = set.InitSet[string](len(signTypes))
+		for _, signType := range signTypes {
+			if models.DisposalSignType.HasKey(signType) {
+				v.signTypeSet.Set(signType)
+			}
+		}
+	}
+}
+
+// AllowNoOperator 允许人工审批步骤不预设操作人，适用于发起工单时才指定操作人的模板
+func AllowNoOperator() Option {
+	return func(v *validator) {
+		v.allowNoOperator = true
+	}
+}
+
+// WithRules 注册自定义规则
+func WithRules(rules ...Rule) Option {
+	return func(v *validator) {
+		v.rules = append(v.rules, rules...)
+	}
+}
+
+// WithMaxSteps 限制模板的步骤数量
+func WithMaxSteps(max int) Option {
+	return WithRules(NewRule("max_steps", func(tpl *models.TicketTemplate) error {
+		if len(tpl.Config) > max {
+			return fmt.Errorf("%w: %d > %d", ErrTooManySteps, len(tpl.Config), max)
+		}
+		return nil
+	}))
+}
+
+// WithStepNamePattern 要求步骤名符合命名规范，如 ^[a-z][a-z0-9_]*$
+func WithStepNamePattern(pattern *regexp.Regexp) Option {
+	return WithRules(NewRule("step_name", func(tpl *models.TicketTemplate) error {
+		for _, c := range tpl.Config {
+			if !pattern.MatchString(c.Step) {
+				return fmt.Errorf("%w: %s", ErrBadStepName, c.Step)
+			}
+		}
+		return nil
+	}))
+}
+
+// WithoutCycles 禁止模板中出现环路
+func WithoutCycles() Option {
+	return WithRules(NewRule("no_cycles", func(tpl *models.TicketTemplate) error {
+		if cycle := findCycle(tpl); len(cycle) > 0 {
+			return fmt.Errorf("%w: %v", ErrCycle, cycle)
+		}
+		return nil
+	}))
+}
+
+// WithRejectPath 要求每个非结束的人工审批步骤都配置驳回操作
+func WithRejectPath() Option {
+	return WithRules(NewRule("reject_path", func(tpl *models.TicketTemplate) error {
+		endStepSet := set.Setify(tpl.EndStep...)
+		for _, c := range tpl.Config {
+			if endStepSet.HasKey(c.Step) || c.IsSubProcess() || c.IsService() {
+				continue
+			}
+			if !hasReject(c) {
+				return fmt.Errorf("%w: %s", ErrNoRejectPath, c.Step)
+			}
+		}
+		return nil
+	}))
+}
+
+func hasReject(c *models.StepConfig) bool {
+	for _, next := range c.Next {
+		if next.GetOperation() == models.Reject {
+			return true
+		}
+	}
+	return false
+}
+
+// successors 步骤的全部后继，包括自动步骤的错误步骤
+func successors(c *models.StepConfig) []string {
+	steps := make([]string, 0, len(c.Next)+1)
+	for _, next := range c.Next {
+		steps = append(steps, next.GetStep())
+	}
+	if c.IsService() && len(c.Service.ErrorStep) > 0 {
+		steps = append(steps, c.Service.ErrorStep)
+	}
+	return append(steps, escalations(c)...)
+}
+
+// findCycle 从开始步骤出发查找环路，返回环上的步骤，首尾相同；无环时返回nil
+func findCycle(tpl *models.TicketTemplate) []string {
+	if cycles := findCycles(tpl); len(cycles) > 0 {
+		return cycles[0]
+	}
+	return nil
+}
+
+// findCycles 从开始步骤深度优先遍历，每条回边对应一个环路，环路首尾相同
+func findCycles(tpl *models.TicketTemplate) [][]string {
+	stepMap := tpl.StepConfigMap()
+	const (
+		visiting = 1
+		done     = 2
+	)
+	marks := make(map[string]int, len(stepMap))
+	var (
+		path   []string
+		cycles [][]string
+		visit  func(step string)
+	)
+	visit = func(step string) {
+		switch marks[step] {
+		case done:
+			return
+		case visiting:
+			for i, s := range path {
+				if s == step {
+					cycles = append(cycles, append(append([]string(nil), path[i:]...), step))
+					return
+				}
+			}
+		}
+		c := stepMap[step]
+		if c == nil {
+			return
+		}
+		marks[step] = visiting
+		path = append(path, step)
+		for _, next := range successors(c) {
+			visit(next)
+		}
+		path = path[:len(path)-1]
+		marks[step] = done
+	}
+	visit(tpl.StartStep)
+	return cycles
+}
@@ -0,0 +1,81 @@
+package template
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func ruleTemplate() models.TicketTemplate {
+	return models.TicketTemplate{
+		StartStep: "review",
+		EndStep:   []string{"end"},
+		Config: []*models.StepConfig{
+			{
+				Step:     "review",
+				Operator: []string{"u1"},
+				Disposal: models.Disposal{SignType: models.AnyoneSign},
+				Next: []*models.NextStep{
+					{Operation: "approve", Step: "end"},
+					{Operation: models.Reject, Step: "revise"},
+				},
+			},
+			{
+				Step:     "revise",
+				Operator: []string{"applicant"},
+				Disposal: models.Disposal{SignType: models.AnyoneSign},
+				Next:     []*models.NextStep{{Operation: "submit", Step: "review"}},
+			},
+			{Step: "end", Disposal: models.Disposal{SignType: models.AnyoneSign}},
+		},
+	}
+}
+
+func TestNewValidator_Options(t *testing.T) {
+	custom := errors.New("custom")
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr error
+	}{
+		{name: "default"},
+		{name: "max steps", opts: []Option{WithMaxSteps(2)}, wantErr: ErrTooManySteps},
+		{name: "max steps ok", opts: []Option{WithMaxSteps(3)}},
+		{name: "step name", opts: []Option{WithStepNamePattern(regexp.MustCompile(`^[a-z]{4}$`))}, wantErr: ErrBadStepName},
+		{name: "no cycles", opts: []Option{WithoutCycles()}, wantErr: ErrCycle},
+		{name: "reject path", opts: []Option{WithRejectPath()}, wantErr: ErrNoRejectPath},
+		{name: "sign types", opts: []Option{WithSignTypes(models.JointlySign)}, wantErr: ErrBadSignType},
+		{name: "unsupported sign type ignored", opts: []Option{WithSignTypes(models.AnyoneSign, "custom")}},
+		{
+			name: "custom rule",
+			opts: []Option{WithRules(NewRule("owner", func(tpl *models.TicketTemplate) error {
+				if len(tpl.Name) == 0 {
+					return custom
+				}
+				return nil
+			}))},
+			wantErr: custom,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewValidator(tt.opts...).Validate(ruleTemplate()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_findCycle(t *testing.T) {
+	tpl := ruleTemplate()
+	if diff := cmp.Diff(findCycle(&tpl), []string{"review", "revise", "review"}); len(diff) > 0 {
+		t.Errorf("findCycle() diff = %v", diff)
+	}
+	tpl.Config[1].Next[0].Step = "end"
+	if got := findCycle(&tpl); got != nil {
+		t.Errorf("findCycle() = %v, want nil", got)
+	}
+}
@@ -3,6 +3,8 @@ package template
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/victorwong171/go-utils/desc/set"
 	"github.com/victorwong171/punched-tape/models"
@@ -14,28 +16,52 @@ type Validator interface {
 }
 
 type validator struct {
-	signTypeSet set.Set[string]
+	signTypeSet     set.Set[string]
+	rules           []Rule
+	allowNoOperator bool
 }
 
-func NewValidator() Validator {
-	return &validator{
+// NewValidator 创建模板校验器，默认只执行内置校验，可通过Option追加策略与自定义规则
+func NewValidator(opts ...Option) Validator {
+	v := &validator{
 		signTypeSet: models.DisposalSignType,
 	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
 }
 
 var (
-	ErrStartStepEmpty    = errors.New("start step is empty")
-	ErrConfigEmpty       = errors.New("config is empty")
-	ErrBadStepConfig     = errors.New("bad step config")
-	ErrStepEmpty         = errors.New("step is empty")
-	ErrBadSignType       = errors.New("bad sign type")
-	ErrNextStepEmpty     = errors.New("next step is empty in non-end step")
-	ErrBadNextStep       = errors.New("bad next step")
-	ErrEndStepHasNext    = errors.New("end step has next steps")
-	ErrBadJointSignRate  = errors.New("bad joint sign rate")
-	ErrDuplicateStep     = errors.New("duplicate step definition")
-	ErrStartStepNotFound = errors.New("start step not found in configurations")
-	ErrUnreachableSteps  = errors.New("some steps are unreachable")
+	ErrStartStepEmpty      = errors.New("start step is empty")
+	ErrConfigEmpty         = errors.New("config is empty")
+	ErrBadStepConfig       = errors.New("bad step config")
+	ErrStepEmpty           = errors.New("step is empty")
+	ErrBadSignType         = errors.New("bad sign type")
+	ErrNextStepEmpty       = errors.New("next step is empty in non-end step")
+	ErrBadNextStep         = errors.New("bad next step")
+	ErrEndStepHasNext      = errors.New("end step has next steps")
+	ErrBadJointSignRate    = errors.New("bad joint sign rate")
+	ErrBadJointSignCount   = errors.New("bad joint sign count")
+	ErrBadWeight           = errors.New("bad weight")
+	ErrBadWeightThreshold  = errors.New("weight threshold is not achievable")
+	ErrDuplicateStep       = errors.New("duplicate step definition")
+	ErrStartStepNotFound   = errors.New("start step not found in configurations")
+	ErrUnreachableSteps    = errors.New("some steps are unreachable")
+	ErrBadFormField        = errors.New("bad form field")
+	ErrBadCCTrigger        = errors.New("bad cc trigger")
+	ErrBadResolution       = errors.New("bad resolution")
+	ErrBadStepKind         = errors.New("bad step kind")
+	ErrBadSubProcess       = errors.New("bad sub process")
+	ErrRecursiveSubProcess = errors.New("recursive sub process reference")
+	ErrBadServiceTask      = errors.New("bad service task")
+	ErrBadEndStatus        = errors.New("bad end status")
+	ErrBadState            = errors.New("bad state")
+	ErrBadReopenPolicy     = errors.New("bad reopen policy")
+	ErrBadOperator         = errors.New("bad operator")
+	ErrDuplicateOperator   = errors.New("duplicate operator")
+	ErrNoOperator          = errors.New("no preset operator in non-end step")
+	ErrBadLoopLimit        = errors.New("bad loop limit")
 )
 
 func (v *validator) Validate(tpl models.TicketTemplate) error {
@@ -45,6 +71,9 @@ func (v *validator) Validate(tpl models.TicketTemplate) error {
 	if len(tpl.Config) == 0 {
 		return ErrConfigEmpty
 	}
+	if len(tpl.Includes) > 0 {
+		return ErrIncludesNotExpanded
+	}
 
 	stepMap := make(map[string]*models.StepConfig, len(tpl.Config))
 	endStepSet := set.Setify(tpl.EndStep...)
@@ -55,13 +84,52 @@ func (v *validator) Validate(tpl models.TicketTemplate) error {
 		if len(c.Next) == 0 && !endStepSet.HasKey(c.Step) {
 			return ErrNextStepEmpty
 		}
-		if !v.signTypeSet.HasKey(c.Disposal.SignType) {
-			return ErrBadSignType
+		if len(c.Kind) > 0 && !models.StepKind.HasKey(c.Kind) {
+			return ErrBadStepKind
+		}
+		if c.IsSubProcess() {
+			if err := validateSubProcessStep(tpl.Uid, c); err != nil {
+				return err
+			}
+		} else if c.IsService() {
+			if len(c.Service.Handler) == 0 || c.Service.Retries < 0 || c.Service.Backoff < 0 {
+				return fmt.Errorf("%w: %s", ErrBadServiceTask, c.Step)
+			}
+		} else {
+			if !v.signTypeSet.HasKey(c.Disposal.SignType) {
+				return ErrBadSignType
+			}
+			// 非结束的人工审批步骤没有预设操作人时工单将无法推进
+			if !v.allowNoOperator && len(c.Operator) == 0 && !endStepSet.HasKey(c.Step) {
+				return fmt.Errorf("%w: %s", ErrNoOperator, c.Step)
+			}
+		}
+		if err := validateOperators(c); err != nil {
+			return err
 		}
 		if c.Disposal.SignType == models.JointlySign {
 			if c.Disposal.JointSignRate < 0 || c.Disposal.JointSignRate > 1 {
 				return ErrBadJointSignRate
 			}
+			// 预设操作人为空时由发起时指定，无法静态校验人数
+			if c.Disposal.JointSignCount < 0 || (len(c.Operator) > 0 && c.Disposal.JointSignCount > len(c.Operator)) {
+				return ErrBadJointSignCount
+			}
+			// 比例为0时无人同意即达到阈值，会签失去意义
+			if len(c.Operator) > 1 && c.Disposal.JointSignCount == 0 && c.Disposal.JointSignRate == 0 {
+				return fmt.Errorf("%w: rate 0 is reached without approval in %s", ErrBadJointSignRate, c.Step)
+			}
+		}
+		if len(c.Disposal.Resolution) > 0 && !models.Resolution.HasKey(c.Disposal.Resolution) {
+			return ErrBadResolution
+		}
+		if c.Disposal.SignType == models.WeightedSign {
+			if err := validateWeights(c); err != nil {
+				return err
+			}
+		}
+		if len(c.CC.Trigger) > 0 && !models.CCTrigger.HasKey(c.CC.Trigger) {
+			return ErrBadCCTrigger
 		}
 		if len(c.Next) > 0 && endStepSet.HasKey(c.Step) {
 			return ErrEndStepHasNext
@@ -74,11 +142,37 @@ func (v *validator) Validate(tpl models.TicketTemplate) error {
 	if _, ok := stepMap[tpl.StartStep]; !ok {
 		return ErrStartStepNotFound
 	}
+	if err := validateEndStatus(&tpl, endStepSet); err != nil {
+		return err
+	}
+	if err := validateState(&tpl); err != nil {
+		return err
+	}
+	if err := validateReopen(&tpl); err != nil {
+		return err
+	}
+	if err := validateLoopLimits(stepMap); err != nil {
+		return err
+	}
+	for _, c := range tpl.Config {
+		if errorStep := c.Service.ErrorStep; c.IsService() && len(errorStep) > 0 && stepMap[errorStep] == nil {
+			return fmt.Errorf("%w: error step %s not found", ErrBadServiceTask, errorStep)
+		}
+	}
+	if err := validateForm(tpl.Form, tpl.Config); err != nil {
+		return err
+	}
 
 	// 验证是否存在不可达的步骤
 	if err := validateReachability(tpl.StartStep, stepMap, endStepSet); err != nil {
 		return err
 	}
+	// 内置校验通过后按注册顺序执行规则
+	for _, rule := range v.rules {
+		if err := rule.Check(&tpl); err != nil {
+			return fmt.Errorf("rule %s: %w", rule.Name(), err)
+		}
+	}
 	return nil
 }
 
@@ -108,6 +202,10 @@ func validateReachability(start string, stepMap map[string]*models.StepConfig, e
 			}
 			queue = append(queue, next.Step)
 		}
+		if config.IsService() && len(config.Service.ErrorStep) > 0 {
+			queue = append(queue, config.Service.ErrorStep)
+		}
+		queue = append(queue, escalations(config)...)
 	}
 
 	if visited.Len() != len(stepMap) {
@@ -115,3 +213,236 @@ func validateReachability(start string, stepMap map[string]*models.StepConfig, e
 	}
 	return nil
 }
+
+func validateForm(form []*models.FormField, config []*models.StepConfig) error {
+	fieldSet := set.InitSet[string](len(form))
+	for _, f := range form {
+		if f == nil || len(f.Name) == 0 {
+			return ErrBadFormField
+		}
+		if fieldSet.HasKey(f.Name) {
+			return fmt.Errorf("%w: duplicate field %s", ErrBadFormField, f.Name)
+		}
+		fieldSet.Set(f.Name)
+		if !models.FormFieldType.HasKey(f.Type) {
+			return fmt.Errorf("%w: bad type of %s", ErrBadFormField, f.Name)
+		}
+		if f.Type == models.FieldEnum && len(f.Options) == 0 {
+			return fmt.Errorf("%w: enum %s has no options", ErrBadFormField, f.Name)
+		}
+		if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+			return fmt.Errorf("%w: min greater than max of %s", ErrBadFormField, f.Name)
+		}
+		if len(f.Pattern) > 0 {
+			if _, err := regexp.Compile(f.Pattern); err != nil {
+				return fmt.Errorf("%w: bad pattern of %s", ErrBadFormField, f.Name)
+			}
+		}
+	}
+
+	// 步骤的可编辑/隐藏字段必须在表单中定义
+	for _, c := range config {
+		for _, name := range c.Editable {
+			if !fieldSet.HasKey(name) {
+				return fmt.Errorf("%w: step %s edits undefined field %s", ErrBadFormField, c.Step, name)
+			}
+		}
+		for _, name := range c.Hidden {
+			if !fieldSet.HasKey(name) {
+				return fmt.Errorf("%w: step %s hides undefined field %s", ErrBadFormField, c.Step, name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateWeights 校验加权投票的权重配置，阈值需不超过可获得的最大权重
+func validateWeights(c *models.StepConfig) error {
+	for _, w := range c.Weights {
+		if w < 0 {
+			return ErrBadWeight
+		}
+	}
+	var total int
+	if len(c.Operator) > 0 {
+		for _, o := range c.Operator {
+			total += c.WeightOf(o)
+		}
+	} else {
+		// 预设操作人为空时按已配置权重估算
+		for _, w := range c.Weights {
+			total += w
+		}
+	}
+	if c.Disposal.WeightThreshold <= 0 || (total > 0 && c.Disposal.WeightThreshold > total) {
+		return ErrBadWeightThreshold
+	}
+	return nil
+}
+
+// validateSubProcessStep 子流程步骤需引用其他模板，且只能按子工单结束状态流转
+// 子模板可自定义结束状态，此处只排除运行中状态，具体状态由ValidateSubProcess按子模板校验
+func validateSubProcessStep(uid string, c *models.StepConfig) error {
+	if len(c.Template) == 0 {
+		return fmt.Errorf("%w: %s has no template", ErrBadSubProcess, c.Step)
+	}
+	if c.Template == uid {
+		return fmt.Errorf("%w: %s", ErrRecursiveSubProcess, uid)
+	}
+	for _, n := range c.Next {
+		if n == nil || len(n.Operation) == 0 || n.Operation == models.Running {
+			return fmt.Errorf("%w: %s next operation must be a terminal status", ErrBadSubProcess, c.Step)
+		}
+	}
+	return nil
+}
+
+// ValidateSubProcess 沿子流程步骤引用的模板递归检查，引用的模板必须存在且不能形成环
+// 子流程步骤Next的Operation必须是子模板可结束的状态，包括内置状态与子模板自定义状态
+func ValidateSubProcess(tpl *models.TicketTemplate, lookup func(uid string) *models.TicketTemplate) error {
+	if tpl == nil {
+		return ErrBadSubProcess
+	}
+	return walkSubProcess(tpl, lookup, []string{tpl.Uid})
+}
+
+func walkSubProcess(tpl *models.TicketTemplate, lookup func(uid string) *models.TicketTemplate, path []string) error {
+	for _, c := range tpl.Config {
+		if !c.IsSubProcess() {
+			continue
+		}
+		for _, uid := range path {
+			if uid == c.Template {
+				return fmt.Errorf("%w: %s -> %s", ErrRecursiveSubProcess, strings.Join(path, " -> "), c.Template)
+			}
+		}
+		child := lookup(c.Template)
+		if child == nil {
+			return fmt.Errorf("%w: template %s not found", ErrBadSubProcess, c.Template)
+		}
+		statuses := child.TicketStatus()
+		for _, n := range c.Next {
+			if n.GetOperation() == models.Running || !statuses.HasKey(n.GetOperation()) {
+				return fmt.Errorf("%w: %s next operation %q is not a status of %s", ErrBadSubProcess, c.Step, n.GetOperation(), c.Template)
+			}
+		}
+		if err := walkSubProcess(child, lookup, append(path[:len(path):len(path)], c.Template)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateEndStatus 结束状态只能声明在结束步骤上，且必须是内置或模板自定义的非运行状态
+func validateEndStatus(tpl *models.TicketTemplate, endStepSet set.Set[string]) error {
+	for _, status := range tpl.Statuses {
+		if len(status) == 0 || status == models.Running {
+			return fmt.Errorf("%w: custom status %q", ErrBadEndStatus, status)
+		}
+	}
+	statuses := tpl.TicketStatus()
+	for _, c := range tpl.Config {
+		if len(c.EndStatus) == 0 {
+			continue
+		}
+		if !endStepSet.HasKey(c.Step) {
+			return fmt.Errorf("%w: %s is not an end step", ErrBadEndStatus, c.Step)
+		}
+		if c.EndStatus == models.Running || !statuses.HasKey(c.EndStatus) {
+			return fmt.Errorf("%w: %s of %s", ErrBadEndStatus, c.EndStatus, c.Step)
+		}
+	}
+	return nil
+}
+
+// validateState 模板声明业务状态枚举后，每个步骤的State都必须取自该枚举；未声明时不做限制
+func validateState(tpl *models.TicketTemplate) error {
+	if len(tpl.States) == 0 {
+		return nil
+	}
+	states := set.InitSet[string](len(tpl.States))
+	for _, state := range tpl.States {
+		if len(state) == 0 || states.HasKey(state) {
+			return fmt.Errorf("%w: declared state %q", ErrBadState, state)
+		}
+		states.Set(state)
+	}
+	for _, c := range tpl.Config {
+		if !states.HasKey(c.State) {
+			return fmt.Errorf("%w: %q of %s", ErrBadState, c.State, c.Step)
+		}
+	}
+	return nil
+}
+
+// validateReopen 只有结束状态可以重新打开
+func validateReopen(tpl *models.TicketTemplate) error {
+	if tpl.Reopen.MaxTimes < 0 {
+		return fmt.Errorf("%w: max times %d", ErrBadReopenPolicy, tpl.Reopen.MaxTimes)
+	}
+	statuses := tpl.TicketStatus()
+	for _, status := range tpl.Reopen.Statuses {
+		if status == models.Running || !statuses.HasKey(status) {
+			return fmt.Errorf("%w: status %q", ErrBadReopenPolicy, status)
+		}
+	}
+	return nil
+}
+
+// validateOperators 预设操作人不能为空字符串或重复，重复会使会签人数与比例失真
+func validateOperators(c *models.StepConfig) error {
+	operators := set.InitSet[string](len(c.Operator))
+	for _, o := range c.Operator {
+		if len(o) == 0 {
+			return fmt.Errorf("%w: empty operator in %s", ErrBadOperator, c.Step)
+		}
+		if operators.HasKey(o) {
+			return fmt.Errorf("%w: %s in %s", ErrDuplicateOperator, o, c.Step)
+		}
+		operators.Set(o)
+	}
+	return nil
+}
+
+// validateLoopLimits 环路次数限制不能为负，escalate必须指定存在的步骤
+func validateLoopLimits(stepMap map[string]*models.StepConfig) error {
+	check := func(limit *models.LoopLimit, where string) error {
+		switch {
+		case limit.MaxVisits < 0:
+			return fmt.Errorf("%w: negative max visits in %s", ErrBadLoopLimit, where)
+		case len(limit.OnExceeded) > 0 && !models.LimitBehaviour.HasKey(limit.OnExceeded):
+			return fmt.Errorf("%w: %s in %s", ErrBadLoopLimit, limit.OnExceeded, where)
+		case limit.Escalates() && stepMap[limit.Step] == nil:
+			return fmt.Errorf("%w: escalation %q in %s not found", ErrBadLoopLimit, limit.Step, where)
+		}
+		return nil
+	}
+	for step, c := range stepMap {
+		if err := check(&c.Limit, step); err != nil {
+			return err
+		}
+		for _, next := range c.Next {
+			if next == nil {
+				continue
+			}
+			if err := check(&next.Limit, models.EdgeKey(step, next.Step)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// escalations 步骤及其流转上配置的升级步骤
+func escalations(c *models.StepConfig) []string {
+	var steps []string
+	if c.Limit.Escalates() {
+		steps = append(steps, c.Limit.Step)
+	}
+	for _, next := range c.Next {
+		if next != nil && next.Limit.Escalates() {
+			steps = append(steps, next.Limit.Step)
+		}
+	}
+	return steps
+}
@@ -1,6 +1,7 @@
 package template
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
@@ -164,10 +165,11 @@ func Test_canValidateReachability(t *testing.T) {
 
 func Test_validator_Validate(t *testing.T) {
 	tests := []struct {
-		name        string
-		template    models.TicketTemplate
-		signTypeSet set.Set[string]
-		wantErr     error
+		name            string
+		template        models.TicketTemplate
+		signTypeSet     set.Set[string]
+		allowNoOperator bool
+		wantErr         error
 	}{
 		{
 			name: "StartStep is empty",
@@ -323,7 +325,8 @@ func Test_validator_Validate(t *testing.T) {
 				EndStep:   []string{"end"},
 				Config: []*models.StepConfig{
 					{
-						Step: "start",
+						Step:     "start",
+						Operator: []string{"u1"},
 						Next: []*models.NextStep{
 							{
 								Step: "next",
@@ -331,7 +334,8 @@ func Test_validator_Validate(t *testing.T) {
 						},
 					},
 					{
-						Step: "next",
+						Step:     "next",
+						Operator: []string{"u2"},
 						Next: []*models.NextStep{
 							{
 								Step: "end",
@@ -347,6 +351,65 @@ func Test_validator_Validate(t *testing.T) {
 			signTypeSet: set.Setify(""),
 			wantErr:     nil,
 		},
+		{
+			name: "Missing operator",
+			template: models.TicketTemplate{
+				StartStep: "start",
+				EndStep:   []string{"end"},
+				Config: []*models.StepConfig{
+					{Step: "start", Next: []*models.NextStep{{Step: "end"}}},
+					{Step: "end", Next: nil},
+				},
+			},
+			signTypeSet: set.Setify(""),
+			wantErr:     ErrNoOperator,
+		},
+		{
+			name: "Missing operator allowed",
+			template: models.TicketTemplate{
+				StartStep: "start",
+				EndStep:   []string{"end"},
+				Config: []*models.StepConfig{
+					{Step: "start", Next: []*models.NextStep{{Step: "end"}}},
+					{Step: "end", Next: nil},
+				},
+			},
+			signTypeSet:     set.Setify(""),
+			allowNoOperator: true,
+		},
+		{
+			name: "bad form field",
+			template: models.TicketTemplate{
+				StartStep: "start",
+				EndStep:   []string{"end"},
+				Form: []*models.FormField{
+					{Name: "reason", Type: "text"},
+				},
+				Config: []*models.StepConfig{
+					{Step: "start", Next: []*models.NextStep{{Step: "end"}}},
+					{Step: "end", Next: nil},
+				},
+			},
+			signTypeSet: set.Setify(""),
+			wantErr:     ErrBadFormField,
+		},
+		{
+			name: "bad cc trigger",
+			template: models.TicketTemplate{
+				StartStep: "start",
+				EndStep:   []string{"end"},
+				Config: []*models.StepConfig{
+					{
+						Step: "start",
+						Next: []*models.NextStep{{Step: "end"}},
+						CC:   models.CarbonCopy{Users: []string{"u1"}, Trigger: "sometimes"},
+					},
+					{Step: "end", Next: nil},
+				},
+			},
+			signTypeSet: set.Setify(""),
+			wantErr:     ErrBadCCTrigger,
+		},
 		{
 			name: "badJointSignRate",
 			template: models.TicketTemplate{
@@ -382,11 +445,123 @@ func Test_validator_Validate(t *testing.T) {
 			signTypeSet: set.Setify(models.JointlySign),
 			wantErr:     ErrBadJointSignRate,
 		},
+		{
+			name: "zeroJointSignRate",
+			template: models.TicketTemplate{
+				StartStep: "start",
+				EndStep:   []string{"end"},
+				Config: []*models.StepConfig{
+					{
+						Step:     "start",
+						Operator: []string{"u1", "u2"},
+						Next:     []*models.NextStep{{Step: "end"}},
+						Disposal: models.Disposal{SignType: models.JointlySign},
+					},
+					{Step: "end", Next: nil},
+				},
+			},
+			signTypeSet: set.Setify(models.JointlySign),
+			wantErr:     ErrBadJointSignRate,
+		},
+		{
+			name: "duplicateOperator",
+			template: models.TicketTemplate{
+				StartStep: "start",
+				EndStep:   []string{"end"},
+				Config: []*models.StepConfig{
+					{
+						Step:     "start",
+						Operator: []string{"u1", "u2", "u1"},
+						Next:     []*models.NextStep{{Step: "end"}},
+						Disposal: models.Disposal{SignType: models.JointlySign, JointSignCount: 3},
+					},
+					{Step: "end", Next: nil},
+				},
+			},
+			signTypeSet: set.Setify(models.JointlySign),
+			wantErr:     ErrDuplicateOperator,
+		},
+		{
+			name: "badJointSignCount",
+			template: models.TicketTemplate{
+				StartStep: "start",
+				EndStep:   []string{"end"},
+				Config: []*models.StepConfig{
+					{
+						Step:     "start",
+						Operator: []string{"u1", "u2"},
+						Next:     []*models.NextStep{{Step: "end"}},
+						Disposal: models.Disposal{
+							SignType:       models.JointlySign,
+							JointSignCount: 3,
+						},
+					},
+					{Step: "end", Next: nil},
+				},
+			},
+			signTypeSet: set.Setify(models.JointlySign),
+			wantErr:     ErrBadJointSignCount,
+		},
+		{
+			name: "badServiceTask",
+			template: models.TicketTemplate{
+				StartStep: "start",
+				EndStep:   []string{"end"},
+				Config: []*models.StepConfig{
+					{
+						Step:    "start",
+						Kind:    models.StepKindService,
+						Service: models.ServiceTask{Handler: "create-jira-issue", ErrorStep: "missing"},
+						Next:    []*models.NextStep{{Step: "end", Operation: "created"}},
+					},
+					{Step: "end", Next: nil},
+				},
+			},
+			signTypeSet: set.Setify(""),
+			wantErr:     ErrBadServiceTask,
+		},
+		{
+			name: "badResolution",
+			template: models.TicketTemplate{
+				StartStep: "start",
+				EndStep:   []string{"end"},
+				Config: []*models.StepConfig{
+					{
+						Step:     "start",
+						Next:     []*models.NextStep{{Step: "end"}},
+						Disposal: models.Disposal{SignType: models.SerialSign, Resolution: "random"},
+					},
+					{Step: "end", Next: nil},
+				},
+			},
+			signTypeSet: set.Setify(models.SerialSign),
+			wantErr:     ErrBadResolution,
+		},
+		{
+			name: "badWeightThreshold",
+			template: models.TicketTemplate{
+				StartStep: "start",
+				EndStep:   []string{"end"},
+				Config: []*models.StepConfig{
+					{
+						Step:     "start",
+						Operator: []string{"chair", "m1"},
+						Next:     []*models.NextStep{{Step: "end"}},
+						Disposal: models.Disposal{SignType: models.WeightedSign, WeightThreshold: 4},
+						Weights:  map[string]int{"chair": 2},
+					},
+					{Step: "end", Next: nil},
+				},
+			},
+			signTypeSet: set.Setify(models.WeightedSign),
+			wantErr:     ErrBadWeightThreshold,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			v := &validator{
-				signTypeSet: tt.signTypeSet,
+				signTypeSet:     tt.signTypeSet,
+				allowNoOperator: tt.allowNoOperator,
 			}
 			if err := v.Validate(tt.template); (err == nil) != (tt.wantErr == nil) {
 				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
@@ -415,3 +590,390 @@ func TestNewValidator(t *testing.T) {
 		})
 	}
 }
+
+func Test_validateForm(t *testing.T) {
+	min, max := 10.0, 1.0
+	tests := []struct {
+		name    string
+		form    []*models.FormField
+		config  []*models.StepConfig
+		wantErr error
+	}{
+		{
+			name: "all is ok",
+			form: []*models.FormField{
+				{Name: "reason", Type: models.FieldString, Pattern: `^\w+$`},
+				{Name: "kind", Type: models.FieldEnum, Options: []string{"a"}},
+			},
+			config: []*models.StepConfig{
+				{Step: "start", Editable: []string{"reason"}, Hidden: []string{"kind"}},
+			},
+			wantErr: nil,
+		},
+		{
+			name:    "empty form",
+			wantErr: nil,
+		},
+		{
+			name:    "nil field",
+			form:    []*models.FormField{nil},
+			wantErr: ErrBadFormField,
+		},
+		{
+			name:    "empty name",
+			form:    []*models.FormField{{Type: models.FieldString}},
+			wantErr: ErrBadFormField,
+		},
+		{
+			name: "duplicate field",
+			form: []*models.FormField{
+				{Name: "reason", Type: models.FieldString},
+				{Name: "reason", Type: models.FieldNumber},
+			},
+			wantErr: ErrBadFormField,
+		},
+		{
+			name:    "bad type",
+			form:    []*models.FormField{{Name: "reason", Type: "text"}},
+			wantErr: ErrBadFormField,
+		},
+		{
+			name:    "enum without options",
+			form:    []*models.FormField{{Name: "kind", Type: models.FieldEnum}},
+			wantErr: ErrBadFormField,
+		},
+		{
+			name:    "min greater than max",
+			form:    []*models.FormField{{Name: "amount", Type: models.FieldNumber, Min: &min, Max: &max}},
+			wantErr: ErrBadFormField,
+		},
+		{
+			name:    "bad pattern",
+			form:    []*models.FormField{{Name: "reason", Type: models.FieldString, Pattern: "("}},
+			wantErr: ErrBadFormField,
+		},
+		{
+			name:    "edit undefined field",
+			form:    []*models.FormField{{Name: "reason", Type: models.FieldString}},
+			config:  []*models.StepConfig{{Step: "start", Editable: []string{"amount"}}},
+			wantErr: ErrBadFormField,
+		},
+		{
+			name:    "hide undefined field",
+			form:    []*models.FormField{{Name: "reason", Type: models.FieldString}},
+			config:  []*models.StepConfig{{Step: "start", Hidden: []string{"amount"}}},
+			wantErr: ErrBadFormField,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateForm(tt.form, tt.config); !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateForm() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *models.StepConfig
+		wantErr error
+	}{
+		{
+			name: "all is ok",
+			config: &models.StepConfig{
+				Operator: []string{"chair", "m1"},
+				Disposal: models.Disposal{SignType: models.WeightedSign, WeightThreshold: 3},
+				Weights:  map[string]int{"chair": 2},
+			},
+		},
+		{
+			name: "negative weight",
+			config: &models.StepConfig{
+				Disposal: models.Disposal{SignType: models.WeightedSign, WeightThreshold: 1},
+				Weights:  map[string]int{"chair": -1},
+			},
+			wantErr: ErrBadWeight,
+		},
+		{
+			name: "zero threshold",
+			config: &models.StepConfig{
+				Operator: []string{"chair"},
+				Disposal: models.Disposal{SignType: models.WeightedSign},
+			},
+			wantErr: ErrBadWeightThreshold,
+		},
+		{
+			name: "unreachable",
+			config: &models.StepConfig{
+				Operator: []string{"chair", "m1"},
+				Disposal: models.Disposal{SignType: models.WeightedSign, WeightThreshold: 4},
+				Weights:  map[string]int{"chair": 2},
+			},
+			wantErr: ErrBadWeightThreshold,
+		},
+		{
+			name: "operators assigned at runtime",
+			config: &models.StepConfig{
+				Disposal: models.Disposal{SignType: models.WeightedSign, WeightThreshold: 4},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateWeights(tt.config); !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateWeights() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateEndStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		config   *models.StepConfig
+		wantErr  error
+	}{
+		{
+			name:   "built-in status",
+			config: &models.StepConfig{Step: "end", EndStatus: models.Cancelled},
+		},
+		{
+			name:     "custom status",
+			statuses: []string{"withdrawn"},
+			config:   &models.StepConfig{Step: "end", EndStatus: "withdrawn"},
+		},
+		{
+			name:    "unknown status",
+			config:  &models.StepConfig{Step: "end", EndStatus: "withdrawn"},
+			wantErr: ErrBadEndStatus,
+		},
+		{
+			name:    "running is not terminal",
+			config:  &models.StepConfig{Step: "end", EndStatus: models.Running},
+			wantErr: ErrBadEndStatus,
+		},
+		{
+			name:    "not an end step",
+			config:  &models.StepConfig{Step: "a", EndStatus: models.Rejected},
+			wantErr: ErrBadEndStatus,
+		},
+		{
+			name:     "custom running",
+			statuses: []string{models.Running},
+			config:   &models.StepConfig{Step: "end"},
+			wantErr:  ErrBadEndStatus,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl := &models.TicketTemplate{Statuses: tt.statuses, Config: []*models.StepConfig{tt.config}}
+			if err := validateEndStatus(tpl, set.Setify("end")); !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateEndStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateState(t *testing.T) {
+	tests := []struct {
+		name    string
+		states  []string
+		config  []*models.StepConfig
+		wantErr error
+	}{
+		{
+			name:   "states not declared",
+			config: []*models.StepConfig{{Step: "a", State: "anything"}},
+		},
+		{
+			name:   "all is ok",
+			states: []string{"in_review", "awaiting_payment"},
+			config: []*models.StepConfig{{Step: "a", State: "in_review"}, {Step: "b", State: "awaiting_payment"}},
+		},
+		{
+			name:    "undeclared state",
+			states:  []string{"in_review"},
+			config:  []*models.StepConfig{{Step: "a", State: "in_review"}, {Step: "b", State: "paid"}},
+			wantErr: ErrBadState,
+		},
+		{
+			name:    "missing state",
+			states:  []string{"in_review"},
+			config:  []*models.StepConfig{{Step: "a"}},
+			wantErr: ErrBadState,
+		},
+		{
+			name:    "duplicate declaration",
+			states:  []string{"in_review", "in_review"},
+			config:  []*models.StepConfig{{Step: "a", State: "in_review"}},
+			wantErr: ErrBadState,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl := &models.TicketTemplate{States: tt.states, Config: tt.config}
+			if err := validateState(tpl); !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateState() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateReopen(t *testing.T) {
+	tests := []struct {
+		name    string
+		tpl     *models.TicketTemplate
+		wantErr error
+	}{
+		{
+			name: "not reopenable",
+			tpl:  &models.TicketTemplate{},
+		},
+		{
+			name: "custom status",
+			tpl: &models.TicketTemplate{
+				Statuses: []string{"withdrawn"},
+				Reopen:   models.ReopenPolicy{Statuses: []string{models.Rejected, "withdrawn"}, MaxTimes: 1},
+			},
+		},
+		{
+			name:    "running",
+			tpl:     &models.TicketTemplate{Reopen: models.ReopenPolicy{Statuses: []string{models.Running}}},
+			wantErr: ErrBadReopenPolicy,
+		},
+		{
+			name:    "unknown status",
+			tpl:     &models.TicketTemplate{Reopen: models.ReopenPolicy{Statuses: []string{"withdrawn"}}},
+			wantErr: ErrBadReopenPolicy,
+		},
+		{
+			name:    "negative max times",
+			tpl:     &models.TicketTemplate{Reopen: models.ReopenPolicy{Statuses: []string{models.Passed}, MaxTimes: -1}},
+			wantErr: ErrBadReopenPolicy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateReopen(tt.tpl); !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateReopen() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateOperators(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator []string
+		wantErr  error
+	}{
+		{name: "no operator"},
+		{name: "all is ok", operator: []string{"u1", "u2"}},
+		{name: "empty id", operator: []string{"u1", ""}, wantErr: ErrBadOperator},
+		{name: "duplicate", operator: []string{"u1", "u2", "u1"}, wantErr: ErrDuplicateOperator},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateOperators(&models.StepConfig{Step: "a", Operator: tt.operator}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateOperators() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_ValidateSubProcess(t *testing.T) {
+	subProcess := func(uid string, refs ...string) *models.TicketTemplate {
+		tpl := &models.TicketTemplate{Uid: uid}
+		for _, ref := range refs {
+			tpl.Config = append(tpl.Config, &models.StepConfig{Step: ref, Kind: models.StepKindSubProcess, Template: ref})
+		}
+		return tpl
+	}
+	templates := map[string]*models.TicketTemplate{
+		"a": subProcess("a", "b"),
+		"b": subProcess("b", "c"),
+		"c": subProcess("c"),
+		"x": subProcess("x", "y"),
+		"y": subProcess("y", "x"),
+		"m": subProcess("m", "missing"),
+		"p": {Uid: "p", Config: []*models.StepConfig{{
+			Step: "contract", Kind: models.StepKindSubProcess, Template: "s",
+			Next: []*models.NextStep{
+				{Operation: models.Passed, Step: "end"},
+				{Operation: models.Cancelled, Step: "end"},
+				{Operation: "withdrawn", Step: "end"},
+			},
+		}}},
+		"q": {Uid: "q", Config: []*models.StepConfig{{
+			Step: "contract", Kind: models.StepKindSubProcess, Template: "s",
+			Next: []*models.NextStep{{Operation: "approve", Step: "end"}},
+		}}},
+		"s": {Uid: "s", Statuses: []string{"withdrawn"}},
+	}
+	lookup := func(uid string) *models.TicketTemplate { return templates[uid] }
+	tests := []struct {
+		name    string
+		uid     string
+		wantErr error
+	}{
+		{name: "all is ok", uid: "a"},
+		{name: "cycle", uid: "x", wantErr: ErrRecursiveSubProcess},
+		{name: "missing", uid: "m", wantErr: ErrBadSubProcess},
+		{name: "child statuses", uid: "p"},
+		{name: "not a child status", uid: "q", wantErr: ErrBadSubProcess},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateSubProcess(templates[tt.uid], lookup); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateSubProcess() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateSubProcessStep(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *models.StepConfig
+		wantErr error
+	}{
+		{
+			name:   "all is ok",
+			config: &models.StepConfig{Step: "s", Kind: models.StepKindSubProcess, Template: "child", Next: []*models.NextStep{{Operation: models.Passed, Step: "end"}}},
+		},
+		{
+			name:    "no template",
+			config:  &models.StepConfig{Step: "s", Kind: models.StepKindSubProcess},
+			wantErr: ErrBadSubProcess,
+		},
+		{
+			name:    "self reference",
+			config:  &models.StepConfig{Step: "s", Kind: models.StepKindSubProcess, Template: "self"},
+			wantErr: ErrRecursiveSubProcess,
+		},
+		{
+			name:   "cancelled and custom statuses",
+			config: &models.StepConfig{Step: "s", Kind: models.StepKindSubProcess, Template: "child", Next: []*models.NextStep{{Operation: models.Cancelled, Step: "end"}, {Operation: "withdrawn", Step: "end"}}},
+		},
+		{
+			name:    "running operation",
+			config:  &models.StepConfig{Step: "s", Kind: models.StepKindSubProcess, Template: "child", Next: []*models.NextStep{{Operation: models.Running, Step: "end"}}},
+			wantErr: ErrBadSubProcess,
+		},
+		{
+			name:    "empty operation",
+			config:  &models.StepConfig{Step: "s", Kind: models.StepKindSubProcess, Template: "child", Next: []*models.NextStep{{Step: "end"}}},
+			wantErr: ErrBadSubProcess,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateSubProcessStep("self", tt.config); !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateSubProcessStep() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
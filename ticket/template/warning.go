@@ -0,0 +1,69 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/victorwong171/go-utils/desc/set"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+var (
+	ErrEndStepHasOperator = errors.New("end step has operators")
+	ErrUnboundedLoop      = errors.New("loop has no visit limit")
+)
+
+// Warning 不影响模板通过校验、但可能导致工单异常的配置问题，可使用errors.Is匹配Err
+type Warning struct {
+	Step string // 步骤名
+	Err  error  // 问题类型
+}
+
+func (w *Warning) Error() string {
+	return fmt.Sprintf("%v: %s", w.Err, w.Step)
+}
+
+func (w *Warning) Unwrap() error {
+	return w.Err
+}
+
+// Warnings 检查模板中可疑的配置，结果按步骤配置顺序排列
+func Warnings(tpl models.TicketTemplate) []*Warning {
+	var warnings []*Warning
+	endStepSet := set.Setify(tpl.EndStep...)
+	for _, c := range tpl.Config {
+		if c == nil {
+			continue
+		}
+		if endStepSet.HasKey(c.Step) && len(c.Operator) > 0 {
+			warnings = append(warnings, &Warning{Step: c.Step, Err: ErrEndStepHasOperator})
+		}
+	}
+	// 环路上的步骤与流转都没有次数限制时，工单可能无限循环
+	stepMap := tpl.StepConfigMap()
+	for _, cycle := range findCycles(&tpl) {
+		if !bounded(cycle, stepMap) {
+			warnings = append(warnings, &Warning{Step: cycle[0], Err: ErrUnboundedLoop})
+		}
+	}
+	return warnings
+}
+
+// bounded 环路上是否有步骤或流转配置了次数限制，cycle首尾相同
+func bounded(cycle []string, stepMap map[string]*models.StepConfig) bool {
+	for i := 0; i+1 < len(cycle); i++ {
+		c := stepMap[cycle[i]]
+		if c == nil {
+			continue
+		}
+		if c.Limit.Enabled() {
+			return true
+		}
+		for _, next := range c.Next {
+			if next.GetStep() == cycle[i+1] && next.Limit.Enabled() {
+				return true
+			}
+		}
+	}
+	return false
+}
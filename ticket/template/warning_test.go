@@ -0,0 +1,80 @@
+package template
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func TestWarnings(t *testing.T) {
+	tpl := models.TicketTemplate{
+		StartStep: "a",
+		EndStep:   []string{"end"},
+		Config: []*models.StepConfig{
+			{Step: "a", Operator: []string{"u1"}, Next: []*models.NextStep{{Operation: "ok", Step: "b"}}},
+			{Step: "b", Next: []*models.NextStep{{Operation: "ok", Step: "c"}}},
+			{Step: "c", Kind: models.StepKindService, Next: []*models.NextStep{{Operation: "ok", Step: "end"}}},
+			{Step: "end", Operator: []string{"u1"}},
+		},
+	}
+	warnings := Warnings(tpl)
+	if len(warnings) != 1 {
+		t.Fatalf("Warnings() = %v, want 1 warning", warnings)
+	}
+	if warnings[0].Step != "end" || !errors.Is(warnings[0], ErrEndStepHasOperator) {
+		t.Errorf("Warnings()[0] = %v, want %v of end", warnings[0], ErrEndStepHasOperator)
+	}
+}
+
+func TestWarnings_UnboundedLoop(t *testing.T) {
+	newTemplate := func(limit models.LoopLimit) models.TicketTemplate {
+		return models.TicketTemplate{
+			StartStep: "review",
+			EndStep:   []string{"end"},
+			Config: []*models.StepConfig{
+				{Step: "review", Operator: []string{"u1"}, Next: []*models.NextStep{
+					{Operation: "approve", Step: "end"},
+					{Operation: models.Reject, Step: "revise"},
+				}},
+				{Step: "revise", Operator: []string{"u2"}, Next: []*models.NextStep{
+					{Operation: "submit", Step: "review", Limit: limit},
+				}},
+				{Step: "end"},
+			},
+		}
+	}
+	warnings := Warnings(newTemplate(models.LoopLimit{}))
+	if len(warnings) != 1 || !errors.Is(warnings[0], ErrUnboundedLoop) || warnings[0].Step != "review" {
+		t.Errorf("Warnings() = %v, want %v of review", warnings, ErrUnboundedLoop)
+	}
+	if warnings = Warnings(newTemplate(models.LoopLimit{MaxVisits: 3})); len(warnings) != 0 {
+		t.Errorf("Warnings() with limit = %v, want none", warnings)
+	}
+}
+
+func Test_validateLoopLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   models.LoopLimit
+		wantErr error
+	}{
+		{name: "no limit"},
+		{name: "reject", limit: models.LoopLimit{MaxVisits: 3}},
+		{name: "escalate", limit: models.LoopLimit{MaxVisits: 3, OnExceeded: models.LimitEscalate, Step: "b"}},
+		{name: "negative", limit: models.LoopLimit{MaxVisits: -1}, wantErr: ErrBadLoopLimit},
+		{name: "bad behaviour", limit: models.LoopLimit{MaxVisits: 1, OnExceeded: "retry"}, wantErr: ErrBadLoopLimit},
+		{name: "escalation not found", limit: models.LoopLimit{MaxVisits: 1, OnExceeded: models.LimitEscalate, Step: "x"}, wantErr: ErrBadLoopLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stepMap := map[string]*models.StepConfig{
+				"a": {Step: "a", Next: []*models.NextStep{{Step: "b", Limit: tt.limit}}},
+				"b": {Step: "b", Limit: tt.limit},
+			}
+			if err := validateLoopLimits(stepMap); !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateLoopLimits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
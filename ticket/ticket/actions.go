@@ -0,0 +1,63 @@
+package ticket
+
+import (
+	"github.com/victorwong171/punched-tape/models"
+)
+
+// AvailableAction 用户在工单当前步骤上的一个操作
+type AvailableAction struct {
+	Operation string `json:"operation"` // 操作名
+	Step      string `json:"step"`      // 目标步骤
+	Allowed   bool   `json:"allowed"`   // 是否允许执行
+	Override  string `json:"override"`  // 执行时使用的管理员能力，处理人正常审批时为空
+	Reason    string `json:"reason"`    // 不允许执行的错误码，与Approval返回错误的Code一致
+	Err       error  `json:"-"`         // 不允许执行的错误值，可使用errors.Is判断
+}
+
+// AvailableActions 列出用户在工单当前步骤上的全部操作，校验规则与Helper.Approval一致，包括admin与Authorizer越权
+// 当前步骤不存在时返回空列表
+func (h *Helper) AvailableActions(ticket *models.Ticket, tpl *models.TicketTemplate, user string, admin bool) []*AvailableAction {
+	if ticket == nil || tpl == nil {
+		return []*AvailableAction{}
+	}
+	stepConfig := tpl.StepConfigMap()
+	step := stepConfig[ticket.Step]
+	if step == nil {
+		return []*AvailableAction{}
+	}
+	actions := make([]*AvailableAction, 0, len(step.GetNext()))
+	for _, next := range step.GetNext() {
+		action := &AvailableAction{
+			Operation: next.GetOperation(),
+			Step:      next.GetStep(),
+		}
+		// 越权所需的能力与操作有关，逐个操作校验
+		var reason error
+		if len(user) == 0 {
+			reason = ErrMissingArguments
+		} else {
+			_, action.Override, reason = h.authorize(user, action.Operation, admin, ticket, stepConfig)
+		}
+		if reason == nil && stepConfig[action.Step] == nil {
+			reason = ErrNextStepNotFound
+		}
+		action.Allowed = reason == nil
+		if reason != nil {
+			action.Err = newApprovalError(reason, ticket.Uid, ticket.Step, user, action.Operation, action.Step)
+			action.Reason = Code(reason)
+		}
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+// Allowed 过滤出允许执行的操作
+func Allowed(actions []*AvailableAction) []*AvailableAction {
+	allowed := make([]*AvailableAction, 0, len(actions))
+	for _, a := range actions {
+		if a.Allowed {
+			allowed = append(allowed, a)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,137 @@
+package ticket
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func TestAvailableActions(t *testing.T) {
+	tpl := &models.TicketTemplate{
+		EndStep: []string{"end"},
+		Config: []*models.StepConfig{
+			{
+				Step:     "a",
+				Operator: []string{"u1"},
+				Disposal: models.Disposal{SignType: models.AnyoneSign},
+				Next: []*models.NextStep{
+					{Operation: "approve", Step: "end"},
+					{Operation: "reject", Step: "end"},
+					{Operation: "escalate", Step: "missing"},
+				},
+			},
+			{Step: "end"},
+		},
+	}
+	running := &models.Ticket{Status: models.Running, Step: "a", Operator: []string{"u1"}}
+	tests := []struct {
+		name       string
+		ticket     *models.Ticket
+		user       string
+		admin      bool
+		authorizer Authorizer
+		want       []*AvailableAction
+	}{
+		{
+			name:   "all is ok",
+			ticket: running,
+			user:   "u1",
+			want: []*AvailableAction{
+				{Operation: "approve", Step: "end", Allowed: true},
+				{Operation: "reject", Step: "end", Allowed: true},
+				{Operation: "escalate", Step: "missing", Reason: CodeNextStepNotFound, Err: ErrNextStepNotFound},
+			},
+		},
+		{
+			name:   "not operator",
+			ticket: running,
+			user:   "u2",
+			want: []*AvailableAction{
+				{Operation: "approve", Step: "end", Reason: CodeOperatorNotAllowed, Err: ErrOperatorNotInOperatorList},
+				{Operation: "reject", Step: "end", Reason: CodeOperatorNotAllowed, Err: ErrOperatorNotInOperatorList},
+				{Operation: "escalate", Step: "missing", Reason: CodeOperatorNotAllowed, Err: ErrOperatorNotInOperatorList},
+			},
+		},
+		{
+			name:   "already operated",
+			ticket: &models.Ticket{Status: models.Running, Step: "a", OperatedUser: []string{"u1"}},
+			user:   "u1",
+			want: []*AvailableAction{
+				{Operation: "approve", Step: "end", Reason: CodeAlreadySigned, Err: ErrAlreadySigned},
+				{Operation: "reject", Step: "end", Reason: CodeAlreadySigned, Err: ErrAlreadySigned},
+				{Operation: "escalate", Step: "missing", Reason: CodeAlreadySigned, Err: ErrAlreadySigned},
+			},
+		},
+		{
+			name:   "admin",
+			ticket: running,
+			user:   "root",
+			admin:  true,
+			want: []*AvailableAction{
+				{Operation: "approve", Step: "end", Allowed: true, Override: models.CapForceApprove},
+				{Operation: "reject", Step: "end", Allowed: true, Override: models.CapForceReject},
+				{Operation: "escalate", Step: "missing", Reason: CodeNextStepNotFound, Err: ErrNextStepNotFound, Override: models.CapForceApprove},
+			},
+		},
+		{
+			name:       "authorizer grants reject only",
+			ticket:     running,
+			user:       "auditor",
+			authorizer: Capabilities{"auditor": {models.CapForceReject}},
+			want: []*AvailableAction{
+				{Operation: "approve", Step: "end", Reason: CodeOperatorNotAllowed, Err: ErrOperatorNotInOperatorList},
+				{Operation: "reject", Step: "end", Allowed: true, Override: models.CapForceReject},
+				{Operation: "escalate", Step: "missing", Reason: CodeOperatorNotAllowed, Err: ErrOperatorNotInOperatorList},
+			},
+		},
+		{
+			name:   "unknown step",
+			ticket: &models.Ticket{Status: models.Running, Step: "x"},
+			user:   "u1",
+			want:   []*AvailableAction{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Helper{}
+			h.SetAuthorizer(tt.authorizer)
+			got := h.AvailableActions(tt.ticket, tpl, tt.user, tt.admin)
+			if diff := cmp.Diff(got, tt.want, cmpopts.EquateErrors()); len(diff) > 0 {
+				t.Errorf("AvailableActions() diff = %v", diff)
+			}
+		})
+	}
+}
+
+func TestAvailableActions_MatchApproval(t *testing.T) {
+	tpl := &models.TicketTemplate{
+		EndStep: []string{"end"},
+		Config: []*models.StepConfig{
+			{
+				Step:     "a",
+				Operator: []string{"u1"},
+				Disposal: models.Disposal{SignType: models.AnyoneSign},
+				Next:     []*models.NextStep{{Operation: "approve", Step: "end"}},
+			},
+			{Step: "end"},
+		},
+	}
+	ticket := &models.Ticket{Status: models.Running, Step: "a", Operator: []string{"u1"}}
+	h := &Helper{}
+	h.SetAuthorizer(Capabilities{"u3": {models.CapForceApprove}})
+	for _, user := range []string{"u1", "u2", "u3"} {
+		for _, admin := range []bool{false, true} {
+			for _, a := range h.AvailableActions(ticket, tpl, user, admin) {
+				tr, err := h.Preview(a.Step, a.Operation, user, admin, tpl.EndStep, ticket, tpl.StepConfigMap())
+				if Code(err) != a.Reason || (err == nil) != a.Allowed || (err == nil && tr.Action.Override != a.Override) {
+					t.Errorf("user %s admin %v operation %s: Preview() error = %v, AvailableActions() = %+v", user, admin, a.Operation, err, a)
+				}
+			}
+		}
+	}
+	if got := Allowed(h.AvailableActions(ticket, tpl, "u1", false)); len(got) != 1 {
+		t.Errorf("Allowed() len = %d, want 1", len(got))
+	}
+}
@@ -1,21 +1,20 @@
 package ticket
 
 import (
-	"errors"
+	"context"
+	"fmt"
+	"maps"
+	"math/big"
+	"strconv"
+	"time"
 
 	"github.com/victorwong171/punched-tape/models"
+	"github.com/victorwong171/punched-tape/ticket/form"
 
 	"github.com/victorwong171/go-utils/desc/set"
 	"github.com/victorwong171/go-utils/utils"
 )
 
-var (
-	ErrOperatorNotInOperatorList = errors.New("operator not in operator list")
-	ErrAlreadyApproved           = errors.New("already approved")
-	ErrBadArguments              = errors.New("bad arguments")
-	ErrInvalidStep               = errors.New("invalid step")
-)
-
 type Approval interface {
 	Approval(next, operation, operator string, ticket *models.Ticket, config *models.StepConfig) (*models.Ticket, error)
 }
@@ -23,15 +22,95 @@ type Approval interface {
 type DisposalHandler interface {
 }
 
-var updateStrategy = map[string]func(operator string, ticket *models.Ticket, jointlySignRate float32, nextStep *models.StepConfig, endStep []string) *models.Ticket{
-	models.JointlySign: jointlySignUpdater,
-	models.SerialSign:  serialSignUpdater,
-	models.AnyoneSign:  anyoneSignUpdater,
+var updateStrategy = map[string]func(operator, operation string, ticket *models.Ticket, step, nextStep *models.StepConfig, stepConfig map[string]*models.StepConfig, endStep []string) *models.Ticket{
+	models.JointlySign:  jointlySignUpdater,
+	models.SerialSign:   serialSignUpdater,
+	models.AnyoneSign:   anyoneSignUpdater,
+	models.WeightedSign: weightedSignUpdater,
+}
+
+// ApprovalOption 审批操作的可选参数
+type ApprovalOption func(*approvalOptions)
+
+type approvalOptions struct {
+	comment     string
+	attachments []*models.Attachment
+	cc          []string
+	at          time.Time
+	ctx         context.Context
+	quiet       bool
+	schema      []*models.FormField
+	formData    map[string]any
+}
+
+// WithComment 附带审批意见
+func WithComment(comment string) ApprovalOption {
+	return func(o *approvalOptions) {
+		o.comment = comment
+	}
+}
+
+// WithAttachments 附带附件引用，附件内容需预先保存到BlobStore
+func WithAttachments(attachments ...*models.Attachment) ApprovalOption {
+	return func(o *approvalOptions) {
+		o.attachments = append(o.attachments, attachments...)
+	}
+}
+
+// WithCC 审批时追加工单抄送用户，抄送用户不获得审批权限
+func WithCC(users ...string) ApprovalOption {
+	return func(o *approvalOptions) {
+		o.cc = append(o.cc, users...)
+	}
+}
+
+// WithFormData 审批时提交修改后的表单数据，schema为模板的表单定义
+// 只能修改当前步骤StepConfig.Editable中声明的字段，隐藏字段保留原值，校验失败时返回ErrBadFormData
+func WithFormData(schema []*models.FormField, data map[string]any) ApprovalOption {
+	return func(o *approvalOptions) {
+		o.schema = schema
+		o.formData = data
+	}
+}
+
+// WithTime 指定操作时间，默认为当前时间，用于事件回放等需要确定性结果的场景
+func WithTime(at time.Time) ApprovalOption {
+	return func(o *approvalOptions) {
+		o.at = at
+	}
+}
+
+// WithContext 指定自动步骤处理器使用的上下文，默认为context.Background()；上下文取消后不再重试
+func WithContext(ctx context.Context) ApprovalOption {
+	return func(o *approvalOptions) {
+		o.ctx = ctx
+	}
+}
+
+// WithoutNotify 操作完成后不通知监听器，由调用方在持久化成功后调用Notify
+func WithoutNotify() ApprovalOption {
+	return func(o *approvalOptions) {
+		o.quiet = true
+	}
+}
+
+func newApprovalOptions(opts []ApprovalOption) *approvalOptions {
+	options := &approvalOptions{at: time.Now(), ctx: context.Background()}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
 }
 
 type Helper struct {
+	listeners  []Listener
+	templates  TemplateLookup
+	handlers   map[string]ServiceHandler
+	sleep      func(time.Duration)
+	authorizer Authorizer
 }
 
+// Approval 执行审批操作并返回流转后的新工单，不修改传入的工单与步骤配置
 func (h *Helper) Approval(
 	next,
 	operation,
@@ -39,26 +118,73 @@ func (h *Helper) Approval(
 	admin bool,
 	endStep []string,
 	ticket *models.Ticket,
-	stepConfig map[string]*models.StepConfig) (*models.Ticket, error) {
-	if len(next) == 0 || len(operation) == 0 || len(operator) == 0 {
-		return nil, ErrBadArguments
-	}
-	if ticket.Status != models.Running {
-		return nil, ErrAlreadyApproved
+	stepConfig map[string]*models.StepConfig,
+	opts ...ApprovalOption) (*models.Ticket, error) {
+	tr, err := h.Transit(next, operation, operator, admin, endStep, ticket, stepConfig, opts...)
+	if err != nil {
+		return nil, err
 	}
+	return tr.Ticket, nil
+}
 
-	step := stepConfig[ticket.Step]
-	if step == nil {
-		return nil, ErrInvalidStep
+// Transit 执行审批操作并返回本次流转，Transition.Previous为操作前工单的副本，Transition.Ticket为流转后的新工单
+// 传入的工单与步骤配置只读，可在多个goroutine间共享
+func (h *Helper) Transit(
+	next,
+	operation,
+	operator string,
+	admin bool,
+	endStep []string,
+	ticket *models.Ticket,
+	stepConfig map[string]*models.StepConfig,
+	opts ...ApprovalOption) (*models.Transition, error) {
+	options := newApprovalOptions(opts)
+	tr, err := h.transit(false, next, operation, operator, admin, endStep, ticket, stepConfig, options)
+	if err != nil {
+		return nil, err
 	}
+	h.notify(tr, options)
+	return tr, nil
+}
 
-	// todo: 已经操作过的人是否可以 reject？
-	if utils.Contain(ticket.OperatedUser, operator) {
-		return nil, ErrAlreadyApproved
-	}
+// Preview 预演审批操作，执行与Approval相同的权限校验与会签计算，返回操作后将产生的流转
+// 不修改任何输入，也不触发监听器，可用于在用户确认前展示下一步骤与处理人
+func (h *Helper) Preview(
+	next,
+	operation,
+	operator string,
+	admin bool,
+	endStep []string,
+	ticket *models.Ticket,
+	stepConfig map[string]*models.StepConfig,
+	opts ...ApprovalOption) (*models.Transition, error) {
+	return h.transit(true, next, operation, operator, admin, endStep, ticket, stepConfig, newApprovalOptions(opts))
+}
 
-	if !(admin || utils.Contain(ticket.Operator, operator)) {
-		return nil, ErrOperatorNotInOperatorList
+func (h *Helper) transit(
+	preview bool,
+	next,
+	operation,
+	operator string,
+	admin bool,
+	endStep []string,
+	ticket *models.Ticket,
+	stepConfig map[string]*models.StepConfig,
+	options *approvalOptions) (*models.Transition, error) {
+	if ticket == nil {
+		return nil, newApprovalError(ErrMissingArguments, "", "", operator, operation, next)
+	}
+	if len(next) == 0 || len(operation) == 0 || len(operator) == 0 {
+		return nil, newApprovalError(ErrMissingArguments, ticket.Uid, ticket.Step, operator, operation, next)
+	}
+	for _, a := range options.attachments {
+		if a == nil || len(a.Name) == 0 || len(a.URI) == 0 {
+			return nil, newApprovalError(ErrBadAttachment, ticket.Uid, ticket.Step, operator, operation, next)
+		}
+	}
+	step, override, err := h.authorize(operator, operation, admin, ticket, stepConfig)
+	if err != nil {
+		return nil, newApprovalError(err, ticket.Uid, ticket.Step, operator, operation, next)
 	}
 
 	var reachable bool
@@ -68,51 +194,285 @@ func (h *Helper) Approval(
 		}
 	}
 	if !reachable {
-		return nil, ErrInvalidStep
+		return nil, newApprovalError(ErrNextStepNotAllowed, ticket.Uid, ticket.Step, operator, operation, next)
+	}
+	if stepConfig[next] == nil {
+		return nil, newApprovalError(ErrNextStepNotFound, ticket.Uid, ticket.Step, operator, operation, next)
+	}
+	var formData map[string]any
+	if options.formData != nil {
+		formData = form.MergeHidden(step, ticket.FormData, options.formData)
+		if err = form.ValidateStep(options.schema, step, ticket.FormData, formData); err != nil {
+			return nil, newApprovalError(fmt.Errorf("%w: %w", ErrBadFormData, err), ticket.Uid, ticket.Step, operator, operation, next)
+		}
+	}
+	action := &models.Action{
+		Operator:    operator,
+		Operation:   operation,
+		Step:        ticket.Step,
+		Next:        next,
+		Comment:     options.comment,
+		Attachments: options.attachments,
+		CreatedAt:   options.at,
+		Override:    override,
 	}
 	nextStep := stepConfig[next]
-	ticket = updateStrategy[step.Disposal.SignType](operator, ticket, step.Disposal.JointSignRate, nextStep, endStep)
-	return ticket, nil
+	updater, ok := updateStrategy[step.Disposal.SignType]
+	if !ok {
+		return nil, newApprovalError(ErrBadSignType, ticket.Uid, ticket.Step, operator, operation, next)
+	}
+	updated := updater(operator, operation, ticket, step, nextStep, stepConfig, endStep)
+	updated.AddCC(options.cc...)
+	if formData != nil {
+		updated.FormData = maps.Clone(formData)
+	}
+	return h.settle(ticket, updated, step, stepConfig, endStep, action, options, !preview)
 }
 
-func updateTicket(ticket *models.Ticket, nextStep *models.StepConfig, endStep []string) *models.Ticket {
+// settle 检查环路次数限制，记录操作并生成流转，随后处理进入新步骤时的自动行为
+// auto为false时不执行自动步骤的处理器，用于Preview
+func (h *Helper) settle(
+	previous,
+	updated *models.Ticket,
+	step *models.StepConfig,
+	stepConfig map[string]*models.StepConfig,
+	endStep []string,
+	action *models.Action,
+	options *approvalOptions,
+	auto bool) (*models.Transition, error) {
+	limited, err := limitLoop(previous.Step, step, updated, stepConfig, endStep)
+	if err != nil {
+		return nil, newApprovalError(err, previous.Uid, previous.Step, action.Operator, action.Operation, action.Next)
+	}
+	updated.History = append(updated.History, action)
+	tr := &models.Transition{
+		Ticket:   updated,
+		Previous: previous.Clone(),
+		From:     action.Step,
+		To:       updated.Step,
+		Action:   action.Clone(),
+		Events:   transitionEvents(action.Step, updated.Step, updated.Status),
+		CC:       append([]string(nil), options.cc...),
+	}
+	if len(action.Override) > 0 {
+		tr.Events = append(tr.Events, models.EventOverridden)
+	}
+	if tr.From != tr.To {
+		users, roles := carbonCopy(updated, step, stepConfig[updated.Step])
+		tr.CC = append(tr.CC, users...)
+		tr.CCRoles = roles
+		if err = h.enter(tr, stepConfig, endStep, options, auto); err != nil {
+			return nil, newApprovalError(err, previous.Uid, previous.Step, action.Operator, action.Operation, action.Next)
+		}
+	}
+	if limited {
+		tr.Events = append(tr.Events, models.EventLoopLimited)
+	}
+	return tr, nil
+}
 
+// enter 处理工单进入子流程步骤或自动步骤，自动步骤可连续推进
+func (h *Helper) enter(
+	tr *models.Transition,
+	stepConfig map[string]*models.StepConfig,
+	endStep []string,
+	options *approvalOptions,
+	auto bool) error {
+	for hops := 0; tr.Ticket.Status == models.Running; hops++ {
+		ticket := tr.Ticket
+		entered := stepConfig[ticket.Step]
+		switch {
+		case entered.IsSubProcess():
+			child, err := h.enterSubProcess(ticket, entered)
+			if err != nil {
+				return err
+			}
+			tr.Events = append(tr.Events, models.EventChildOpened)
+			if child != nil {
+				tr.Children = append(tr.Children, child)
+			}
+			return nil
+		case entered.IsService():
+			ticket.Operator = nil
+			handler := h.handlers[entered.Service.Handler]
+			if !auto || handler == nil {
+				// 处理器未注册时停留在自动步骤，等待RunService
+				return nil
+			}
+			if hops >= maxServiceHops {
+				return fmt.Errorf("%w: %d", ErrTooManyServiceHops, hops)
+			}
+			action, nextStep, err := h.execute(handler, ticket, entered, stepConfig, options)
+			if err != nil {
+				return err
+			}
+			updated := updateTicket(ticket, nextStep, endStep)
+			limited, err := limitLoop(entered.Step, entered, updated, stepConfig, endStep)
+			if err != nil {
+				return err
+			}
+			updated.History = append(updated.History, action)
+			users, roles := carbonCopy(updated, entered, stepConfig[updated.Step])
+			tr.Ticket = updated
+			tr.To = updated.Step
+			tr.Events = append(tr.Events, transitionEvents(action.Step, updated.Step, updated.Status)...)
+			if limited {
+				tr.Events = append(tr.Events, models.EventLoopLimited)
+			}
+			tr.CC = append(tr.CC, users...)
+			tr.CCRoles = append(tr.CCRoles, roles...)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// checkStep 校验工单状态，返回需要人工处理的当前步骤配置
+func checkStep(ticket *models.Ticket, stepConfig map[string]*models.StepConfig) (*models.StepConfig, error) {
+	if ticket.Status != models.Running {
+		return nil, ErrTicketNotRunning
+	}
+
+	step := stepConfig[ticket.Step]
+	if step == nil {
+		return nil, ErrStepNotFound
+	}
+	if step.IsSubProcess() {
+		return nil, ErrWaitingForChild
+	}
+	if step.IsService() {
+		return nil, ErrAutomaticStep
+	}
+	return step, nil
+}
+
+// checkOperator 校验工单状态与操作人权限，返回当前步骤配置，错误不携带上下文
+// 操作人不在处理人列表中时同时返回步骤配置，供越权校验使用
+func checkOperator(operator string, ticket *models.Ticket, stepConfig map[string]*models.StepConfig) (*models.StepConfig, error) {
+	step, err := checkStep(ticket, stepConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// todo: 已经操作过的人是否可以 reject？
+	if utils.Contain(ticket.OperatedUser, operator) || utils.Contain(ticket.RejectedUser, operator) {
+		return nil, ErrAlreadySigned
+	}
+
+	if !utils.Contain(ticket.Operator, operator) {
+		return step, ErrOperatorNotInOperatorList
+	}
+	return step, nil
+}
+
+func updateTicket(ticket *models.Ticket, nextStep *models.StepConfig, endStep []string) *models.Ticket {
+	// 保留最近一次计票结果，再次进入同一步骤时重新计票
+	if ticket.Tally != nil && ticket.Tally.Step == nextStep.Step {
+		ticket.Tally = nil
+	}
+	ticket.Visit(ticket.Step, nextStep.Step)
 	ticket.Step = nextStep.Step
+	ticket.State = nextStep.State
 	endStepSet := set.Setify(endStep...)
 	if endStepSet.HasKey(nextStep.Step) {
 		ticket.Operator = nil
 		ticket.OperatedUser = nil
-		ticket.Status = models.Passed
+		ticket.RejectedUser = nil
+		ticket.Status = nextStep.TerminalStatus()
 	} else {
-		ticket.Operator = nextStep.Operator
+		// 复制预设操作人，避免后续修改工单时影响模板
+		ticket.Operator = append([]string(nil), nextStep.Operator...)
 		ticket.OperatedUser = nil
+		ticket.RejectedUser = nil
 	}
 	return ticket
 }
 
-func jointlySignUpdater(operator string, ticket *models.Ticket, jointlySignRate float32, nextStep *models.StepConfig, endStep []string) *models.Ticket {
+// 以下updater均返回新工单，不修改传入的工单
+func jointlySignUpdater(operator, operation string, ticket *models.Ticket, step, nextStep *models.StepConfig, stepConfig map[string]*models.StepConfig, endStep []string) *models.Ticket {
+	ticket = ticket.Clone()
+	disposal := step.GetDisposal()
 	userSet := set.Setify(ticket.OperatedUser...)
 	userSet.Set(ticket.Operator...)
-	passRate := float32(1+len(ticket.OperatedUser)) / float32(userSet.Len())
-	if passRate < jointlySignRate {
-		ticket.Operator = utils.RemoveItemByValue(ticket.Operator, operator)
-		ticket.OperatedUser = append(ticket.OperatedUser, operator)
-	} else {
-		ticket = updateTicket(ticket, nextStep, endStep)
+	userSet.Set(ticket.RejectedUser...)
+	userSet.Set(operator)
+	total := userSet.Len()
+	recordVote(ticket, step, nextStep, operator, operation, 1)
+	ticket.Operator = utils.RemoveItemByValue(ticket.Operator, operator)
+	if operation == models.Reject {
+		// 一票否决，或剩余操作人全部同意也无法达到阈值时提前驳回
+		if disposal.Veto || !reachThreshold(len(ticket.OperatedUser)+len(ticket.Operator), total, disposal) {
+			return updateTicket(ticket, nextStep, endStep)
+		}
+		ticket.RejectedUser = append(ticket.RejectedUser, operator)
+		return ticket
+	}
+	if reachThreshold(len(ticket.OperatedUser)+1, total, disposal) {
+		return updateTicket(ticket, resolveNext(ticket.Tally, step, nextStep, stepConfig), endStep)
+	}
+	ticket.OperatedUser = append(ticket.OperatedUser, operator)
+	if !reachThreshold(len(ticket.OperatedUser)+len(ticket.Operator), total, disposal) {
+		return rejectUnreachable(ticket, step, stepConfig, endStep)
 	}
 	return ticket
 }
 
-func serialSignUpdater(operator string, ticket *models.Ticket, _ float32, nextStep *models.StepConfig, endStep []string) *models.Ticket {
+// reachThreshold 同意人数是否达到会签阈值，比例使用精确的有理数比较
+func reachThreshold(approved, total int, disposal models.Disposal) bool {
+	if disposal.JointSignCount > 0 {
+		return approved >= disposal.JointSignCount
+	}
+	if total == 0 {
+		return true
+	}
+	// 按float32的最短十进制表示解析，0.3即3/10，避免float32误差
+	rate, ok := new(big.Rat).SetString(strconv.FormatFloat(float64(disposal.JointSignRate), 'f', -1, 32))
+	if !ok {
+		return false
+	}
+	return big.NewRat(int64(approved), int64(total)).Cmp(rate) >= 0
+}
+
+func serialSignUpdater(operator, operation string, ticket *models.Ticket, step, nextStep *models.StepConfig, stepConfig map[string]*models.StepConfig, endStep []string) *models.Ticket {
+	ticket = ticket.Clone()
+	recordVote(ticket, step, nextStep, operator, operation, 1)
 	ticket.Operator = utils.RemoveItemByValue(ticket.Operator, operator)
 	if len(ticket.Operator) != 0 {
 		ticket.OperatedUser = append(ticket.OperatedUser, operator)
 	} else {
-		ticket = updateTicket(ticket, nextStep, endStep)
+		ticket = updateTicket(ticket, resolveNext(ticket.Tally, step, nextStep, stepConfig), endStep)
 	}
 	return ticket
 }
 
-func anyoneSignUpdater(_ string, ticket *models.Ticket, _ float32, nextStep *models.StepConfig, endStep []string) *models.Ticket {
-	return updateTicket(ticket, nextStep, endStep)
+func anyoneSignUpdater(_, _ string, ticket *models.Ticket, _, nextStep *models.StepConfig, _ map[string]*models.StepConfig, endStep []string) *models.Ticket {
+	return updateTicket(ticket.Clone(), nextStep, endStep)
+}
+
+func weightedSignUpdater(operator, operation string, ticket *models.Ticket, step, nextStep *models.StepConfig, stepConfig map[string]*models.StepConfig, endStep []string) *models.Ticket {
+	ticket = ticket.Clone()
+	recordVote(ticket, step, nextStep, operator, operation, step.WeightOf(operator))
+	ticket.Operator = utils.RemoveItemByValue(ticket.Operator, operator)
+	// 剩余操作人全部同意也无法达到阈值时驳回
+	remaining := 0
+	for _, o := range ticket.Operator {
+		remaining += step.WeightOf(o)
+	}
+	reachable := ticket.Tally.Approved+remaining >= ticket.Tally.Threshold
+	if operation == models.Reject {
+		if !reachable {
+			return updateTicket(ticket, nextStep, endStep)
+		}
+		ticket.RejectedUser = append(ticket.RejectedUser, operator)
+		return ticket
+	}
+	if ticket.Tally.Approved >= ticket.Tally.Threshold {
+		return updateTicket(ticket, resolveNext(ticket.Tally, step, nextStep, stepConfig), endStep)
+	}
+	ticket.OperatedUser = append(ticket.OperatedUser, operator)
+	if !reachable {
+		return rejectUnreachable(ticket, step, stepConfig, endStep)
+	}
+	return ticket
 }
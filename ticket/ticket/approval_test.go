@@ -1,10 +1,13 @@
 package ticket
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/victorwong171/punched-tape/models"
+	"github.com/victorwong171/punched-tape/ticket/form"
 )
 
 func TestHelper_Approval(t *testing.T) {
@@ -62,8 +65,18 @@ func TestHelper_Approval(t *testing.T) {
 				},
 			},
 			want: &models.Ticket{
-				Status:   models.Running,
-				Operator: []string{"user"},
+				Status:     models.Running,
+				Operator:   []string{"user"},
+				Visits:     map[string]int{"": 1},
+				Traversals: map[string]int{"a->": 1},
+				History: []*models.Action{
+					{
+						Operator:  "user",
+						Operation: "submit",
+						Step:      "a",
+						Next:      "b",
+					},
+				},
 			},
 			wantErr: false,
 		},
@@ -321,7 +334,7 @@ func TestHelper_Approval(t *testing.T) {
 				t.Errorf("Approval() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if diff := cmp.Diff(got, tt.want); len(diff) > 0 {
+			if diff := cmp.Diff(got, tt.want, cmpopts.IgnoreFields(models.Action{}, "CreatedAt")); len(diff) > 0 {
 				t.Errorf("Approval() diff = %v", diff)
 			}
 		})
@@ -356,8 +369,11 @@ func Test_jointlySignUpdater(t *testing.T) {
 				endStep: []string{"end"},
 			},
 			want: &models.Ticket{
-				Status: models.Passed,
-				Step:   "end",
+				Status:     models.Passed,
+				Step:       "end",
+				Tally:      &models.Tally{Approved: 1, Votes: []*models.Vote{{Operator: "a", Next: "end", Weight: 1}}},
+				Visits:     map[string]int{"end": 1},
+				Traversals: map[string]int{"->end": 1},
 			},
 		},
 		{
@@ -377,12 +393,13 @@ func Test_jointlySignUpdater(t *testing.T) {
 			want: &models.Ticket{
 				Operator:     []string{"a"},
 				OperatedUser: []string{"c", "b"},
+				Tally:        &models.Tally{Approved: 1, Votes: []*models.Vote{{Operator: "b", Next: "end", Weight: 1}}},
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := jointlySignUpdater(tt.args.operator, tt.args.ticket, tt.args.jointlySignRate, tt.args.nextStep, tt.args.endStep)
+			got := jointlySignUpdater(tt.args.operator, "", tt.args.ticket, &models.StepConfig{Disposal: models.Disposal{JointSignRate: tt.args.jointlySignRate}}, tt.args.nextStep, nil, tt.args.endStep)
 			if diff := cmp.Diff(got, tt.want); len(diff) > 0 {
 				t.Errorf("jointlySignUpdater() diff = %v", diff)
 			}
@@ -416,7 +433,10 @@ func Test_serialSignUpdater(t *testing.T) {
 				},
 			},
 			want: &models.Ticket{
-				Step: "next",
+				Step:       "next",
+				Tally:      &models.Tally{Approved: 1, Votes: []*models.Vote{{Operator: "a", Next: "next", Weight: 1}}},
+				Visits:     map[string]int{"next": 1},
+				Traversals: map[string]int{"->next": 1},
 			},
 		},
 		{
@@ -431,15 +451,519 @@ func Test_serialSignUpdater(t *testing.T) {
 			want: &models.Ticket{
 				Operator:     []string{"c"},
 				OperatedUser: []string{"b", "a"},
+				Tally:        &models.Tally{Approved: 1, Votes: []*models.Vote{{Operator: "a", Weight: 1}}},
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := serialSignUpdater(tt.args.operator, tt.args.ticket, tt.args.in2, tt.args.nextStep, tt.args.endStep)
+			got := serialSignUpdater(tt.args.operator, "", tt.args.ticket, &models.StepConfig{Disposal: models.Disposal{JointSignRate: tt.args.in2}}, tt.args.nextStep, nil, tt.args.endStep)
 			if diff := cmp.Diff(got, tt.want); len(diff) > 0 {
 				t.Errorf("serialSignUpdater() diff = %v", diff)
 			}
 		})
 	}
 }
+
+func TestHelper_ApprovalWithOptions(t *testing.T) {
+	stepConfig := map[string]*models.StepConfig{
+		"a": {
+			Step:     "a",
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Editable: []string{"amount"},
+			Hidden:   []string{"note"},
+			Next: []*models.NextStep{
+				{Operation: "submit", Step: "b"},
+				{Operation: models.Reject, Step: "end"},
+			},
+		},
+		"b": {
+			Step:     "b",
+			Operator: []string{"boss"},
+			Next:     []*models.NextStep{{Operation: "pass", Step: "end"}},
+		},
+		"end": {Step: "end"},
+	}
+	attachment := &models.Attachment{Name: "invoice.pdf", Size: 3, Hash: "sha256:abc", URI: "file:///tmp/abc"}
+	schema := []*models.FormField{
+		{Name: "reason", Type: models.FieldString, Required: true},
+		{Name: "amount", Type: models.FieldNumber, Required: true},
+		{Name: "note", Type: models.FieldString},
+	}
+	formData := map[string]any{"reason": "trip", "amount": 100, "note": "vip"}
+	tests := []struct {
+		name      string
+		operation string
+		next      string
+		opts      []ApprovalOption
+		want      []*models.Action
+		wantForm  map[string]any
+		wantErr   error
+	}{
+		{
+			name:      "approve with comment and attachments",
+			operation: "submit",
+			next:      "b",
+			opts:      []ApprovalOption{WithComment("looks good"), WithAttachments(attachment)},
+			want: []*models.Action{
+				{Operator: "user", Operation: "submit", Step: "a", Next: "b", Comment: "looks good", Attachments: []*models.Attachment{attachment}},
+			},
+		},
+		{
+			name:      "reject with comment",
+			operation: models.Reject,
+			next:      "end",
+			opts:      []ApprovalOption{WithComment("missing invoice")},
+			want: []*models.Action{
+				{Operator: "user", Operation: models.Reject, Step: "a", Next: "end", Comment: "missing invoice"},
+			},
+		},
+		{
+			name:      "bad attachment",
+			operation: "submit",
+			next:      "b",
+			opts:      []ApprovalOption{WithAttachments(&models.Attachment{Name: "no-uri"})},
+			wantErr:   ErrBadArguments,
+		},
+		{
+			name:      "nil attachment",
+			operation: "submit",
+			next:      "b",
+			opts:      []ApprovalOption{WithAttachments(nil)},
+			wantErr:   ErrBadArguments,
+		},
+		{
+			name:      "edit editable form field",
+			operation: "submit",
+			next:      "b",
+			opts:      []ApprovalOption{WithFormData(schema, map[string]any{"reason": "trip", "amount": 80})},
+			want: []*models.Action{
+				{Operator: "user", Operation: "submit", Step: "a", Next: "b"},
+			},
+			wantForm: map[string]any{"reason": "trip", "amount": 80, "note": "vip"},
+		},
+		{
+			name:      "edit visible form data",
+			operation: "submit",
+			next:      "b",
+			opts: []ApprovalOption{WithFormData(schema, func() map[string]any {
+				data := form.Visible(stepConfig["a"], formData)
+				data["amount"] = 90
+				return data
+			}())},
+			want: []*models.Action{
+				{Operator: "user", Operation: "submit", Step: "a", Next: "b"},
+			},
+			wantForm: map[string]any{"reason": "trip", "amount": 90, "note": "vip"},
+		},
+		{
+			name:      "hidden form field keeps original value",
+			operation: "submit",
+			next:      "b",
+			opts:      []ApprovalOption{WithFormData(schema, map[string]any{"reason": "trip", "amount": 100, "note": "forged"})},
+			want: []*models.Action{
+				{Operator: "user", Operation: "submit", Step: "a", Next: "b"},
+			},
+		},
+		{
+			name:      "edit read-only form field",
+			operation: "submit",
+			next:      "b",
+			opts:      []ApprovalOption{WithFormData(schema, map[string]any{"reason": "holiday", "amount": 100})},
+			wantErr:   ErrBadFormData,
+		},
+		{
+			name:      "form data fails schema",
+			operation: "submit",
+			next:      "b",
+			opts:      []ApprovalOption{WithFormData(schema, map[string]any{"reason": "trip", "amount": "many"})},
+			wantErr:   form.ErrFieldType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticket := &models.Ticket{Status: models.Running, Step: "a", Operator: []string{"user"}, FormData: formData}
+			h := &Helper{}
+			got, err := h.Approval(tt.next, tt.operation, "user", false, []string{"end"}, ticket, stepConfig, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Approval() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(got.History, tt.want, cmpopts.IgnoreFields(models.Action{}, "CreatedAt")); len(diff) > 0 {
+				t.Errorf("Approval() history diff = %v", diff)
+			}
+			if got.History[0].CreatedAt.IsZero() {
+				t.Errorf("Approval() history CreatedAt is zero")
+			}
+			if tt.wantForm == nil {
+				tt.wantForm = formData
+			}
+			if diff := cmp.Diff(got.FormData, tt.wantForm); len(diff) > 0 {
+				t.Errorf("Approval() form data diff = %v", diff)
+			}
+		})
+	}
+}
+
+func TestHelper_Transit(t *testing.T) {
+	stepConfig := map[string]*models.StepConfig{
+		"a": {
+			Step:     "a",
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "submit", Step: "b"}},
+		},
+		"b": {
+			Step:     "b",
+			Operator: []string{"u2", "u3"},
+			Disposal: models.Disposal{SignType: models.SerialSign},
+			Next:     []*models.NextStep{{Operation: "approve", Step: "end"}},
+		},
+		"end": {Step: "end"},
+	}
+	ticket := &models.Ticket{Status: models.Running, Step: "a", Operator: []string{"u1"}}
+	before := ticket.Clone()
+	h := &Helper{}
+
+	tr, err := h.Transit("b", "submit", "u1", false, []string{"end"}, ticket, stepConfig)
+	if err != nil {
+		t.Fatalf("Transit() error = %v", err)
+	}
+	if diff := cmp.Diff(ticket, before); len(diff) > 0 {
+		t.Errorf("Transit() mutated input ticket, diff = %v", diff)
+	}
+	if diff := cmp.Diff(tr.Previous, before); len(diff) > 0 {
+		t.Errorf("Transit() previous diff = %v", diff)
+	}
+	if tr.From != "a" || tr.To != "b" || tr.Ticket.Step != "b" {
+		t.Errorf("Transit() = %+v", tr)
+	}
+
+	next, err := h.Approval("end", "approve", "u2", false, []string{"end"}, tr.Ticket, stepConfig)
+	if err != nil {
+		t.Fatalf("Approval() error = %v", err)
+	}
+	if diff := cmp.Diff(next.Operator, []string{"u3"}); len(diff) > 0 {
+		t.Errorf("Approval() operator diff = %v", diff)
+	}
+	if diff := cmp.Diff(tr.Ticket.Operator, []string{"u2", "u3"}); len(diff) > 0 {
+		t.Errorf("Approval() mutated previous ticket, diff = %v", diff)
+	}
+	if diff := cmp.Diff(stepConfig["b"].Operator, []string{"u2", "u3"}); len(diff) > 0 {
+		t.Errorf("Approval() mutated step config, diff = %v", diff)
+	}
+
+	if _, err = h.Transit("b", "submit", "u1", false, []string{"end"}, nil, stepConfig); !errors.Is(err, ErrBadArguments) {
+		t.Errorf("Transit() error = %v, want %v", err, ErrBadArguments)
+	}
+}
+
+func TestHelper_Preview(t *testing.T) {
+	stepConfig := map[string]*models.StepConfig{
+		"a": {
+			Step:     "a",
+			Disposal: models.Disposal{SignType: models.JointlySign, JointSignRate: 0.5},
+			Next:     []*models.NextStep{{Operation: "submit", Step: "finance"}},
+		},
+		"finance": {Step: "finance", Operator: []string{"x", "y"}},
+	}
+	tests := []struct {
+		name     string
+		ticket   *models.Ticket
+		operator string
+		wantTo   string
+		wantOps  []string
+		wantEvts []string
+		wantErr  error
+	}{
+		{
+			name:     "signed only",
+			ticket:   &models.Ticket{Status: models.Running, Step: "a", Operator: []string{"u1", "u2", "u3"}},
+			operator: "u1",
+			wantTo:   "a",
+			wantOps:  []string{"u2", "u3"},
+			wantEvts: []string{models.EventSigned},
+		},
+		{
+			name:     "rate reached",
+			ticket:   &models.Ticket{Status: models.Running, Step: "a", Operator: []string{"u2", "u3"}, OperatedUser: []string{"u1"}},
+			operator: "u2",
+			wantTo:   "finance",
+			wantOps:  []string{"x", "y"},
+			wantEvts: []string{models.EventStepLeft, models.EventStepEntered},
+		},
+		{
+			name:     "not operator",
+			ticket:   &models.Ticket{Status: models.Running, Step: "a", Operator: []string{"u1"}},
+			operator: "u9",
+			wantErr:  ErrOperatorNotInOperatorList,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var emitted int
+			h := &Helper{}
+			h.Register(ListenerFunc(func(*models.Transition) { emitted++ }))
+			before := tt.ticket.Clone()
+			got, err := h.Preview("finance", "submit", tt.operator, false, nil, tt.ticket, stepConfig)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Preview() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.ticket, before); len(diff) > 0 {
+				t.Errorf("Preview() mutated ticket, diff = %v", diff)
+			}
+			if emitted != 0 {
+				t.Errorf("Preview() emitted %d transitions, want 0", emitted)
+			}
+			if err != nil {
+				return
+			}
+			if got.To != tt.wantTo {
+				t.Errorf("Preview() to = %v, want %v", got.To, tt.wantTo)
+			}
+			if diff := cmp.Diff(got.Ticket.Operator, tt.wantOps); len(diff) > 0 {
+				t.Errorf("Preview() operator diff = %v", diff)
+			}
+			if diff := cmp.Diff(got.Events, tt.wantEvts); len(diff) > 0 {
+				t.Errorf("Preview() events diff = %v", diff)
+			}
+		})
+	}
+}
+
+func Test_jointlySignUpdater_Threshold(t *testing.T) {
+	nextStep := &models.StepConfig{Step: "next", Operator: []string{"n1"}}
+	rejectStep := &models.StepConfig{Step: "back", Operator: []string{"applicant"}}
+	tests := []struct {
+		name       string
+		operator   string
+		operation  string
+		ticket     *models.Ticket
+		disposal   models.Disposal
+		next       []*models.NextStep
+		stepConfig map[string]*models.StepConfig
+		nextStep   *models.StepConfig
+		want       *models.Ticket
+	}{
+		{
+			name:     "rate 0.3 reached exactly",
+			operator: "u3",
+			ticket: &models.Ticket{
+				Step:         "a",
+				Operator:     []string{"u3", "u4", "u5", "u6", "u7", "u8", "u9", "u10"},
+				OperatedUser: []string{"u1", "u2"},
+			},
+			disposal: models.Disposal{JointSignRate: 0.3},
+			nextStep: nextStep,
+			want:     &models.Ticket{Step: "next", Operator: []string{"n1"}},
+		},
+		{
+			name:     "count not reached",
+			operator: "u1",
+			ticket:   &models.Ticket{Step: "a", Operator: []string{"u1", "u2", "u3"}},
+			disposal: models.Disposal{JointSignRate: 0.1, JointSignCount: 2},
+			nextStep: nextStep,
+			want:     &models.Ticket{Step: "a", Operator: []string{"u2", "u3"}, OperatedUser: []string{"u1"}},
+		},
+		{
+			name:     "count reached",
+			operator: "u2",
+			ticket:   &models.Ticket{Step: "a", Operator: []string{"u2", "u3"}, OperatedUser: []string{"u1"}},
+			disposal: models.Disposal{JointSignCount: 2},
+			nextStep: nextStep,
+			want:     &models.Ticket{Step: "next", Operator: []string{"n1"}},
+		},
+		{
+			name:      "veto",
+			operator:  "u1",
+			operation: models.Reject,
+			ticket:    &models.Ticket{Step: "a", Operator: []string{"u1", "u2", "u3"}},
+			disposal:  models.Disposal{JointSignCount: 1, Veto: true},
+			nextStep:  rejectStep,
+			want:      &models.Ticket{Step: "back", Operator: []string{"applicant"}},
+		},
+		{
+			name:      "reject still reachable",
+			operator:  "u1",
+			operation: models.Reject,
+			ticket:    &models.Ticket{Step: "a", Operator: []string{"u1", "u2", "u3"}},
+			disposal:  models.Disposal{JointSignCount: 2},
+			nextStep:  rejectStep,
+			want:      &models.Ticket{Step: "a", Operator: []string{"u2", "u3"}, RejectedUser: []string{"u1"}},
+		},
+		{
+			name:      "early rejection",
+			operator:  "u2",
+			operation: models.Reject,
+			ticket:    &models.Ticket{Step: "a", Operator: []string{"u2", "u3"}, RejectedUser: []string{"u1"}},
+			disposal:  models.Disposal{JointSignCount: 2},
+			nextStep:  rejectStep,
+			want:      &models.Ticket{Step: "back", Operator: []string{"applicant"}},
+		},
+		{
+			name:      "early rejection by rate",
+			operator:  "u1",
+			operation: models.Reject,
+			ticket:    &models.Ticket{Step: "a", Operator: []string{"u1", "u2", "u3", "u4"}},
+			disposal:  models.Disposal{JointSignRate: 0.8},
+			nextStep:  rejectStep,
+			want:      &models.Ticket{Step: "back", Operator: []string{"applicant"}},
+		},
+		{
+			name:     "approval cannot reach count",
+			operator: "u1",
+			ticket:   &models.Ticket{Step: "a", Status: models.Running, Operator: []string{"u1", "u2"}},
+			disposal: models.Disposal{JointSignCount: 3},
+			nextStep: nextStep,
+			want:     &models.Ticket{Step: "a", Status: models.Rejected},
+		},
+		{
+			name:       "approval cannot reach count with reject edge",
+			operator:   "u1",
+			ticket:     &models.Ticket{Step: "a", Status: models.Running, Operator: []string{"u1", "u2"}},
+			disposal:   models.Disposal{JointSignCount: 3},
+			next:       []*models.NextStep{{Operation: "approve", Step: "next"}, {Operation: models.Reject, Step: "back"}},
+			stepConfig: map[string]*models.StepConfig{"next": nextStep, "back": rejectStep},
+			nextStep:   nextStep,
+			want:       &models.Ticket{Step: "back", Status: models.Running, Operator: []string{"applicant"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := tt.ticket.Clone()
+			step := &models.StepConfig{Step: "a", Disposal: tt.disposal, Next: tt.next}
+			got := jointlySignUpdater(tt.operator, tt.operation, tt.ticket, step, tt.nextStep, tt.stepConfig, []string{"end"})
+			if diff := cmp.Diff(got, tt.want, cmpopts.EquateEmpty(), cmpopts.IgnoreFields(models.Ticket{}, "Tally", "Visits", "Traversals")); len(diff) > 0 {
+				t.Errorf("jointlySignUpdater() diff = %v", diff)
+			}
+			if diff := cmp.Diff(tt.ticket, before); len(diff) > 0 {
+				t.Errorf("jointlySignUpdater() mutated input, diff = %v", diff)
+			}
+		})
+	}
+}
+
+func Test_reachThreshold(t *testing.T) {
+	tests := []struct {
+		name     string
+		approved int
+		total    int
+		disposal models.Disposal
+		want     bool
+	}{
+		{name: "0.3 of 10", approved: 3, total: 10, disposal: models.Disposal{JointSignRate: 0.3}, want: true},
+		{name: "below 0.3", approved: 2, total: 10, disposal: models.Disposal{JointSignRate: 0.3}, want: false},
+		{name: "0.7 of 10", approved: 7, total: 10, disposal: models.Disposal{JointSignRate: 0.7}, want: true},
+		{name: "count wins", approved: 1, total: 10, disposal: models.Disposal{JointSignRate: 0.9, JointSignCount: 1}, want: true},
+		{name: "no operators", approved: 0, total: 0, disposal: models.Disposal{JointSignRate: 1}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reachThreshold(tt.approved, tt.total, tt.disposal); got != tt.want {
+				t.Errorf("reachThreshold() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_weightedSignUpdater(t *testing.T) {
+	step := &models.StepConfig{
+		Step:     "board",
+		Operator: []string{"chair", "m1", "m2"},
+		Disposal: models.Disposal{SignType: models.WeightedSign, WeightThreshold: 3},
+		Weights:  map[string]int{"chair": 2},
+	}
+	nextStep := &models.StepConfig{Step: "end"}
+	rejectStep := &models.StepConfig{Step: "back", Operator: []string{"applicant"}}
+	tests := []struct {
+		name      string
+		operator  string
+		operation string
+		ticket    *models.Ticket
+		nextStep  *models.StepConfig
+		want      *models.Ticket
+	}{
+		{
+			name:     "chair signs",
+			operator: "chair",
+			ticket:   &models.Ticket{Step: "board", Status: models.Running, Operator: []string{"chair", "m1", "m2"}},
+			nextStep: nextStep,
+			want: &models.Ticket{
+				Step: "board", Status: models.Running, Operator: []string{"m1", "m2"}, OperatedUser: []string{"chair"},
+				Tally: &models.Tally{Step: "board", Threshold: 3, Approved: 2, Votes: []*models.Vote{
+					{Operator: "chair", Next: "end", Weight: 2},
+				}},
+			},
+		},
+		{
+			name:     "threshold reached",
+			operator: "m1",
+			ticket: &models.Ticket{
+				Step: "board", Status: models.Running, Operator: []string{"m1", "m2"}, OperatedUser: []string{"chair"},
+				Tally: &models.Tally{Step: "board", Threshold: 3, Approved: 2, Votes: []*models.Vote{
+					{Operator: "chair", Next: "end", Weight: 2},
+				}},
+			},
+			nextStep: nextStep,
+			want: &models.Ticket{
+				Step: "end", Status: models.Passed,
+				Visits:     map[string]int{"end": 1},
+				Traversals: map[string]int{"board->end": 1},
+				Tally: &models.Tally{Step: "board", Threshold: 3, Approved: 3, Votes: []*models.Vote{
+					{Operator: "chair", Next: "end", Weight: 2},
+					{Operator: "m1", Next: "end", Weight: 1},
+				}},
+			},
+		},
+		{
+			name:      "chair rejects",
+			operator:  "chair",
+			operation: models.Reject,
+			ticket:    &models.Ticket{Step: "board", Status: models.Running, Operator: []string{"chair", "m1", "m2"}},
+			nextStep:  rejectStep,
+			want: &models.Ticket{
+				Step: "back", Status: models.Running, Operator: []string{"applicant"},
+				Visits:     map[string]int{"back": 1},
+				Traversals: map[string]int{"board->back": 1},
+				Tally: &models.Tally{Step: "board", Threshold: 3, Rejected: 2, Votes: []*models.Vote{
+					{Operator: "chair", Operation: models.Reject, Next: "back", Weight: 2},
+				}},
+			},
+		},
+		{
+			name:      "member rejects",
+			operator:  "m1",
+			operation: models.Reject,
+			ticket:    &models.Ticket{Step: "board", Status: models.Running, Operator: []string{"chair", "m1", "m2"}},
+			nextStep:  rejectStep,
+			want: &models.Ticket{
+				Step: "board", Status: models.Running, Operator: []string{"chair", "m2"}, RejectedUser: []string{"m1"},
+				Tally: &models.Tally{Step: "board", Threshold: 3, Rejected: 1, Votes: []*models.Vote{
+					{Operator: "m1", Operation: models.Reject, Next: "back", Weight: 1},
+				}},
+			},
+		},
+		{
+			name:     "remaining weights below threshold",
+			operator: "m1",
+			ticket:   &models.Ticket{Step: "board", Status: models.Running, Operator: []string{"m1", "m2"}},
+			nextStep: nextStep,
+			want: &models.Ticket{
+				Step: "board", Status: models.Rejected,
+				Tally: &models.Tally{Step: "board", Threshold: 3, Approved: 1, Votes: []*models.Vote{
+					{Operator: "m1", Next: "end", Weight: 1},
+				}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := weightedSignUpdater(tt.operator, tt.operation, tt.ticket, step, tt.nextStep, nil, []string{"end"})
+			if diff := cmp.Diff(got, tt.want, cmpopts.EquateEmpty()); len(diff) > 0 {
+				t.Errorf("weightedSignUpdater() diff = %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package ticket
+
+import (
+	"errors"
+
+	"github.com/victorwong171/punched-tape/models"
+
+	"github.com/victorwong171/go-utils/desc/set"
+	"github.com/victorwong171/go-utils/utils"
+)
+
+// Authorizer 判定操作人能否在工单当前步骤上使用管理员能力，capability取值见models.Cap*
+// 引擎仅在操作人不在处理人列表中或执行管理操作时调用
+type Authorizer interface {
+	Authorize(actor, capability string, ticket *models.Ticket, step *models.StepConfig, operation string) bool
+}
+
+// AuthorizerFunc 函数形式的Authorizer
+type AuthorizerFunc func(actor, capability string, ticket *models.Ticket, step *models.StepConfig, operation string) bool
+
+func (f AuthorizerFunc) Authorize(actor, capability string, ticket *models.Ticket, step *models.StepConfig, operation string) bool {
+	return f(actor, capability, ticket, step, operation)
+}
+
+// Capabilities 按用户授予管理员能力，key为用户，value为能力列表
+type Capabilities map[string][]string
+
+func (c Capabilities) Authorize(actor, capability string, _ *models.Ticket, _ *models.StepConfig, _ string) bool {
+	return set.Setify(c[actor]...).HasKey(capability)
+}
+
+// SetAuthorizer 设置管理员能力判定，未设置时只有Approval的admin参数为true才能越权操作
+func (h *Helper) SetAuthorizer(authorizer Authorizer) {
+	h.authorizer = authorizer
+}
+
+// authorize 校验操作人权限，操作人不在处理人列表中时按操作类型申请越权能力，返回使用的能力
+// admin为true时视为拥有全部能力
+func (h *Helper) authorize(operator, operation string, admin bool, ticket *models.Ticket, stepConfig map[string]*models.StepConfig) (*models.StepConfig, string, error) {
+	step, err := checkOperator(operator, ticket, stepConfig)
+	if !errors.Is(err, ErrOperatorNotInOperatorList) {
+		return step, "", err
+	}
+	capability := utils.TernaryOperator(operation == models.Reject, models.CapForceReject, models.CapForceApprove)
+	if err = h.override(operator, capability, admin, ticket, step, operation); err != nil {
+		// 普通用户审批时不提示能力不足，与未配置越权时的错误保持一致
+		if errors.Is(err, ErrCapabilityDenied) {
+			err = ErrOperatorNotInOperatorList
+		}
+		return nil, "", err
+	}
+	return step, capability, nil
+}
+
+// override 校验管理员能力，步骤禁止越权时即使拥有能力也不允许
+// step为nil时（当前步骤已不在模板中）不检查NoOverride，允许管理员将工单移出
+func (h *Helper) override(actor, capability string, admin bool, ticket *models.Ticket, step *models.StepConfig, operation string) error {
+	if step != nil && step.NoOverride {
+		return ErrOverrideForbidden
+	}
+	if admin || (h.authorizer != nil && h.authorizer.Authorize(actor, capability, ticket, step, operation)) {
+		return nil
+	}
+	return ErrCapabilityDenied
+}
+
+// Reassign 更换工单当前步骤的待处理人，已签署记录保留；需要CapReassign能力
+func (h *Helper) Reassign(
+	actor string,
+	operators []string,
+	ticket *models.Ticket,
+	stepConfig map[string]*models.StepConfig,
+	opts ...ApprovalOption) (*models.Transition, error) {
+	if ticket == nil {
+		return nil, newApprovalError(ErrMissingArguments, "", "", actor, models.Reassign, "")
+	}
+	fail := func(err error) (*models.Transition, error) {
+		return nil, newApprovalError(err, ticket.Uid, ticket.Step, actor, models.Reassign, ticket.Step)
+	}
+	if len(actor) == 0 || len(operators) == 0 {
+		return fail(ErrMissingArguments)
+	}
+	step, err := checkStep(ticket, stepConfig)
+	if err != nil {
+		return fail(err)
+	}
+	if err = h.override(actor, models.CapReassign, false, ticket, step, models.Reassign); err != nil {
+		return fail(err)
+	}
+	options := newApprovalOptions(opts)
+
+	updated := ticket.Clone()
+	updated.Operator = make([]string, 0, len(operators))
+	for _, o := range operators {
+		if !utils.Contain(updated.Operator, o) {
+			updated.Operator = append(updated.Operator, o)
+		}
+	}
+	updated.AddCC(options.cc...)
+	action := &models.Action{
+		Operator:  actor,
+		Operation: models.Reassign,
+		Step:      ticket.Step,
+		Next:      ticket.Step,
+		Comment:   options.comment,
+		CreatedAt: options.at,
+		Override:  models.CapReassign,
+	}
+	updated.History = append(updated.History, action)
+	tr := &models.Transition{
+		Ticket:   updated,
+		Previous: ticket.Clone(),
+		From:     ticket.Step,
+		To:       ticket.Step,
+		Action:   action.Clone(),
+		Events:   []string{models.EventReassigned, models.EventOverridden},
+		CC:       append([]string(nil), options.cc...),
+	}
+	h.notify(tr, options)
+	return tr, nil
+}
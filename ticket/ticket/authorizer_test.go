@@ -0,0 +1,182 @@
+package ticket
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/victorwong171/go-utils/utils"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func TestHelper_Approval_Authorizer(t *testing.T) {
+	tests := []struct {
+		name       string
+		capability []string
+		operator   string
+		admin      bool
+		noOverride bool
+		operation  string
+		wantErr    error
+		override   string
+	}{
+		{
+			name:      "no capability",
+			operation: "approve",
+			wantErr:   ErrOperatorNotInOperatorList,
+		},
+		{
+			name:       "force approve",
+			capability: []string{models.CapForceApprove},
+			operation:  "approve",
+			override:   models.CapForceApprove,
+		},
+		{
+			name:       "force reject only",
+			capability: []string{models.CapForceReject},
+			operation:  "approve",
+			wantErr:    ErrOperatorNotInOperatorList,
+		},
+		{
+			name:       "force reject",
+			capability: []string{models.CapForceReject},
+			operation:  models.Reject,
+			override:   models.CapForceReject,
+		},
+		{
+			name:      "legacy admin flag",
+			admin:     true,
+			operation: "approve",
+			override:  models.CapForceApprove,
+		},
+		{
+			name:       "override forbidden",
+			capability: []string{models.CapForceApprove},
+			admin:      true,
+			noOverride: true,
+			operation:  "approve",
+			wantErr:    ErrOverrideForbidden,
+		},
+		{
+			name:       "operator is not overridden",
+			operator:   "u1",
+			admin:      true,
+			noOverride: true,
+			operation:  "approve",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stepConfig := map[string]*models.StepConfig{
+				"a": {
+					Step:       "a",
+					Operator:   []string{"u1"},
+					Disposal:   models.Disposal{SignType: models.AnyoneSign},
+					NoOverride: tt.noOverride,
+					Next: []*models.NextStep{
+						{Operation: "approve", Step: "end"},
+						{Operation: models.Reject, Step: "end"},
+					},
+				},
+				"end": {Step: "end"},
+			}
+			operator := utils.TernaryOperator(len(tt.operator) > 0, tt.operator, "boss")
+			h := &Helper{}
+			h.SetAuthorizer(Capabilities{"boss": tt.capability})
+			ticket := &models.Ticket{Uid: "t1", Status: models.Running, Step: "a", Operator: []string{"u1"}}
+			tr, err := h.Transit("end", tt.operation, operator, tt.admin, []string{"end"}, ticket, stepConfig)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Transit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if tr.Action.Override != tt.override {
+				t.Errorf("Transit() override = %v, want %v", tr.Action.Override, tt.override)
+			}
+			if tr.HasEvent(models.EventOverridden) != (len(tt.override) > 0) {
+				t.Errorf("Transit() events = %v, override %v", tr.Events, tt.override)
+			}
+		})
+	}
+}
+
+func TestHelper_Reassign(t *testing.T) {
+	tests := []struct {
+		name         string
+		capability   []string
+		noOverride   bool
+		operators    []string
+		wantOperator []string
+		wantErr      error
+	}{
+		{
+			name:      "no capability",
+			operators: []string{"u2"},
+			wantErr:   ErrCapabilityDenied,
+		},
+		{
+			name:       "override forbidden",
+			capability: []string{models.CapReassign},
+			noOverride: true,
+			operators:  []string{"u2"},
+			wantErr:    ErrOverrideForbidden,
+		},
+		{
+			name:       "missing operators",
+			capability: []string{models.CapReassign},
+			wantErr:    ErrMissingArguments,
+		},
+		{
+			name:         "duplicates removed",
+			capability:   []string{models.CapReassign},
+			operators:    []string{"u2", "u3", "u2"},
+			wantOperator: []string{"u2", "u3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stepConfig := map[string]*models.StepConfig{
+				"a": {
+					Step:       "a",
+					Operator:   []string{"u1"},
+					Disposal:   models.Disposal{SignType: models.AnyoneSign},
+					NoOverride: tt.noOverride,
+					Next:       []*models.NextStep{{Operation: "approve", Step: "end"}},
+				},
+				"end": {Step: "end"},
+			}
+			var got []*models.Transition
+			h := &Helper{}
+			h.SetAuthorizer(Capabilities{"boss": tt.capability})
+			h.Register(ListenerFunc(func(tr *models.Transition) { got = append(got, tr) }))
+			ticket := &models.Ticket{Uid: "t1", Status: models.Running, Step: "a", Operator: []string{"u1"}}
+			tr, err := h.Reassign("boss", tt.operators, ticket, stepConfig, WithComment("u1 on leave"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Reassign() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(got) != 0 {
+					t.Errorf("listener received %d transitions on failure, want 0", len(got))
+				}
+				return
+			}
+			if diff := cmp.Diff(tr.Ticket.Operator, tt.wantOperator); len(diff) > 0 {
+				t.Errorf("Reassign() operator diff = %v", diff)
+			}
+			if diff := cmp.Diff(ticket.Operator, []string{"u1"}); len(diff) > 0 {
+				t.Errorf("Reassign() modified input ticket, diff = %v", diff)
+			}
+			last := tr.Ticket.History[len(tr.Ticket.History)-1]
+			if last.Operation != models.Reassign || last.Override != models.CapReassign || last.Comment != "u1 on leave" {
+				t.Errorf("Reassign() action = %+v", last)
+			}
+			if len(got) != 1 || !got[0].HasEvent(models.EventReassigned) {
+				t.Errorf("listener received %+v, want one reassigned transition", got)
+			}
+			if _, err = h.Transit("end", "approve", tt.wantOperator[len(tt.wantOperator)-1], false, []string{"end"}, tr.Ticket, stepConfig); err != nil {
+				t.Errorf("Transit() by new operator error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package ticket
+
+import (
+	"github.com/victorwong171/go-utils/utils"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+// carbonCopy 工单从from步骤流转到to步骤时，按步骤抄送配置追加工单抄送人，返回本次触发的抄送用户与角色
+func carbonCopy(ticket *models.Ticket, from, to *models.StepConfig) (users, roles []string) {
+	if from != nil && from.CC.OnLeave() {
+		users = append(users, from.CC.Users...)
+		roles = append(roles, from.CC.Roles...)
+	}
+	if to != nil && to.CC.OnEnter() {
+		users = append(users, to.CC.Users...)
+		roles = append(roles, to.CC.Roles...)
+	}
+	ticket.AddCC(users...)
+	ticket.AddCCRoles(roles...)
+	return users, roles
+}
+
+// IsCCedTo 工单是否抄送给user，roles为user所属角色
+func IsCCedTo(ticket *models.Ticket, user string, roles ...string) bool {
+	if utils.Contain(ticket.CC, user) {
+		return true
+	}
+	for _, role := range roles {
+		if utils.Contain(ticket.CCRoles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// CCedTo 筛选抄送给user的工单，即"抄送我的"
+func CCedTo(tickets []*models.Ticket, user string, roles ...string) []*models.Ticket {
+	result := make([]*models.Ticket, 0)
+	for _, ticket := range tickets {
+		if ticket != nil && IsCCedTo(ticket, user, roles...) {
+			result = append(result, ticket)
+		}
+	}
+	return result
+}
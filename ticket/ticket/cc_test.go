@@ -0,0 +1,115 @@
+package ticket
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func Test_carbonCopy(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      *models.StepConfig
+		to        *models.StepConfig
+		wantCC    []string
+		wantRoles []string
+	}{
+		{
+			name:   "enter by default",
+			from:   &models.StepConfig{CC: models.CarbonCopy{Users: []string{"a"}}},
+			to:     &models.StepConfig{CC: models.CarbonCopy{Users: []string{"b"}, Roles: []string{"hr"}}},
+			wantCC: []string{"b"}, wantRoles: []string{"hr"},
+		},
+		{
+			name:   "leave",
+			from:   &models.StepConfig{CC: models.CarbonCopy{Users: []string{"a"}, Trigger: models.CCOnLeave}},
+			to:     &models.StepConfig{CC: models.CarbonCopy{Users: []string{"b"}, Trigger: models.CCOnLeave}},
+			wantCC: []string{"a"},
+		},
+		{
+			name:   "both deduplicated",
+			from:   &models.StepConfig{CC: models.CarbonCopy{Users: []string{"a"}, Trigger: models.CCOnBoth}},
+			to:     &models.StepConfig{CC: models.CarbonCopy{Users: []string{"a", "b"}, Trigger: models.CCOnBoth}},
+			wantCC: []string{"a", "b"},
+		},
+		{
+			name: "nil steps",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticket := &models.Ticket{}
+			carbonCopy(ticket, tt.from, tt.to)
+			if diff := cmp.Diff(ticket.CC, tt.wantCC); len(diff) > 0 {
+				t.Errorf("carbonCopy() cc diff = %v", diff)
+			}
+			if diff := cmp.Diff(ticket.CCRoles, tt.wantRoles); len(diff) > 0 {
+				t.Errorf("carbonCopy() roles diff = %v", diff)
+			}
+		})
+	}
+}
+
+func TestCCedTo(t *testing.T) {
+	t1 := &models.Ticket{Uid: "1", CC: []string{"alice"}}
+	t2 := &models.Ticket{Uid: "2", CCRoles: []string{"hr"}}
+	t3 := &models.Ticket{Uid: "3", Operator: []string{"alice"}}
+	tickets := []*models.Ticket{t1, t2, t3, nil}
+	tests := []struct {
+		name  string
+		user  string
+		roles []string
+		want  []*models.Ticket
+	}{
+		{name: "by user", user: "alice", want: []*models.Ticket{t1}},
+		{name: "by role", user: "bob", roles: []string{"hr"}, want: []*models.Ticket{t2}},
+		{name: "by user and role", user: "alice", roles: []string{"hr"}, want: []*models.Ticket{t1, t2}},
+		{name: "none", user: "carol", want: []*models.Ticket{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(CCedTo(tickets, tt.user, tt.roles...), tt.want); len(diff) > 0 {
+				t.Errorf("CCedTo() diff = %v", diff)
+			}
+		})
+	}
+}
+
+func TestHelper_ApprovalCC(t *testing.T) {
+	stepConfig := map[string]*models.StepConfig{
+		"a": {
+			Step:     "a",
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "submit", Step: "b"}},
+			CC:       models.CarbonCopy{Users: []string{"leaver"}, Trigger: models.CCOnLeave},
+		},
+		"b": {
+			Step:     "b",
+			Operator: []string{"boss"},
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "pass", Step: "end"}},
+			CC:       models.CarbonCopy{Users: []string{"watcher"}, Roles: []string{"finance"}},
+		},
+		"end": {Step: "end"},
+	}
+	h := &Helper{}
+	ticket := &models.Ticket{Status: models.Running, Step: "a", Operator: []string{"user"}}
+	got, err := h.Approval("b", "submit", "user", false, []string{"end"}, ticket, stepConfig, WithCC("extra"))
+	if err != nil {
+		t.Fatalf("Approval() error = %v", err)
+	}
+	if diff := cmp.Diff(got.CC, []string{"extra", "leaver", "watcher"}); len(diff) > 0 {
+		t.Errorf("Approval() cc diff = %v", diff)
+	}
+	if diff := cmp.Diff(got.CCRoles, []string{"finance"}); len(diff) > 0 {
+		t.Errorf("Approval() cc roles diff = %v", diff)
+	}
+
+	// 抄送人不获得审批权限
+	_, err = h.Approval("end", "pass", "watcher", false, []string{"end"}, got, stepConfig)
+	if !errors.Is(err, ErrOperatorNotInOperatorList) {
+		t.Errorf("Approval() error = %v, wantErr %v", err, ErrOperatorNotInOperatorList)
+	}
+}
@@ -0,0 +1,177 @@
+package ticket
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrOperatorNotInOperatorList = errors.New("operator not in operator list")
+	ErrAlreadyApproved           = errors.New("already approved")
+	ErrBadArguments              = errors.New("bad arguments")
+	ErrInvalidStep               = errors.New("invalid step")
+)
+
+// 细分错误，均可通过errors.Is匹配到上面的通用错误
+var (
+	ErrMissingArguments   = fmt.Errorf("%w: missing arguments", ErrBadArguments)
+	ErrBadAttachment      = fmt.Errorf("%w: bad attachment", ErrBadArguments)
+	ErrBadFormData        = fmt.Errorf("%w: bad form data", ErrBadArguments)
+	ErrTicketNotRunning   = fmt.Errorf("%w: ticket not running", ErrAlreadyApproved)
+	ErrAlreadySigned      = fmt.Errorf("%w: operator already signed", ErrAlreadyApproved)
+	ErrStepNotFound       = fmt.Errorf("%w: current step not found", ErrInvalidStep)
+	ErrNextStepNotAllowed = fmt.Errorf("%w: next step not allowed", ErrInvalidStep)
+	ErrNextStepNotFound   = fmt.Errorf("%w: next step not found", ErrInvalidStep)
+	ErrBadSignType        = fmt.Errorf("%w: unknown sign type", ErrInvalidStep)
+	ErrWaitingForChild    = fmt.Errorf("%w: step is waiting for child ticket", ErrInvalidStep)
+	ErrNotChild           = fmt.Errorf("%w: not a child of the ticket", ErrBadArguments)
+	ErrChildRunning       = fmt.Errorf("%w: child ticket is still running", ErrBadArguments)
+	ErrSubProcessTemplate = errors.New("sub process template not found")
+	ErrAutomaticStep      = fmt.Errorf("%w: step is executed automatically", ErrInvalidStep)
+	ErrNotServiceStep     = fmt.Errorf("%w: not a service step", ErrInvalidStep)
+	ErrHandlerNotFound    = errors.New("service handler not found")
+	ErrServiceFailed      = errors.New("service handler failed")
+	ErrTooManyServiceHops = errors.New("too many consecutive service steps")
+	ErrOverrideForbidden  = fmt.Errorf("%w: admin override forbidden on step", ErrOperatorNotInOperatorList)
+	ErrCapabilityDenied   = fmt.Errorf("%w: capability not granted", ErrOperatorNotInOperatorList)
+	ErrJumpTargetNotFound = fmt.Errorf("%w: jump target not found", ErrInvalidStep)
+	ErrJumpToCurrentStep  = fmt.Errorf("%w: target is the current step", ErrInvalidStep)
+	ErrNotVisited         = fmt.Errorf("%w: rollback target was not visited", ErrInvalidStep)
+	ErrTicketRunning      = fmt.Errorf("%w: ticket is still running", ErrBadArguments)
+	ErrReopenNotAllowed   = fmt.Errorf("%w: reopen not allowed", ErrOperatorNotInOperatorList)
+	ErrReopenLimit        = fmt.Errorf("%w: reopen limit reached", ErrReopenNotAllowed)
+	ErrBadReopenStep      = fmt.Errorf("%w: bad reopen step", ErrInvalidStep)
+)
+
+// 错误码，供API响应使用，保持稳定
+const (
+	CodeMissingArguments   = "missing_arguments"
+	CodeBadAttachment      = "bad_attachment"
+	CodeBadFormData        = "bad_form_data"
+	CodeTicketNotRunning   = "ticket_not_running"
+	CodeAlreadySigned      = "already_signed"
+	CodeOperatorNotAllowed = "operator_not_allowed"
+	CodeStepNotFound       = "step_not_found"
+	CodeNextStepNotAllowed = "next_step_not_allowed"
+	CodeNextStepNotFound   = "next_step_not_found"
+	CodeBadSignType        = "bad_sign_type"
+	CodeWaitingForChild    = "waiting_for_child"
+	CodeNotChild           = "not_child"
+	CodeChildRunning       = "child_running"
+	CodeSubProcessTemplate = "sub_process_template_not_found"
+	CodeAutomaticStep      = "automatic_step"
+	CodeNotServiceStep     = "not_service_step"
+	CodeHandlerNotFound    = "handler_not_found"
+	CodeServiceFailed      = "service_failed"
+	CodeTooManyServiceHops = "too_many_service_hops"
+	CodeOverrideForbidden  = "override_forbidden"
+	CodeCapabilityDenied   = "capability_denied"
+	CodeJumpTargetNotFound = "jump_target_not_found"
+	CodeJumpToCurrentStep  = "jump_to_current_step"
+	CodeNotVisited         = "not_visited"
+	CodeTicketRunning      = "ticket_running"
+	CodeReopenNotAllowed   = "reopen_not_allowed"
+	CodeReopenLimit        = "reopen_limit"
+	CodeBadReopenStep      = "bad_reopen_step"
+)
+
+// errorCodes 错误与错误码的对应关系，细分错误可能包装其他细分错误，按从具体到通用的顺序匹配
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrReopenLimit, CodeReopenLimit},
+	{ErrReopenNotAllowed, CodeReopenNotAllowed},
+	{ErrOverrideForbidden, CodeOverrideForbidden},
+	{ErrCapabilityDenied, CodeCapabilityDenied},
+	{ErrMissingArguments, CodeMissingArguments},
+	{ErrBadAttachment, CodeBadAttachment},
+	{ErrBadFormData, CodeBadFormData},
+	{ErrTicketNotRunning, CodeTicketNotRunning},
+	{ErrAlreadySigned, CodeAlreadySigned},
+	{ErrStepNotFound, CodeStepNotFound},
+	{ErrNextStepNotAllowed, CodeNextStepNotAllowed},
+	{ErrNextStepNotFound, CodeNextStepNotFound},
+	{ErrBadSignType, CodeBadSignType},
+	{ErrWaitingForChild, CodeWaitingForChild},
+	{ErrNotChild, CodeNotChild},
+	{ErrChildRunning, CodeChildRunning},
+	{ErrSubProcessTemplate, CodeSubProcessTemplate},
+	{ErrAutomaticStep, CodeAutomaticStep},
+	{ErrNotServiceStep, CodeNotServiceStep},
+	{ErrHandlerNotFound, CodeHandlerNotFound},
+	{ErrServiceFailed, CodeServiceFailed},
+	{ErrTooManyServiceHops, CodeTooManyServiceHops},
+	{ErrJumpTargetNotFound, CodeJumpTargetNotFound},
+	{ErrJumpToCurrentStep, CodeJumpToCurrentStep},
+	{ErrNotVisited, CodeNotVisited},
+	{ErrTicketRunning, CodeTicketRunning},
+	{ErrBadReopenStep, CodeBadReopenStep},
+	{ErrOperatorNotInOperatorList, CodeOperatorNotAllowed},
+}
+
+// ApprovalError 审批引擎返回的错误，携带出错时的工单上下文
+type ApprovalError struct {
+	Code      string // 错误码
+	Ticket    string // 工单唯一标识
+	Step      string // 工单当前步骤
+	Operator  string // 操作人
+	Operation string // 尝试执行的操作
+	Next      string // 尝试进入的步骤
+	err       error
+}
+
+func newApprovalError(err error, ticket, step, operator, operation, next string) *ApprovalError {
+	return &ApprovalError{
+		Code:      codeOf(err),
+		Ticket:    ticket,
+		Step:      step,
+		Operator:  operator,
+		Operation: operation,
+		Next:      next,
+		err:       err,
+	}
+}
+
+func (e *ApprovalError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.err.Error())
+	for _, kv := range [][2]string{
+		{"ticket", e.Ticket},
+		{"step", e.Step},
+		{"operator", e.Operator},
+		{"operation", e.Operation},
+		{"next", e.Next},
+	} {
+		if len(kv[1]) > 0 {
+			b.WriteString(" ")
+			b.WriteString(kv[0])
+			b.WriteString("=")
+			b.WriteString(kv[1])
+		}
+	}
+	return b.String()
+}
+
+func (e *ApprovalError) Unwrap() error {
+	return e.err
+}
+
+// Code 返回错误码，非审批引擎错误返回空字符串
+func Code(err error) string {
+	var ae *ApprovalError
+	if errors.As(err, &ae) {
+		return ae.Code
+	}
+	return codeOf(err)
+}
+
+func codeOf(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return ""
+}
@@ -0,0 +1,189 @@
+package ticket
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func TestApprovalError(t *testing.T) {
+	stepConfig := map[string]*models.StepConfig{
+		"a": {
+			Step:     "a",
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "approve", Step: "end"}, {Operation: "jump", Step: "missing"}},
+		},
+		"c": {
+			Step:     "c",
+			Disposal: models.Disposal{SignType: "custom"},
+			Next:     []*models.NextStep{{Operation: "approve", Step: "end"}},
+		},
+		"end": {Step: "end"},
+	}
+	tests := []struct {
+		name      string
+		ticket    *models.Ticket
+		next      string
+		operation string
+		operator  string
+		opts      []ApprovalOption
+		want      error
+		legacy    error
+		code      string
+	}{
+		{
+			name:      "missing arguments",
+			ticket:    &models.Ticket{Uid: "t1", Status: models.Running, Step: "a"},
+			next:      "end",
+			operation: "approve",
+			want:      ErrMissingArguments,
+			legacy:    ErrBadArguments,
+			code:      CodeMissingArguments,
+		},
+		{
+			name:      "bad attachment",
+			ticket:    &models.Ticket{Uid: "t1", Status: models.Running, Step: "a", Operator: []string{"u1"}},
+			next:      "end",
+			operation: "approve",
+			operator:  "u1",
+			opts:      []ApprovalOption{WithAttachments(&models.Attachment{Name: "a.pdf"})},
+			want:      ErrBadAttachment,
+			legacy:    ErrBadArguments,
+			code:      CodeBadAttachment,
+		},
+		{
+			name:      "ticket not running",
+			ticket:    &models.Ticket{Uid: "t1", Status: models.Passed, Step: "end"},
+			next:      "end",
+			operation: "approve",
+			operator:  "u1",
+			want:      ErrTicketNotRunning,
+			legacy:    ErrAlreadyApproved,
+			code:      CodeTicketNotRunning,
+		},
+		{
+			name:      "already signed",
+			ticket:    &models.Ticket{Uid: "t1", Status: models.Running, Step: "a", OperatedUser: []string{"u1"}},
+			next:      "end",
+			operation: "approve",
+			operator:  "u1",
+			want:      ErrAlreadySigned,
+			legacy:    ErrAlreadyApproved,
+			code:      CodeAlreadySigned,
+		},
+		{
+			name:      "operator not allowed",
+			ticket:    &models.Ticket{Uid: "t1", Status: models.Running, Step: "a", Operator: []string{"u2"}},
+			next:      "end",
+			operation: "approve",
+			operator:  "u1",
+			want:      ErrOperatorNotInOperatorList,
+			legacy:    ErrOperatorNotInOperatorList,
+			code:      CodeOperatorNotAllowed,
+		},
+		{
+			name:      "step not found",
+			ticket:    &models.Ticket{Uid: "t1", Status: models.Running, Step: "x"},
+			next:      "end",
+			operation: "approve",
+			operator:  "u1",
+			want:      ErrStepNotFound,
+			legacy:    ErrInvalidStep,
+			code:      CodeStepNotFound,
+		},
+		{
+			name:      "next step not allowed",
+			ticket:    &models.Ticket{Uid: "t1", Status: models.Running, Step: "a", Operator: []string{"u1"}},
+			next:      "end",
+			operation: "reject",
+			operator:  "u1",
+			want:      ErrNextStepNotAllowed,
+			legacy:    ErrInvalidStep,
+			code:      CodeNextStepNotAllowed,
+		},
+		{
+			name:      "next step not found",
+			ticket:    &models.Ticket{Uid: "t1", Status: models.Running, Step: "a", Operator: []string{"u1"}},
+			next:      "missing",
+			operation: "jump",
+			operator:  "u1",
+			want:      ErrNextStepNotFound,
+			legacy:    ErrInvalidStep,
+			code:      CodeNextStepNotFound,
+		},
+		{
+			name:      "unknown sign type",
+			ticket:    &models.Ticket{Uid: "t1", Status: models.Running, Step: "c", Operator: []string{"u1"}},
+			next:      "end",
+			operation: "approve",
+			operator:  "u1",
+			want:      ErrBadSignType,
+			legacy:    ErrInvalidStep,
+			code:      CodeBadSignType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Helper{}
+			_, err := h.Approval(tt.next, tt.operation, tt.operator, false, []string{"end"}, tt.ticket, stepConfig, tt.opts...)
+			if !errors.Is(err, tt.want) || !errors.Is(err, tt.legacy) {
+				t.Fatalf("Approval() error = %v, want %v and %v", err, tt.want, tt.legacy)
+			}
+			if got := Code(err); got != tt.code {
+				t.Errorf("Code() = %v, want %v", got, tt.code)
+			}
+			var ae *ApprovalError
+			if !errors.As(err, &ae) {
+				t.Fatalf("Approval() error %T is not *ApprovalError", err)
+			}
+			if ae.Ticket != "t1" || ae.Step != tt.ticket.Step || ae.Operator != tt.operator || ae.Operation != tt.operation || ae.Next != tt.next {
+				t.Errorf("ApprovalError context = %+v", ae)
+			}
+		})
+	}
+}
+
+func TestApprovalError_Error(t *testing.T) {
+	err := newApprovalError(ErrAlreadySigned, "t1", "a", "u1", "approve", "")
+	want := "already approved: operator already signed ticket=t1 step=a operator=u1 operation=approve"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %v, want %v", got, want)
+	}
+	if got := Code(errors.New("other")); got != "" {
+		t.Errorf("Code() = %v, want empty", got)
+	}
+	if got := Code(ErrStepNotFound); got != CodeStepNotFound {
+		t.Errorf("Code() = %v, want %v", got, CodeStepNotFound)
+	}
+}
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "nested sentinel", err: ErrReopenLimit, want: CodeReopenLimit},
+		{name: "wrapped nested sentinel", err: fmt.Errorf("ctx: %w", ErrReopenLimit), want: CodeReopenLimit},
+		{name: "wrapped parent sentinel", err: fmt.Errorf("ctx: %w", ErrReopenNotAllowed), want: CodeReopenNotAllowed},
+		{name: "wrapped capability", err: fmt.Errorf("ctx: %w", ErrCapabilityDenied), want: CodeCapabilityDenied},
+		{name: "generic sentinel", err: ErrOperatorNotInOperatorList, want: CodeOperatorNotAllowed},
+		{name: "approval error", err: newApprovalError(fmt.Errorf("ctx: %w", ErrOverrideForbidden), "t1", "a", "u1", "", ""), want: CodeOverrideForbidden},
+		{name: "legacy sentinel", err: ErrBadArguments, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Code(tt.err); got != tt.want {
+				t.Errorf("Code() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	// 每个细分错误都应匹配到自身的错误码，而不是其包装的错误
+	for _, c := range errorCodes {
+		if got := Code(c.err); got != c.code {
+			t.Errorf("Code(%v) = %v, want %v", c.err, got, c.code)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package ticket
+
+import (
+	"github.com/victorwong171/punched-tape/models"
+)
+
+// Jump 管理员将运行中的工单移动到模板中的任意步骤，处理人按目标步骤配置重置；需要CapJump能力
+// reason必填，与操作人一同记录在操作记录中；当前步骤禁止越权时不允许移出
+func (h *Helper) Jump(
+	actor,
+	target,
+	reason string,
+	endStep []string,
+	ticket *models.Ticket,
+	stepConfig map[string]*models.StepConfig,
+	opts ...ApprovalOption) (*models.Transition, error) {
+	return h.move(models.Jump, models.CapJump, actor, target, reason, endStep, ticket, stepConfig, opts)
+}
+
+// Rollback 管理员将运行中的工单退回到已经过的步骤，target为空时退回到上一个步骤；需要CapRollback能力
+func (h *Helper) Rollback(
+	actor,
+	target,
+	reason string,
+	endStep []string,
+	ticket *models.Ticket,
+	stepConfig map[string]*models.StepConfig,
+	opts ...ApprovalOption) (*models.Transition, error) {
+	if ticket != nil && len(target) == 0 {
+		if target = previousStep(ticket); len(target) == 0 {
+			return nil, newApprovalError(ErrNotVisited, ticket.Uid, ticket.Step, actor, models.Rollback, target)
+		}
+	}
+	return h.move(models.Rollback, models.CapRollback, actor, target, reason, endStep, ticket, stepConfig, opts)
+}
+
+// move 执行Jump与Rollback，当前步骤可以是等待子工单或自动步骤，用于移出卡住的工单
+func (h *Helper) move(
+	operation,
+	capability,
+	actor,
+	target,
+	reason string,
+	endStep []string,
+	ticket *models.Ticket,
+	stepConfig map[string]*models.StepConfig,
+	opts []ApprovalOption) (*models.Transition, error) {
+	if ticket == nil {
+		return nil, newApprovalError(ErrMissingArguments, "", "", actor, operation, target)
+	}
+	fail := func(err error) (*models.Transition, error) {
+		return nil, newApprovalError(err, ticket.Uid, ticket.Step, actor, operation, target)
+	}
+	if len(actor) == 0 || len(target) == 0 || len(reason) == 0 {
+		return fail(ErrMissingArguments)
+	}
+	if ticket.Status != models.Running {
+		return fail(ErrTicketNotRunning)
+	}
+	nextStep := stepConfig[target]
+	switch {
+	case nextStep == nil:
+		return fail(ErrJumpTargetNotFound)
+	case target == ticket.Step:
+		return fail(ErrJumpToCurrentStep)
+	case operation == models.Rollback && !visited(ticket, target):
+		return fail(ErrNotVisited)
+	}
+	step := stepConfig[ticket.Step]
+	if err := h.override(actor, capability, false, ticket, step, operation); err != nil {
+		return fail(err)
+	}
+	options := newApprovalOptions(opts)
+
+	updated := updateTicket(ticket.Clone(), nextStep, endStep)
+	updated.AddCC(options.cc...)
+	action := &models.Action{
+		Operator:  actor,
+		Operation: operation,
+		Step:      ticket.Step,
+		Next:      target,
+		Comment:   reason,
+		CreatedAt: options.at,
+		Override:  capability,
+	}
+	tr, err := h.settle(ticket, updated, step, stepConfig, endStep, action, options, true)
+	if err != nil {
+		return nil, err
+	}
+	if operation == models.Rollback {
+		tr.Events = append(tr.Events, models.EventRolledBack)
+	} else {
+		tr.Events = append(tr.Events, models.EventJumped)
+	}
+	h.notify(tr, options)
+	return tr, nil
+}
+
+// previousStep 工单进入当前步骤之前所在的步骤
+// 按操作记录重建经过的步骤栈，回退时出栈到目标步骤，连续回退时沿原路径逐步后退
+func previousStep(ticket *models.Ticket) string {
+	var path []string
+	for _, a := range ticket.History {
+		if len(a.GetNext()) == 0 || a.GetNext() == a.GetStep() {
+			continue
+		}
+		if len(path) == 0 || path[len(path)-1] != a.GetStep() {
+			path = append(path, a.GetStep())
+		}
+		if a.GetOperation() == models.Rollback {
+			if i := lastIndex(path, a.GetNext()); i >= 0 {
+				path = path[:i+1]
+				continue
+			}
+		}
+		path = append(path, a.GetNext())
+	}
+	if n := len(path); n >= 2 && path[n-1] == ticket.Step {
+		return path[n-2]
+	}
+	return ""
+}
+
+func lastIndex(path []string, step string) int {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == step {
+			return i
+		}
+	}
+	return -1
+}
+
+// visited 工单是否经过step，开始步骤在离开时记录于操作记录中
+func visited(ticket *models.Ticket, step string) bool {
+	for _, a := range ticket.History {
+		if a.GetStep() == step {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,297 @@
+package ticket
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func TestHelper_Jump(t *testing.T) {
+	endStep := []string{"end"}
+	stepConfig := map[string]*models.StepConfig{
+		"draft": {
+			Step:     "draft",
+			Operator: []string{"applicant"},
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "submit", Step: "review"}},
+		},
+		"review": {
+			Step:     "review",
+			Operator: []string{"r1", "r2"},
+			Disposal: models.Disposal{SignType: models.JointlySign, JointSignRate: 1},
+			Next:     []*models.NextStep{{Operation: "approve", Step: "pay"}},
+		},
+		"pay": {
+			Step:       "pay",
+			Operator:   []string{"finance"},
+			Disposal:   models.Disposal{SignType: models.AnyoneSign},
+			NoOverride: true,
+			Next:       []*models.NextStep{{Operation: "approve", Step: "end"}},
+		},
+		"end": {Step: "end"},
+	}
+	reviewing := &models.Ticket{
+		Uid:          "t1",
+		Status:       models.Running,
+		Step:         "review",
+		Operator:     []string{"r1", "r2"},
+		OperatedUser: []string{"r1"},
+	}
+
+	tests := []struct {
+		name         string
+		actor        string
+		target       string
+		reason       string
+		ticket       *models.Ticket
+		wantOperator []string
+		wantEvents   []string
+		wantErr      error
+	}{
+		{name: "missing reason", actor: "ops", target: "draft", ticket: reviewing, wantErr: ErrMissingArguments},
+		{name: "unknown target", actor: "ops", target: "nowhere", reason: "r", ticket: reviewing, wantErr: ErrJumpTargetNotFound},
+		{name: "current step", actor: "ops", target: "review", reason: "r", ticket: reviewing, wantErr: ErrJumpToCurrentStep},
+		{name: "no capability", actor: "r1", target: "draft", reason: "r", ticket: reviewing, wantErr: ErrCapabilityDenied},
+		{
+			name:    "override forbidden",
+			actor:   "ops",
+			target:  "end",
+			reason:  "r",
+			ticket:  &models.Ticket{Status: models.Running, Step: "pay"},
+			wantErr: ErrOverrideForbidden,
+		},
+		{
+			name:    "not running",
+			actor:   "ops",
+			target:  "draft",
+			reason:  "r",
+			ticket:  &models.Ticket{Status: models.Passed, Step: "end"},
+			wantErr: ErrTicketNotRunning,
+		},
+		{
+			name:         "jump forward",
+			actor:        "ops",
+			target:       "pay",
+			reason:       "reviewer r2 left the company",
+			ticket:       reviewing,
+			wantOperator: []string{"finance"},
+			wantEvents:   []string{models.EventStepLeft, models.EventStepEntered, models.EventOverridden, models.EventJumped},
+		},
+		{
+			name:         "current step not in template",
+			actor:        "ops",
+			target:       "review",
+			reason:       "step removed from template",
+			ticket:       &models.Ticket{Uid: "t1", Status: models.Running, Step: "gone", Operator: []string{"x"}},
+			wantOperator: []string{"r1", "r2"},
+			wantEvents:   []string{models.EventStepLeft, models.EventStepEntered, models.EventOverridden, models.EventJumped},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Helper{}
+			h.SetAuthorizer(Capabilities{"ops": {models.CapJump}})
+			var got []*models.Transition
+			h.Register(ListenerFunc(func(tr *models.Transition) { got = append(got, tr) }))
+			tr, err := h.Jump(tt.actor, tt.target, tt.reason, endStep, tt.ticket, stepConfig)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Jump() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(got) != 0 {
+					t.Errorf("listener received %d transitions on failure, want 0", len(got))
+				}
+				return
+			}
+			if tr.Ticket.Step != tt.target || tr.Ticket.OperatedUser != nil {
+				t.Errorf("Jump() ticket = %+v", tr.Ticket)
+			}
+			if diff := cmp.Diff(tr.Ticket.Operator, tt.wantOperator); len(diff) > 0 {
+				t.Errorf("Jump() operator diff = %v", diff)
+			}
+			if diff := cmp.Diff(tr.Events, tt.wantEvents); len(diff) > 0 {
+				t.Errorf("Jump() events diff = %v", diff)
+			}
+			action := tr.Ticket.History[len(tr.Ticket.History)-1]
+			if action.Operator != tt.actor || action.Operation != models.Jump || action.Comment != tt.reason || action.Override != models.CapJump {
+				t.Errorf("Jump() action = %+v", action)
+			}
+			if len(got) != 1 {
+				t.Errorf("listener received %d transitions, want 1", len(got))
+			}
+		})
+	}
+}
+
+func TestHelper_Rollback(t *testing.T) {
+	endStep := []string{"end"}
+	stepConfig := map[string]*models.StepConfig{
+		"draft": {
+			Step:     "draft",
+			Operator: []string{"applicant"},
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "submit", Step: "review"}},
+		},
+		"review": {
+			Step:     "review",
+			Operator: []string{"r1", "r2"},
+			Disposal: models.Disposal{SignType: models.JointlySign, JointSignRate: 1},
+			Next:     []*models.NextStep{{Operation: "approve", Step: "pay"}},
+		},
+		"pay": {
+			Step:     "pay",
+			Operator: []string{"finance"},
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "approve", Step: "end"}},
+		},
+		"end": {Step: "end"},
+	}
+
+	tests := []struct {
+		name      string
+		actor     string
+		targets   []string
+		ticket    *models.Ticket
+		wantSteps []string
+		wantErr   error
+	}{
+		{
+			name:    "target not visited",
+			actor:   "ops",
+			targets: []string{"pay"},
+			ticket: &models.Ticket{
+				Status: models.Running,
+				Step:   "review",
+				History: []*models.Action{
+					{Operator: "applicant", Operation: "submit", Step: "draft", Next: "review"},
+				},
+			},
+			wantErr: ErrNotVisited,
+		},
+		{
+			name:    "nothing to roll back to",
+			actor:   "ops",
+			targets: []string{""},
+			ticket:  &models.Ticket{Status: models.Running, Step: "draft"},
+			wantErr: ErrNotVisited,
+		},
+		{
+			name:    "no capability",
+			actor:   "r1",
+			targets: []string{""},
+			ticket: &models.Ticket{
+				Status: models.Running,
+				Step:   "review",
+				History: []*models.Action{
+					{Operator: "applicant", Operation: "submit", Step: "draft", Next: "review"},
+				},
+			},
+			wantErr: ErrCapabilityDenied,
+		},
+		{
+			name:    "previous step",
+			actor:   "ops",
+			targets: []string{""},
+			ticket: &models.Ticket{
+				Status:   models.Running,
+				Step:     "review",
+				Operator: []string{"r1", "r2"},
+				History: []*models.Action{
+					{Operator: "applicant", Operation: "submit", Step: "draft", Next: "review"},
+				},
+			},
+			wantSteps: []string{"draft"},
+		},
+		{
+			name:    "two rollbacks in a row",
+			actor:   "ops",
+			targets: []string{"", ""},
+			ticket: &models.Ticket{
+				Status:   models.Running,
+				Step:     "pay",
+				Operator: []string{"finance"},
+				History: []*models.Action{
+					{Operator: "applicant", Operation: "submit", Step: "draft", Next: "review"},
+					{Operator: "r1", Operation: "approve", Step: "review", Next: "review"},
+					{Operator: "r2", Operation: "approve", Step: "review", Next: "pay"},
+				},
+			},
+			wantSteps: []string{"review", "draft"},
+		},
+		{
+			name:    "rollback walks the visited path after a jump",
+			actor:   "ops",
+			targets: []string{"", ""},
+			ticket: &models.Ticket{
+				Status:   models.Running,
+				Step:     "pay",
+				Operator: []string{"finance"},
+				History: []*models.Action{
+					{Operator: "applicant", Operation: "submit", Step: "draft", Next: "review"},
+					{Operator: "ops", Operation: models.Jump, Step: "review", Next: "draft"},
+					{Operator: "applicant", Operation: "submit", Step: "draft", Next: "review"},
+					{Operator: "r2", Operation: "approve", Step: "review", Next: "pay"},
+				},
+			},
+			wantSteps: []string{"review", "draft"},
+		},
+		{
+			name:    "current step not in template",
+			actor:   "ops",
+			targets: []string{""},
+			ticket: &models.Ticket{
+				Status:   models.Running,
+				Step:     "gone",
+				Operator: []string{"x"},
+				History: []*models.Action{
+					{Operator: "applicant", Operation: "submit", Step: "draft", Next: "gone"},
+				},
+			},
+			wantSteps: []string{"draft"},
+		},
+		{
+			name:    "explicit target then previous step",
+			actor:   "ops",
+			targets: []string{"draft", ""},
+			ticket: &models.Ticket{
+				Status:   models.Running,
+				Step:     "pay",
+				Operator: []string{"finance"},
+				History: []*models.Action{
+					{Operator: "applicant", Operation: "submit", Step: "draft", Next: "review"},
+					{Operator: "r2", Operation: "approve", Step: "review", Next: "pay"},
+				},
+			},
+			wantSteps: []string{"draft"},
+			wantErr:   ErrNotVisited,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Helper{}
+			h.SetAuthorizer(Capabilities{"ops": {models.CapRollback}})
+			ticket := tt.ticket
+			var steps []string
+			var err error
+			for _, target := range tt.targets {
+				var tr *models.Transition
+				if tr, err = h.Rollback(tt.actor, target, "missing receipts", endStep, ticket, stepConfig); err != nil {
+					break
+				}
+				if !tr.HasEvent(models.EventRolledBack) || len(tr.Ticket.History) != len(ticket.History)+1 {
+					t.Errorf("Rollback() = %+v", tr)
+				}
+				ticket = tr.Ticket
+				steps = append(steps, ticket.Step)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Rollback() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(steps, tt.wantSteps); len(diff) > 0 {
+				t.Errorf("Rollback() steps diff = %v", diff)
+			}
+		})
+	}
+}
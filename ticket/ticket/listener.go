@@ -0,0 +1,64 @@
+package ticket
+
+import (
+	"github.com/victorwong171/punched-tape/models"
+)
+
+// Listener 工单流转监听器，在Helper完成一次操作后同步调用
+// 监听器不应修改Transition中的工单
+type Listener interface {
+	OnTransition(tr *models.Transition)
+}
+
+// ListenerFunc 将普通函数适配为Listener
+type ListenerFunc func(tr *models.Transition)
+
+func (f ListenerFunc) OnTransition(tr *models.Transition) {
+	f(tr)
+}
+
+// Register 注册工单流转监听器
+func (h *Helper) Register(listeners ...Listener) {
+	h.listeners = append(h.listeners, listeners...)
+}
+
+// Notify 通知监听器，用于以WithoutNotify执行的操作在持久化成功后补发通知
+func (h *Helper) Notify(tr *models.Transition) {
+	h.emit(tr)
+}
+
+// notify 按操作参数决定是否通知监听器
+func (h *Helper) notify(tr *models.Transition, options *approvalOptions) {
+	if !options.quiet {
+		h.emit(tr)
+	}
+}
+
+func (h *Helper) emit(tr *models.Transition) {
+	for _, l := range h.listeners {
+		l.OnTransition(tr)
+	}
+}
+
+// transitionEvents 根据操作前后的步骤与状态计算流转事件
+func transitionEvents(from, to, status string) []string {
+	var events []string
+	if from != to {
+		events = []string{models.EventStepLeft, models.EventStepEntered}
+	} else if status == models.Running || len(status) == 0 {
+		return []string{models.EventSigned}
+	}
+	// 工单可能在原步骤直接结束，如超过环路次数限制被驳回
+	switch status {
+	case models.Passed:
+		events = append(events, models.EventPassed)
+	case models.Rejected:
+		events = append(events, models.EventRejected)
+	case models.Cancelled:
+		events = append(events, models.EventCancelled)
+	case models.Running, "":
+	default:
+		events = append(events, models.EventClosed)
+	}
+	return events
+}
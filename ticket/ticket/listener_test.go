@@ -0,0 +1,184 @@
+package ticket
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func Test_transitionEvents(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		to     string
+		status string
+		want   []string
+	}{
+		{
+			name:   "signed",
+			from:   "a",
+			to:     "a",
+			status: models.Running,
+			want:   []string{models.EventSigned},
+		},
+		{
+			name:   "moved",
+			from:   "a",
+			to:     "b",
+			status: models.Running,
+			want:   []string{models.EventStepLeft, models.EventStepEntered},
+		},
+		{
+			name:   "passed",
+			from:   "a",
+			to:     "end",
+			status: models.Passed,
+			want:   []string{models.EventStepLeft, models.EventStepEntered, models.EventPassed},
+		},
+		{
+			name:   "rejected",
+			from:   "a",
+			to:     "end",
+			status: models.Rejected,
+			want:   []string{models.EventStepLeft, models.EventStepEntered, models.EventRejected},
+		},
+		{
+			name:   "cancelled",
+			from:   "a",
+			to:     "cancel",
+			status: models.Cancelled,
+			want:   []string{models.EventStepLeft, models.EventStepEntered, models.EventCancelled},
+		},
+		{
+			name:   "rejected in place",
+			from:   "a",
+			to:     "a",
+			status: models.Rejected,
+			want:   []string{models.EventRejected},
+		},
+		{
+			name:   "custom",
+			from:   "a",
+			to:     "withdraw",
+			status: "withdrawn",
+			want:   []string{models.EventStepLeft, models.EventStepEntered, models.EventClosed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(transitionEvents(tt.from, tt.to, tt.status), tt.want); len(diff) > 0 {
+				t.Errorf("transitionEvents() diff = %v", diff)
+			}
+		})
+	}
+}
+
+func TestHelper_Register(t *testing.T) {
+	stepConfig := map[string]*models.StepConfig{
+		"a": {
+			Step:     "a",
+			Disposal: models.Disposal{SignType: models.SerialSign},
+			Next:     []*models.NextStep{{Operation: "submit", Step: "end"}},
+			CC:       models.CarbonCopy{Roles: []string{"hr"}, Trigger: models.CCOnLeave},
+		},
+		"end": {Step: "end"},
+	}
+	var got []*models.Transition
+	h := &Helper{}
+	h.Register(ListenerFunc(func(tr *models.Transition) {
+		got = append(got, tr)
+	}))
+
+	ticket := &models.Ticket{Status: models.Running, Step: "a", Operator: []string{"u1", "u2"}}
+	ticket, err := h.Approval("end", "submit", "u1", false, []string{"end"}, ticket, stepConfig, WithCC("x"))
+	if err != nil {
+		t.Fatalf("Approval() error = %v", err)
+	}
+	if _, err = h.Approval("end", "submit", "u2", false, []string{"end"}, ticket, stepConfig); err != nil {
+		t.Fatalf("Approval() error = %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("listener received %d transitions, want 2", len(got))
+	}
+	if diff := cmp.Diff(got[0].Events, []string{models.EventSigned}); len(diff) > 0 {
+		t.Errorf("first transition events diff = %v", diff)
+	}
+	if diff := cmp.Diff(got[0].CC, []string{"x"}); len(diff) > 0 {
+		t.Errorf("first transition cc diff = %v", diff)
+	}
+	if got[1].From != "a" || got[1].To != "end" || got[1].Action.Operator != "u2" {
+		t.Errorf("second transition = %+v", got[1])
+	}
+	if diff := cmp.Diff(got[1].Events, []string{models.EventStepLeft, models.EventStepEntered, models.EventPassed}); len(diff) > 0 {
+		t.Errorf("second transition events diff = %v", diff)
+	}
+	if diff := cmp.Diff(got[1].CCRoles, []string{"hr"}); len(diff) > 0 {
+		t.Errorf("second transition cc roles diff = %v", diff)
+	}
+}
+
+func TestHelper_Approval_EndStatus(t *testing.T) {
+	stepConfig := map[string]*models.StepConfig{
+		"a": {
+			Step:     "a",
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next: []*models.NextStep{
+				{Operation: "submit", Step: "end"},
+				{Operation: models.Reject, Step: "cancel"},
+			},
+		},
+		"end":    {Step: "end"},
+		"cancel": {Step: "cancel", EndStatus: models.Cancelled},
+	}
+	var got *models.Transition
+	h := &Helper{}
+	h.Register(ListenerFunc(func(tr *models.Transition) { got = tr }))
+
+	ticket := &models.Ticket{Status: models.Running, Step: "a", Operator: []string{"u1"}}
+	ticket, err := h.Approval("cancel", models.Reject, "u1", false, []string{"end", "cancel"}, ticket, stepConfig)
+	if err != nil {
+		t.Fatalf("Approval() error = %v", err)
+	}
+	if ticket.Status != models.Cancelled {
+		t.Errorf("Approval() status = %v, want %v", ticket.Status, models.Cancelled)
+	}
+	if got == nil || !got.HasEvent(models.EventCancelled) {
+		t.Errorf("transition = %+v, want %v event", got, models.EventCancelled)
+	}
+}
+
+func TestHelper_Approval_State(t *testing.T) {
+	stepConfig := map[string]*models.StepConfig{
+		"a": {
+			Step:     "a",
+			State:    "in_review",
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "submit", Step: "pay"}},
+		},
+		"pay": {
+			Step:     "pay",
+			State:    "awaiting_payment",
+			Operator: []string{"finance"},
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "submit", Step: "end"}},
+		},
+		"end": {Step: "end", State: "done"},
+	}
+	h := &Helper{}
+	ticket := &models.Ticket{Status: models.Running, Step: "a", State: "in_review", Operator: []string{"u1"}}
+	ticket, err := h.Approval("pay", "submit", "u1", false, []string{"end"}, ticket, stepConfig)
+	if err != nil {
+		t.Fatalf("Approval() error = %v", err)
+	}
+	if ticket.State != "awaiting_payment" {
+		t.Errorf("Approval() state = %v, want awaiting_payment", ticket.State)
+	}
+	if ticket, err = h.Approval("end", "submit", "finance", false, []string{"end"}, ticket, stepConfig); err != nil {
+		t.Fatalf("Approval() error = %v", err)
+	}
+	if ticket.State != "done" {
+		t.Errorf("Approval() state = %v, want done", ticket.State)
+	}
+}
@@ -0,0 +1,58 @@
+package ticket
+
+import (
+	"fmt"
+
+	"github.com/victorwong171/punched-tape/models"
+)
+
+// limitLoop 检查工单从from离开后的流转是否超过环路次数限制，先检查流转限制，再检查目标步骤限制
+// 超过时撤销本次进入的计数，按限制配置驳回工单或转入升级步骤，返回是否超过限制
+func limitLoop(from string, step *models.StepConfig, updated *models.Ticket, stepConfig map[string]*models.StepConfig, endStep []string) (bool, error) {
+	to := updated.Step
+	if to == from {
+		return false, nil
+	}
+	key := models.EdgeKey(from, to)
+	var limit *models.LoopLimit
+	// 当前步骤已不在模板中时没有流转限制，只检查目标步骤的次数限制
+	if step != nil {
+		for _, next := range step.Next {
+			if next.GetStep() == to && next.Limit.Exceeded(updated.Traversals[key]) {
+				limit = &next.Limit
+				break
+			}
+		}
+	}
+	if target := stepConfig[to]; limit == nil && target != nil && target.Limit.Exceeded(updated.Visits[to]) {
+		limit = &target.Limit
+	}
+	if limit == nil {
+		return false, nil
+	}
+
+	uncount(updated.Visits, to)
+	uncount(updated.Traversals, key)
+	updated.Step = from
+	if limit.Escalates() {
+		escalate := stepConfig[limit.Step]
+		if escalate == nil {
+			return false, fmt.Errorf("%w: escalation %s", ErrNextStepNotFound, limit.Step)
+		}
+		updateTicket(updated, escalate, endStep)
+		return true, nil
+	}
+	// 驳回时工单停留在离开前的步骤
+	updated.State = step.GetState()
+	updated.Status = models.Rejected
+	updated.Operator = nil
+	updated.OperatedUser = nil
+	updated.RejectedUser = nil
+	return true, nil
+}
+
+func uncount(counts map[string]int, key string) {
+	if counts[key]--; counts[key] <= 0 {
+		delete(counts, key)
+	}
+}
@@ -0,0 +1,281 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func TestHelper_Approval_LoopLimit(t *testing.T) {
+	type op struct {
+		operation string
+		operator  string
+		next      string
+	}
+	reject := op{operation: models.Reject, operator: "reviewer", next: "revise"}
+	submit := op{operation: "submit", operator: "applicant", next: "review"}
+	jump := op{operation: models.Jump, operator: "ops", next: "revise"}
+	tests := []struct {
+		name           string
+		stepConfig     map[string]*models.StepConfig
+		ops            []op
+		wantStatus     string
+		wantStep       string
+		wantOperator   []string
+		wantVisits     map[string]int
+		wantTraversals map[string]int
+		wantEvents     []string
+		wantErr        error
+	}{
+		{
+			name: "unlimited",
+			stepConfig: map[string]*models.StepConfig{
+				"review": {
+					Step:     "review",
+					Operator: []string{"reviewer"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next: []*models.NextStep{
+						{Operation: "approve", Step: "end"},
+						{Operation: models.Reject, Step: "revise"},
+					},
+				},
+				"revise": {
+					Step:     "revise",
+					Operator: []string{"applicant"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "submit", Step: "review"}},
+				},
+				"end": {Step: "end"},
+			},
+			ops:          []op{reject, submit, reject, submit, reject, submit},
+			wantStatus:   models.Running,
+			wantStep:     "review",
+			wantOperator: []string{"reviewer"},
+			wantVisits:   map[string]int{"revise": 3, "review": 3},
+			wantTraversals: map[string]int{
+				models.EdgeKey("review", "revise"): 3,
+				models.EdgeKey("revise", "review"): 3,
+			},
+			wantEvents: []string{models.EventStepLeft, models.EventStepEntered},
+		},
+		{
+			name: "step limit rejects",
+			stepConfig: map[string]*models.StepConfig{
+				"review": {
+					Step:     "review",
+					State:    "in_review",
+					Operator: []string{"reviewer"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next: []*models.NextStep{
+						{Operation: "approve", Step: "end"},
+						{Operation: models.Reject, Step: "revise"},
+					},
+				},
+				"revise": {
+					Step:     "revise",
+					Operator: []string{"applicant"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Limit:    models.LoopLimit{MaxVisits: 2},
+					Next:     []*models.NextStep{{Operation: "submit", Step: "review"}},
+				},
+				"end": {Step: "end"},
+			},
+			ops:        []op{reject, submit, reject, submit, reject},
+			wantStatus: models.Rejected,
+			wantStep:   "review",
+			wantVisits: map[string]int{"revise": 2, "review": 2},
+			wantTraversals: map[string]int{
+				models.EdgeKey("review", "revise"): 2,
+				models.EdgeKey("revise", "review"): 2,
+			},
+			wantEvents: []string{models.EventRejected, models.EventLoopLimited},
+		},
+		{
+			name: "edge limit escalates",
+			stepConfig: map[string]*models.StepConfig{
+				"review": {
+					Step:     "review",
+					Operator: []string{"reviewer"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next: []*models.NextStep{
+						{Operation: "approve", Step: "end"},
+						{Operation: models.Reject, Step: "revise"},
+					},
+				},
+				"revise": {
+					Step:     "revise",
+					Operator: []string{"applicant"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next: []*models.NextStep{{
+						Operation: "submit",
+						Step:      "review",
+						Limit:     models.LoopLimit{MaxVisits: 1, OnExceeded: models.LimitEscalate, Step: "director"},
+					}},
+				},
+				"director": {
+					Step:     "director",
+					Operator: []string{"director"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "approve", Step: "end"}},
+				},
+				"end": {Step: "end"},
+			},
+			ops:          []op{reject, submit, reject, submit},
+			wantStatus:   models.Running,
+			wantStep:     "director",
+			wantOperator: []string{"director"},
+			wantVisits:   map[string]int{"revise": 2, "review": 1, "director": 1},
+			wantTraversals: map[string]int{
+				models.EdgeKey("review", "revise"):   2,
+				models.EdgeKey("revise", "review"):   1,
+				models.EdgeKey("revise", "director"): 1,
+			},
+			wantEvents: []string{models.EventStepLeft, models.EventStepEntered, models.EventLoopLimited},
+		},
+		{
+			name: "missing escalation step",
+			stepConfig: map[string]*models.StepConfig{
+				"review": {
+					Step:     "review",
+					Operator: []string{"reviewer"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: models.Reject, Step: "revise"}},
+				},
+				"revise": {
+					Step:     "revise",
+					Operator: []string{"applicant"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next: []*models.NextStep{{
+						Operation: "submit",
+						Step:      "review",
+						Limit:     models.LoopLimit{MaxVisits: 1, OnExceeded: models.LimitEscalate, Step: "nowhere"},
+					}},
+				},
+				"end": {Step: "end"},
+			},
+			ops:     []op{reject, submit, reject, submit},
+			wantErr: ErrNextStepNotFound,
+		},
+		{
+			name: "jump counts toward step limit",
+			stepConfig: map[string]*models.StepConfig{
+				"review": {
+					Step:     "review",
+					Operator: []string{"reviewer"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next: []*models.NextStep{
+						{Operation: "approve", Step: "end"},
+						{Operation: models.Reject, Step: "revise"},
+					},
+				},
+				"revise": {
+					Step:     "revise",
+					Operator: []string{"applicant"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Limit:    models.LoopLimit{MaxVisits: 1},
+					Next:     []*models.NextStep{{Operation: "submit", Step: "review"}},
+				},
+				"end": {Step: "end"},
+			},
+			ops:        []op{reject, submit, jump},
+			wantStatus: models.Rejected,
+			wantStep:   "review",
+			wantVisits: map[string]int{"revise": 1, "review": 1},
+			wantTraversals: map[string]int{
+				models.EdgeKey("review", "revise"): 1,
+				models.EdgeKey("revise", "review"): 1,
+			},
+			wantEvents: []string{models.EventRejected, models.EventOverridden, models.EventLoopLimited, models.EventJumped},
+		},
+		{
+			name: "service step counts toward step limit",
+			stepConfig: map[string]*models.StepConfig{
+				"review": {
+					Step:     "review",
+					Operator: []string{"reviewer"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Limit:    models.LoopLimit{MaxVisits: 1},
+					Next: []*models.NextStep{
+						{Operation: "approve", Step: "end"},
+						{Operation: models.Reject, Step: "revise"},
+					},
+				},
+				"revise": {
+					Step:     "revise",
+					Operator: []string{"applicant"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "submit", Step: "check"}},
+				},
+				"check": {
+					Step:    "check",
+					Kind:    models.StepKindService,
+					Service: models.ServiceTask{Handler: "check"},
+					Next:    []*models.NextStep{{Operation: "ok", Step: "review"}},
+				},
+				"end": {Step: "end"},
+			},
+			ops: []op{
+				reject, {operation: "submit", operator: "applicant", next: "check"},
+				reject, {operation: "submit", operator: "applicant", next: "check"},
+			},
+			wantStatus: models.Rejected,
+			wantStep:   "check",
+			wantVisits: map[string]int{"revise": 2, "check": 2, "review": 1},
+			wantTraversals: map[string]int{
+				models.EdgeKey("review", "revise"): 2,
+				models.EdgeKey("revise", "check"):  2,
+				models.EdgeKey("check", "review"):  1,
+			},
+			wantEvents: []string{models.EventStepLeft, models.EventStepEntered, models.EventRejected, models.EventLoopLimited},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Helper{}
+			h.SetAuthorizer(Capabilities{"ops": {models.CapJump}})
+			h.RegisterHandler("check", ServiceHandlerFunc(func(context.Context, *models.Ticket) (string, error) {
+				return "ok", nil
+			}))
+			ticket := &models.Ticket{Uid: "t1", Status: models.Running, Step: "review", Operator: []string{"reviewer"}}
+			var (
+				tr  *models.Transition
+				err error
+			)
+			for _, o := range tt.ops {
+				if o.operation == models.Jump {
+					tr, err = h.Jump(o.operator, o.next, "again", []string{"end"}, ticket, tt.stepConfig)
+				} else {
+					tr, err = h.Transit(o.next, o.operation, o.operator, false, []string{"end"}, ticket, tt.stepConfig)
+				}
+				if err != nil {
+					break
+				}
+				ticket = tr.Ticket
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Transit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if ticket.Status != tt.wantStatus || ticket.Step != tt.wantStep {
+				t.Errorf("ticket status %s step %s, want %s %s", ticket.Status, ticket.Step, tt.wantStatus, tt.wantStep)
+			}
+			if diff := cmp.Diff(ticket.Operator, tt.wantOperator); len(diff) > 0 {
+				t.Errorf("operator diff = %v", diff)
+			}
+			if diff := cmp.Diff(ticket.Visits, tt.wantVisits); len(diff) > 0 {
+				t.Errorf("Visits diff = %v", diff)
+			}
+			if diff := cmp.Diff(ticket.Traversals, tt.wantTraversals); len(diff) > 0 {
+				t.Errorf("Traversals diff = %v", diff)
+			}
+			if diff := cmp.Diff(tr.Events, tt.wantEvents); len(diff) > 0 {
+				t.Errorf("events diff = %v", diff)
+			}
+		})
+	}
+}
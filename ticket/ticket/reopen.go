@@ -0,0 +1,78 @@
+package ticket
+
+import (
+	"github.com/victorwong171/punched-tape/models"
+
+	"github.com/victorwong171/go-utils/desc/set"
+	"github.com/victorwong171/go-utils/utils"
+)
+
+// Reopen 按模板的重新打开策略将已结束的工单恢复为running，target为空时回到开始步骤
+// 处理人按目标步骤配置重置，操作记录保留，Ticket.Reopened加一
+func (h *Helper) Reopen(
+	actor,
+	target,
+	reason string,
+	tpl *models.TicketTemplate,
+	ticket *models.Ticket,
+	opts ...ApprovalOption) (*models.Transition, error) {
+	if ticket == nil {
+		return nil, newApprovalError(ErrMissingArguments, "", "", actor, models.Reopen, target)
+	}
+	if tpl == nil {
+		return nil, newApprovalError(ErrMissingArguments, ticket.Uid, ticket.Step, actor, models.Reopen, target)
+	}
+	if len(target) == 0 {
+		target = tpl.StartStep
+	}
+	fail := func(err error) (*models.Transition, error) {
+		return nil, newApprovalError(err, ticket.Uid, ticket.Step, actor, models.Reopen, target)
+	}
+	if len(actor) == 0 {
+		return fail(ErrMissingArguments)
+	}
+	if ticket.Status == models.Running {
+		return fail(ErrTicketRunning)
+	}
+	policy := tpl.Reopen
+	if !policy.Allows(ticket.Status) {
+		return fail(ErrReopenNotAllowed)
+	}
+	if len(policy.Operators) > 0 && !utils.Contain(policy.Operators, actor) {
+		return fail(ErrReopenNotAllowed)
+	}
+	if policy.MaxTimes > 0 && ticket.Reopened >= policy.MaxTimes {
+		return fail(ErrReopenLimit)
+	}
+	stepConfig := tpl.StepConfigMap()
+	nextStep := stepConfig[target]
+	if nextStep == nil || set.Setify(tpl.EndStep...).HasKey(target) {
+		return fail(ErrBadReopenStep)
+	}
+	options := newApprovalOptions(opts)
+
+	updated := ticket.Clone()
+	updated.Status = models.Running
+	updated.Reopened++
+	updated.Tally = nil
+	// 重新打开后重新计算环路次数，避免因上一轮的次数立即再次超限
+	updated.Visits = nil
+	updated.Traversals = nil
+	updated = updateTicket(updated, nextStep, tpl.EndStep)
+	updated.AddCC(options.cc...)
+	action := &models.Action{
+		Operator:  actor,
+		Operation: models.Reopen,
+		Step:      ticket.Step,
+		Next:      target,
+		Comment:   reason,
+		CreatedAt: options.at,
+	}
+	tr, err := h.settle(ticket, updated, stepConfig[ticket.Step], stepConfig, tpl.EndStep, action, options, true)
+	if err != nil {
+		return nil, err
+	}
+	tr.Events = append(tr.Events, models.EventReopened)
+	h.notify(tr, options)
+	return tr, nil
+}
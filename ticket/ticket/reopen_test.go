@@ -0,0 +1,187 @@
+package ticket
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func TestHelper_Reopen(t *testing.T) {
+	config := []*models.StepConfig{
+		{
+			Step:     "draft",
+			Operator: []string{"applicant"},
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "submit", Step: "review"}},
+		},
+		{
+			Step:     "review",
+			State:    "in_review",
+			Operator: []string{"r1"},
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Limit:    models.LoopLimit{MaxVisits: 1},
+			Next: []*models.NextStep{
+				{Operation: "approve", Step: "end"},
+				{Operation: models.Reject, Step: "rejected"},
+			},
+		},
+		{Step: "end"},
+		{Step: "rejected", EndStatus: models.Rejected},
+	}
+	rejected := &models.Ticket{
+		Uid:      "t1",
+		Status:   models.Rejected,
+		Step:     "rejected",
+		Reopened: 1,
+		History:  []*models.Action{{Operator: "r1", Operation: models.Reject, Step: "review", Next: "rejected"}},
+	}
+	tests := []struct {
+		name           string
+		policy         models.ReopenPolicy
+		actor          string
+		target         string
+		ticket         *models.Ticket
+		wantStep       string
+		wantState      string
+		wantOperator   []string
+		wantVisits     map[string]int
+		wantTraversals map[string]int
+		wantErr        error
+	}{
+		{
+			name:    "policy forbids",
+			actor:   "applicant",
+			ticket:  rejected,
+			wantErr: ErrReopenNotAllowed,
+		},
+		{
+			name:    "status not reopenable",
+			policy:  models.ReopenPolicy{Statuses: []string{models.Passed}},
+			actor:   "applicant",
+			ticket:  rejected,
+			wantErr: ErrReopenNotAllowed,
+		},
+		{
+			name:    "operator not allowed",
+			policy:  models.ReopenPolicy{Statuses: []string{models.Rejected}, Operators: []string{"hr"}},
+			actor:   "applicant",
+			ticket:  rejected,
+			wantErr: ErrReopenNotAllowed,
+		},
+		{
+			name:    "limit reached",
+			policy:  models.ReopenPolicy{Statuses: []string{models.Rejected}, MaxTimes: 1},
+			actor:   "applicant",
+			ticket:  rejected,
+			wantErr: ErrReopenLimit,
+		},
+		{
+			name:    "end step target",
+			policy:  models.ReopenPolicy{Statuses: []string{models.Rejected}},
+			actor:   "applicant",
+			target:  "end",
+			ticket:  rejected,
+			wantErr: ErrBadReopenStep,
+		},
+		{
+			name:    "still running",
+			policy:  models.ReopenPolicy{Statuses: []string{models.Rejected}},
+			actor:   "applicant",
+			ticket:  &models.Ticket{Status: models.Running, Step: "review"},
+			wantErr: ErrTicketRunning,
+		},
+		{
+			name:           "start step by default",
+			policy:         models.ReopenPolicy{Statuses: []string{models.Rejected}, MaxTimes: 2},
+			actor:          "applicant",
+			ticket:         rejected,
+			wantStep:       "draft",
+			wantOperator:   []string{"applicant"},
+			wantVisits:     map[string]int{"draft": 1},
+			wantTraversals: map[string]int{models.EdgeKey("rejected", "draft"): 1},
+		},
+		{
+			name:           "explicit target",
+			policy:         models.ReopenPolicy{Statuses: []string{models.Rejected}},
+			actor:          "applicant",
+			target:         "review",
+			ticket:         rejected,
+			wantStep:       "review",
+			wantState:      "in_review",
+			wantOperator:   []string{"r1"},
+			wantVisits:     map[string]int{"review": 1},
+			wantTraversals: map[string]int{models.EdgeKey("rejected", "review"): 1},
+		},
+		{
+			name:   "loop counters restart",
+			policy: models.ReopenPolicy{Statuses: []string{models.Rejected}},
+			actor:  "applicant",
+			ticket: &models.Ticket{
+				Uid:        "t1",
+				Status:     models.Rejected,
+				Step:       "draft",
+				Visits:     map[string]int{"draft": 1, "review": 1},
+				Traversals: map[string]int{models.EdgeKey("draft", "review"): 1, models.EdgeKey("review", "draft"): 1},
+				History: []*models.Action{
+					{Operator: "applicant", Operation: "submit", Step: "draft", Next: "review"},
+					{Operator: "r1", Operation: models.Reject, Step: "review", Next: "draft"},
+					{Operator: "applicant", Operation: "submit", Step: "draft", Next: "review"},
+				},
+			},
+			wantStep:       "draft",
+			wantOperator:   []string{"applicant"},
+			wantVisits:     map[string]int{"draft": 1},
+			wantTraversals: map[string]int{models.EdgeKey("draft", "draft"): 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Helper{}
+			tpl := &models.TicketTemplate{
+				Uid:       "expense",
+				StartStep: "draft",
+				EndStep:   []string{"end", "rejected"},
+				Reopen:    tt.policy,
+				Config:    config,
+			}
+			input := tt.ticket.Clone()
+			tr, err := h.Reopen(tt.actor, tt.target, "receipts attached", tpl, tt.ticket)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Reopen() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := tr.Ticket
+			if got.Status != models.Running || got.Step != tt.wantStep || got.State != tt.wantState ||
+				got.Reopened != tt.ticket.Reopened+1 || len(got.History) != len(tt.ticket.History)+1 {
+				t.Errorf("Reopen() ticket = %+v", got)
+			}
+			if diff := cmp.Diff(got.Operator, tt.wantOperator); len(diff) > 0 {
+				t.Errorf("Reopen() operator diff = %v", diff)
+			}
+			if diff := cmp.Diff(got.Visits, tt.wantVisits); len(diff) > 0 {
+				t.Errorf("Reopen() visits diff = %v", diff)
+			}
+			if diff := cmp.Diff(got.Traversals, tt.wantTraversals); len(diff) > 0 {
+				t.Errorf("Reopen() traversals diff = %v", diff)
+			}
+			if !tr.HasEvent(models.EventReopened) {
+				t.Errorf("Reopen() events = %v", tr.Events)
+			}
+			if diff := cmp.Diff(tt.ticket, input); len(diff) > 0 {
+				t.Errorf("Reopen() modified input ticket: %v", diff)
+			}
+
+			// 重新打开后的第一次提交不受上一轮环路次数影响
+			if got.Step == "draft" {
+				tr, err = h.Transit("review", "submit", "applicant", false, tpl.EndStep, got, tpl.StepConfigMap())
+				if err != nil || tr.Ticket.Status != models.Running || tr.Ticket.Step != "review" {
+					t.Errorf("Transit() after reopen = %+v, error = %v", tr, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package ticket
+
+import (
+	"github.com/victorwong171/punched-tape/models"
+)
+
+// recordVote 在工单计票结果中记录签署人的选择，进入新步骤时重新计票
+func recordVote(ticket *models.Ticket, step, nextStep *models.StepConfig, operator, operation string, weight int) {
+	var next string
+	if nextStep != nil {
+		next = nextStep.Step
+	}
+	if ticket.Tally == nil || ticket.Tally.Step != ticket.Step {
+		ticket.Tally = &models.Tally{Step: ticket.Step, Threshold: step.GetDisposal().WeightThreshold}
+	}
+	ticket.Tally.AddVote(&models.Vote{
+		Operator:  operator,
+		Operation: operation,
+		Next:      next,
+		Weight:    weight,
+	})
+}
+
+// resolveNext 多人审批步骤完成时，按Disposal.Resolution从全部签署人的选择中确定下一步骤
+// 选出的步骤不存在时沿用最后一名签署人的选择
+func resolveNext(tally *models.Tally, step, last *models.StepConfig, stepConfig map[string]*models.StepConfig) *models.StepConfig {
+	var next string
+	switch step.GetDisposal().Resolution {
+	case models.ResolveMajority:
+		next = majorityNext(tally, step)
+	case models.ResolveStrictest:
+		next = strictestNext(tally, step)
+	}
+	if resolved := stepConfig[next]; resolved != nil {
+		return resolved
+	}
+	return last
+}
+
+// majorityNext 得票权重最多的下一步骤，票数相同时取NextStep配置中靠前的
+func majorityNext(tally *models.Tally, step *models.StepConfig) string {
+	weights := tally.CountByNext()
+	var (
+		best       string
+		bestWeight = -1
+	)
+	for _, n := range step.GetNext() {
+		if w, ok := weights[n.Step]; ok && w > bestWeight {
+			best, bestWeight = n.Step, w
+		}
+	}
+	return best
+}
+
+// strictestNext 有人驳回时取第一个驳回的选择，否则取NextStep配置中最靠前的选择
+func strictestNext(tally *models.Tally, step *models.StepConfig) string {
+	for _, v := range tally.GetVotes() {
+		if v.Operation == models.Reject {
+			return v.Next
+		}
+	}
+	weights := tally.CountByNext()
+	for _, n := range step.GetNext() {
+		if _, ok := weights[n.Step]; ok {
+			return n.Step
+		}
+	}
+	return ""
+}
+
+// rejectUnreachable 剩余操作人全部同意也无法达到阈值时驳回，避免工单停留在无人可签的步骤
+// 优先进入步骤配置的驳回分支，未配置时工单在当前步骤结束为驳回
+func rejectUnreachable(ticket *models.Ticket, step *models.StepConfig, stepConfig map[string]*models.StepConfig, endStep []string) *models.Ticket {
+	for _, n := range step.GetNext() {
+		if rejectStep := stepConfig[n.Step]; n.Operation == models.Reject && rejectStep != nil {
+			return updateTicket(ticket, rejectStep, endStep)
+		}
+	}
+	ticket.Status = models.Rejected
+	ticket.Operator = nil
+	ticket.OperatedUser = nil
+	ticket.RejectedUser = nil
+	return ticket
+}
@@ -0,0 +1,102 @@
+package ticket
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func TestHelper_ApprovalResolution(t *testing.T) {
+	newStepConfig := func(signType, resolution string) map[string]*models.StepConfig {
+		return map[string]*models.StepConfig{
+			"review": {
+				Step:     "review",
+				Disposal: models.Disposal{SignType: signType, JointSignRate: 1, Resolution: resolution},
+				Next: []*models.NextStep{
+					{Operation: models.Reject, Step: "back"},
+					{Operation: "escalate", Step: "finance"},
+					{Operation: "approve", Step: "end"},
+				},
+			},
+			"back":    {Step: "back", Operator: []string{"applicant"}, Next: []*models.NextStep{{Operation: "submit", Step: "review"}}},
+			"finance": {Step: "finance", Operator: []string{"cfo"}, Next: []*models.NextStep{{Operation: "approve", Step: "end"}}},
+			"end":     {Step: "end"},
+		}
+	}
+	type decision struct {
+		operator, operation, next string
+	}
+	tests := []struct {
+		name       string
+		signType   string
+		resolution string
+		decisions  []decision
+		want       string
+	}{
+		{
+			name:      "last by default",
+			signType:  models.SerialSign,
+			decisions: []decision{{"u1", "escalate", "finance"}, {"u2", "escalate", "finance"}, {"u3", "approve", "end"}},
+			want:      "end",
+		},
+		{
+			name:       "majority",
+			signType:   models.SerialSign,
+			resolution: models.ResolveMajority,
+			decisions:  []decision{{"u1", "escalate", "finance"}, {"u2", "escalate", "finance"}, {"u3", "approve", "end"}},
+			want:       "finance",
+		},
+		{
+			name:       "majority tie prefers configured order",
+			signType:   models.JointlySign,
+			resolution: models.ResolveMajority,
+			decisions:  []decision{{"u1", "approve", "end"}, {"u2", "escalate", "finance"}},
+			want:       "finance",
+		},
+		{
+			name:       "strictest without reject",
+			signType:   models.SerialSign,
+			resolution: models.ResolveStrictest,
+			decisions:  []decision{{"u1", "approve", "end"}, {"u2", "escalate", "finance"}, {"u3", "approve", "end"}},
+			want:       "finance",
+		},
+		{
+			name:       "strictest with reject",
+			signType:   models.SerialSign,
+			resolution: models.ResolveStrictest,
+			decisions:  []decision{{"u1", "approve", "end"}, {"u2", models.Reject, "back"}, {"u3", "approve", "end"}},
+			want:       "back",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stepConfig := newStepConfig(tt.signType, tt.resolution)
+			ticket := &models.Ticket{Status: models.Running, Step: "review"}
+			for _, d := range tt.decisions {
+				ticket.Operator = append(ticket.Operator, d.operator)
+			}
+			h := &Helper{}
+			var err error
+			for _, d := range tt.decisions {
+				if ticket, err = h.Approval(d.next, d.operation, d.operator, false, []string{"end"}, ticket, stepConfig); err != nil {
+					t.Fatalf("Approval() error = %v", err)
+				}
+			}
+			if ticket.Step != tt.want {
+				t.Errorf("Approval() step = %v, want %v", ticket.Step, tt.want)
+			}
+			var got []string
+			for _, v := range ticket.Tally.GetVotes() {
+				got = append(got, v.Operator+":"+v.Operation)
+			}
+			var want []string
+			for _, d := range tt.decisions {
+				want = append(want, d.operator+":"+d.operation)
+			}
+			if diff := cmp.Diff(got, want); len(diff) > 0 {
+				t.Errorf("Tally votes diff = %v", diff)
+			}
+		})
+	}
+}
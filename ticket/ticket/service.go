@@ -0,0 +1,199 @@
+package ticket
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/victorwong171/punched-tape/models"
+)
+
+// maxServiceHops 一次操作中最多连续执行的自动步骤数，防止配置成环时无限执行
+const maxServiceHops = 32
+
+// ServiceHandler 自动步骤处理器，返回的结果用于在步骤Next中按Operation选择下一步骤
+// 处理器收到的是工单副本，修改不会生效
+type ServiceHandler interface {
+	Handle(ctx context.Context, ticket *models.Ticket) (string, error)
+}
+
+// ServiceHandlerFunc 将普通函数适配为ServiceHandler
+type ServiceHandlerFunc func(ctx context.Context, ticket *models.Ticket) (string, error)
+
+func (f ServiceHandlerFunc) Handle(ctx context.Context, ticket *models.Ticket) (string, error) {
+	return f(ctx, ticket)
+}
+
+// RegisterHandler 注册自动步骤处理器，工单进入StepConfig.Service.Handler为name的步骤时执行
+func (h *Helper) RegisterHandler(name string, handler ServiceHandler) {
+	if h.handlers == nil {
+		h.handlers = make(map[string]ServiceHandler)
+	}
+	h.handlers[name] = handler
+}
+
+// RunService 执行工单当前所在的自动步骤，用于处理器注册前进入自动步骤的工单
+func (h *Helper) RunService(
+	ticket *models.Ticket,
+	endStep []string,
+	stepConfig map[string]*models.StepConfig,
+	opts ...ApprovalOption) (*models.Transition, error) {
+	if ticket == nil {
+		return nil, newApprovalError(ErrMissingArguments, "", "", models.SystemOperator, "", "")
+	}
+	fail := func(err error) (*models.Transition, error) {
+		return nil, newApprovalError(err, ticket.Uid, ticket.Step, models.SystemOperator, "", "")
+	}
+	step, err := checkService(ticket, stepConfig)
+	if err != nil {
+		return fail(err)
+	}
+	handler := h.handlers[step.Service.Handler]
+	if handler == nil {
+		return fail(fmt.Errorf("%w: %s", ErrHandlerNotFound, step.Service.Handler))
+	}
+	options := newApprovalOptions(opts)
+	action, nextStep, err := h.execute(handler, ticket, step, stepConfig, options)
+	if err != nil {
+		return fail(err)
+	}
+	tr, err := h.settle(ticket, updateTicket(ticket.Clone(), nextStep, endStep), step, stepConfig, endStep, action, options, true)
+	if err != nil {
+		return nil, err
+	}
+	h.notify(tr, options)
+	return tr, nil
+}
+
+// CompleteService 以处理器在引擎外得到的结果推进自动步骤，不调用处理器
+// operation为处理器结果，失败时为models.ServiceError并以comment记录原因；用于异步执行处理器与事件回放
+func (h *Helper) CompleteService(
+	operation,
+	comment string,
+	endStep []string,
+	ticket *models.Ticket,
+	stepConfig map[string]*models.StepConfig,
+	opts ...ApprovalOption) (*models.Transition, error) {
+	if ticket == nil {
+		return nil, newApprovalError(ErrMissingArguments, "", "", models.SystemOperator, operation, "")
+	}
+	fail := func(err error) (*models.Transition, error) {
+		return nil, newApprovalError(err, ticket.Uid, ticket.Step, models.SystemOperator, operation, "")
+	}
+	if len(operation) == 0 {
+		return fail(ErrMissingArguments)
+	}
+	step, err := checkService(ticket, stepConfig)
+	if err != nil {
+		return fail(err)
+	}
+	nextStep := serviceNext(step, stepConfig, operation)
+	if operation == models.ServiceError {
+		nextStep = stepConfig[step.Service.ErrorStep]
+	}
+	if nextStep == nil {
+		return fail(ErrNextStepNotAllowed)
+	}
+	options := newApprovalOptions(opts)
+	action := &models.Action{
+		Operator:  models.SystemOperator,
+		Operation: operation,
+		Step:      ticket.Step,
+		Next:      nextStep.Step,
+		Comment:   comment,
+		CreatedAt: options.at,
+	}
+	tr, err := h.settle(ticket, updateTicket(ticket.Clone(), nextStep, endStep), step, stepConfig, endStep, action, options, true)
+	if err != nil {
+		return nil, err
+	}
+	h.notify(tr, options)
+	return tr, nil
+}
+
+// checkService 校验工单停留在自动步骤，返回步骤配置
+func checkService(ticket *models.Ticket, stepConfig map[string]*models.StepConfig) (*models.StepConfig, error) {
+	if ticket.Status != models.Running {
+		return nil, ErrTicketNotRunning
+	}
+	step := stepConfig[ticket.Step]
+	if step == nil {
+		return nil, ErrStepNotFound
+	}
+	if !step.IsService() {
+		return nil, ErrNotServiceStep
+	}
+	return step, nil
+}
+
+// serviceNext 按处理器结果选择下一步骤，无对应步骤时返回nil
+func serviceNext(step *models.StepConfig, stepConfig map[string]*models.StepConfig, result string) *models.StepConfig {
+	for _, n := range step.GetNext() {
+		if n.Operation == result && stepConfig[n.Step] != nil {
+			return stepConfig[n.Step]
+		}
+	}
+	return nil
+}
+
+// execute 执行处理器，失败时按指数退避重试，重试耗尽后进入错误步骤
+// 上下文取消时停止重试并返回错误，不进入错误步骤
+func (h *Helper) execute(
+	handler ServiceHandler,
+	ticket *models.Ticket,
+	step *models.StepConfig,
+	stepConfig map[string]*models.StepConfig,
+	options *approvalOptions) (*models.Action, *models.StepConfig, error) {
+	service := step.Service
+	var (
+		result string
+		err    error
+	)
+	for attempt := 0; attempt <= service.Retries; attempt++ {
+		if attempt > 0 && service.Backoff > 0 {
+			h.wait(options.ctx, service.Backoff<<(attempt-1))
+		}
+		if err = options.ctx.Err(); err != nil {
+			return nil, nil, fmt.Errorf("%w: %s: %w", ErrServiceFailed, service.Handler, err)
+		}
+		result, err = handler.Handle(options.ctx, ticket.Clone())
+		if err == nil {
+			break
+		}
+	}
+	action := &models.Action{
+		Operator:  models.SystemOperator,
+		Operation: result,
+		Step:      ticket.Step,
+		CreatedAt: options.at,
+	}
+	if err == nil {
+		if nextStep := serviceNext(step, stepConfig, result); nextStep != nil {
+			action.Next = nextStep.Step
+			return action, nextStep, nil
+		}
+		// 结果无对应的下一步骤属于配置错误，不再重试
+		err = fmt.Errorf("%w: handler %s returned %q", ErrNextStepNotAllowed, service.Handler, result)
+	}
+	if errorStep := stepConfig[service.ErrorStep]; errorStep != nil {
+		action.Operation = models.ServiceError
+		action.Next = errorStep.Step
+		action.Comment = err.Error()
+		return action, errorStep, nil
+	}
+	return nil, nil, fmt.Errorf("%w: %s: %v", ErrServiceFailed, service.Handler, err)
+}
+
+// wait 重试前等待，上下文取消时提前返回
+func (h *Helper) wait(ctx context.Context, d time.Duration) {
+	if h.sleep != nil {
+		h.sleep(d)
+		return
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
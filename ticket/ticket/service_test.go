@@ -0,0 +1,477 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func TestHelper_ServiceStep(t *testing.T) {
+	errBoom := errors.New("boom")
+	at := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		results    []string
+		errs       []error
+		cancel     bool
+		stepConfig map[string]*models.StepConfig
+		wantStep   string
+		wantCalls  int
+		wantSleep  []time.Duration
+		wantErr    error
+	}{
+		{
+			name:    "result selects next step",
+			results: []string{"exists"},
+			errs:    []error{nil},
+			stepConfig: map[string]*models.StepConfig{
+				"apply": {
+					Step:     "apply",
+					Operator: []string{"u1"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "submit", Step: "provision"}},
+				},
+				"provision": {
+					Step:    "provision",
+					Kind:    models.StepKindService,
+					Service: models.ServiceTask{Handler: "provision-account"},
+					Next: []*models.NextStep{
+						{Operation: "created", Step: "end"},
+						{Operation: "exists", Step: "review"},
+					},
+				},
+				"review": {
+					Step:     "review",
+					Operator: []string{"admin"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "approve", Step: "end"}},
+				},
+				"end": {Step: "end"},
+			},
+			wantStep:  "review",
+			wantCalls: 1,
+		},
+		{
+			name:    "retry with backoff",
+			results: []string{"", "", "created"},
+			errs:    []error{errBoom, errBoom, nil},
+			stepConfig: map[string]*models.StepConfig{
+				"apply": {
+					Step:     "apply",
+					Operator: []string{"u1"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "submit", Step: "provision"}},
+				},
+				"provision": {
+					Step:    "provision",
+					Kind:    models.StepKindService,
+					Service: models.ServiceTask{Handler: "provision-account", Retries: 2, Backoff: time.Second},
+					Next:    []*models.NextStep{{Operation: "created", Step: "end"}},
+				},
+				"end": {Step: "end"},
+			},
+			wantStep:  "end",
+			wantCalls: 3,
+			wantSleep: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:    "routed to error step",
+			results: []string{"", "", ""},
+			errs:    []error{errBoom, errBoom, errBoom},
+			stepConfig: map[string]*models.StepConfig{
+				"apply": {
+					Step:     "apply",
+					Operator: []string{"u1"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "submit", Step: "provision"}},
+				},
+				"provision": {
+					Step: "provision",
+					Kind: models.StepKindService,
+					Service: models.ServiceTask{
+						Handler:   "provision-account",
+						Retries:   2,
+						Backoff:   time.Second,
+						ErrorStep: "manual",
+					},
+					Next: []*models.NextStep{{Operation: "created", Step: "end"}},
+				},
+				"manual": {
+					Step:     "manual",
+					Operator: []string{"ops"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "done", Step: "end"}},
+				},
+				"end": {Step: "end"},
+			},
+			wantStep:  "manual",
+			wantCalls: 3,
+			wantSleep: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:    "failed without error step",
+			results: []string{"", "", ""},
+			errs:    []error{errBoom, errBoom, errBoom},
+			stepConfig: map[string]*models.StepConfig{
+				"apply": {
+					Step:     "apply",
+					Operator: []string{"u1"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "submit", Step: "provision"}},
+				},
+				"provision": {
+					Step:    "provision",
+					Kind:    models.StepKindService,
+					Service: models.ServiceTask{Handler: "provision-account", Retries: 2, Backoff: time.Second},
+					Next:    []*models.NextStep{{Operation: "created", Step: "end"}},
+				},
+				"end": {Step: "end"},
+			},
+			wantCalls: 3,
+			wantSleep: []time.Duration{time.Second, 2 * time.Second},
+			wantErr:   ErrServiceFailed,
+		},
+		{
+			name:    "unknown result",
+			results: []string{"weird"},
+			errs:    []error{nil},
+			stepConfig: map[string]*models.StepConfig{
+				"apply": {
+					Step:     "apply",
+					Operator: []string{"u1"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "submit", Step: "provision"}},
+				},
+				"provision": {
+					Step:    "provision",
+					Kind:    models.StepKindService,
+					Service: models.ServiceTask{Handler: "provision-account", ErrorStep: "manual"},
+					Next:    []*models.NextStep{{Operation: "created", Step: "end"}},
+				},
+				"manual": {
+					Step:     "manual",
+					Operator: []string{"ops"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "done", Step: "end"}},
+				},
+				"end": {Step: "end"},
+			},
+			wantStep:  "manual",
+			wantCalls: 1,
+		},
+		{
+			name:   "cancelled context is not routed to error step",
+			cancel: true,
+			stepConfig: map[string]*models.StepConfig{
+				"apply": {
+					Step:     "apply",
+					Operator: []string{"u1"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "submit", Step: "provision"}},
+				},
+				"provision": {
+					Step:    "provision",
+					Kind:    models.StepKindService,
+					Service: models.ServiceTask{Handler: "provision-account", ErrorStep: "manual"},
+					Next:    []*models.NextStep{{Operation: "created", Step: "end"}},
+				},
+				"manual": {
+					Step:     "manual",
+					Operator: []string{"ops"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "done", Step: "end"}},
+				},
+				"end": {Step: "end"},
+			},
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				calls int
+				slept []time.Duration
+			)
+			h := &Helper{sleep: func(d time.Duration) { slept = append(slept, d) }}
+			h.RegisterHandler("provision-account", ServiceHandlerFunc(func(_ context.Context, ticket *models.Ticket) (string, error) {
+				if ticket.Step != "provision" {
+					t.Errorf("handler got ticket at step %s", ticket.Step)
+				}
+				calls++
+				return tt.results[calls-1], tt.errs[calls-1]
+			}))
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+			ticket := &models.Ticket{Uid: "T1", Status: models.Running, Step: "apply", Operator: []string{"u1"}}
+			tr, err := h.Transit("provision", "submit", "u1", false, []string{"end"}, ticket, tt.stepConfig,
+				WithContext(ctx), WithTime(at))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Transit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("handler calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if diff := cmp.Diff(slept, tt.wantSleep); len(diff) > 0 {
+				t.Errorf("backoff diff = %v", diff)
+			}
+			if err != nil {
+				return
+			}
+			if tr.Ticket.Step != tt.wantStep || tr.To != tt.wantStep || tr.From != "apply" {
+				t.Errorf("Transit() from %s to %s, ticket step %s, want %s", tr.From, tr.To, tr.Ticket.Step, tt.wantStep)
+			}
+			last := tr.Ticket.History[len(tr.Ticket.History)-1]
+			if last.Operator != models.SystemOperator || last.Step != "provision" || last.Next != tt.wantStep || !last.CreatedAt.Equal(at) {
+				t.Errorf("last action = %+v", last)
+			}
+		})
+	}
+}
+
+func TestHelper_RunService(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    ServiceHandler
+		ticket     *models.Ticket
+		stepConfig map[string]*models.StepConfig
+		wantStatus string
+		wantErr    error
+	}{
+		{
+			name:   "handler not registered",
+			ticket: &models.Ticket{Uid: "T1", Status: models.Running, Step: "provision"},
+			stepConfig: map[string]*models.StepConfig{
+				"provision": {
+					Step:    "provision",
+					Kind:    models.StepKindService,
+					Service: models.ServiceTask{Handler: "provision-account"},
+					Next:    []*models.NextStep{{Operation: "created", Step: "end"}},
+				},
+				"end": {Step: "end"},
+			},
+			wantErr: ErrHandlerNotFound,
+		},
+		{
+			name: "not a service step",
+			handler: ServiceHandlerFunc(func(context.Context, *models.Ticket) (string, error) {
+				return "created", nil
+			}),
+			ticket: &models.Ticket{Uid: "T1", Status: models.Running, Step: "apply", Operator: []string{"u1"}},
+			stepConfig: map[string]*models.StepConfig{
+				"apply": {
+					Step:     "apply",
+					Operator: []string{"u1"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "submit", Step: "end"}},
+				},
+				"end": {Step: "end"},
+			},
+			wantErr: ErrNotServiceStep,
+		},
+		{
+			name: "result selects next step",
+			handler: ServiceHandlerFunc(func(context.Context, *models.Ticket) (string, error) {
+				return "created", nil
+			}),
+			ticket: &models.Ticket{Uid: "T1", Status: models.Running, Step: "provision"},
+			stepConfig: map[string]*models.StepConfig{
+				"provision": {
+					Step:    "provision",
+					Kind:    models.StepKindService,
+					Service: models.ServiceTask{Handler: "provision-account"},
+					Next:    []*models.NextStep{{Operation: "created", Step: "end"}},
+				},
+				"end": {Step: "end"},
+			},
+			wantStatus: models.Passed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Helper{}
+			if tt.handler != nil {
+				h.RegisterHandler("provision-account", tt.handler)
+			}
+			tr, err := h.RunService(tt.ticket, []string{"end"}, tt.stepConfig)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RunService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (tr.Ticket.Status != tt.wantStatus || !tr.HasEvent(models.EventPassed)) {
+				t.Errorf("RunService() = %+v, want %s", tr.Ticket, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHelper_ServiceStepWaiting(t *testing.T) {
+	stepConfig := map[string]*models.StepConfig{
+		"apply": {
+			Step:     "apply",
+			Operator: []string{"u1"},
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "submit", Step: "provision"}},
+		},
+		"provision": {
+			Step:    "provision",
+			Kind:    models.StepKindService,
+			Service: models.ServiceTask{Handler: "provision-account"},
+			Next:    []*models.NextStep{{Operation: "created", Step: "end"}},
+		},
+		"end": {Step: "end"},
+	}
+	h := &Helper{}
+	h.RegisterHandler("provision-account", ServiceHandlerFunc(func(context.Context, *models.Ticket) (string, error) {
+		return "created", nil
+	}))
+	ticket := &models.Ticket{Uid: "T1", Status: models.Running, Step: "apply", Operator: []string{"u1"}}
+
+	preview, err := h.Preview("provision", "submit", "u1", false, []string{"end"}, ticket, stepConfig)
+	if err != nil {
+		t.Fatalf("Preview() error = %v", err)
+	}
+	if preview.To != "provision" || len(preview.Ticket.Operator) != 0 {
+		t.Errorf("Preview() should not run handlers, to = %v", preview.To)
+	}
+	if _, err = h.Approval("end", "created", "u1", true, []string{"end"}, preview.Ticket, stepConfig); !errors.Is(err, ErrAutomaticStep) {
+		t.Errorf("Approval() error = %v, want %v", err, ErrAutomaticStep)
+	}
+}
+
+func TestHelper_CompleteService(t *testing.T) {
+	at := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		operation  string
+		comment    string
+		ticket     *models.Ticket
+		stepConfig map[string]*models.StepConfig
+		wantStep   string
+		wantErr    error
+	}{
+		{
+			name:      "result selects next step",
+			operation: "exists",
+			ticket:    &models.Ticket{Uid: "T1", Status: models.Running, Step: "provision"},
+			stepConfig: map[string]*models.StepConfig{
+				"provision": {
+					Step:    "provision",
+					Kind:    models.StepKindService,
+					Service: models.ServiceTask{Handler: "provision-account"},
+					Next: []*models.NextStep{
+						{Operation: "created", Step: "end"},
+						{Operation: "exists", Step: "review"},
+					},
+				},
+				"review": {
+					Step:     "review",
+					Operator: []string{"admin"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "approve", Step: "end"}},
+				},
+				"end": {Step: "end"},
+			},
+			wantStep: "review",
+		},
+		{
+			name:      "failure routed to error step",
+			operation: models.ServiceError,
+			comment:   "boom",
+			ticket:    &models.Ticket{Uid: "T1", Status: models.Running, Step: "provision"},
+			stepConfig: map[string]*models.StepConfig{
+				"provision": {
+					Step:    "provision",
+					Kind:    models.StepKindService,
+					Service: models.ServiceTask{Handler: "provision-account", ErrorStep: "manual"},
+					Next:    []*models.NextStep{{Operation: "created", Step: "end"}},
+				},
+				"manual": {
+					Step:     "manual",
+					Operator: []string{"ops"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "done", Step: "end"}},
+				},
+				"end": {Step: "end"},
+			},
+			wantStep: "manual",
+		},
+		{
+			name:      "failure without error step",
+			operation: models.ServiceError,
+			ticket:    &models.Ticket{Uid: "T1", Status: models.Running, Step: "provision"},
+			stepConfig: map[string]*models.StepConfig{
+				"provision": {
+					Step:    "provision",
+					Kind:    models.StepKindService,
+					Service: models.ServiceTask{Handler: "provision-account"},
+					Next:    []*models.NextStep{{Operation: "created", Step: "end"}},
+				},
+				"end": {Step: "end"},
+			},
+			wantErr: ErrNextStepNotAllowed,
+		},
+		{
+			name:      "unknown result",
+			operation: "weird",
+			ticket:    &models.Ticket{Uid: "T1", Status: models.Running, Step: "provision"},
+			stepConfig: map[string]*models.StepConfig{
+				"provision": {
+					Step:    "provision",
+					Kind:    models.StepKindService,
+					Service: models.ServiceTask{Handler: "provision-account"},
+					Next:    []*models.NextStep{{Operation: "created", Step: "end"}},
+				},
+				"end": {Step: "end"},
+			},
+			wantErr: ErrNextStepNotAllowed,
+		},
+		{
+			name:      "not a service step",
+			operation: "created",
+			ticket:    &models.Ticket{Uid: "T1", Status: models.Running, Step: "apply", Operator: []string{"u1"}},
+			stepConfig: map[string]*models.StepConfig{
+				"apply": {
+					Step:     "apply",
+					Operator: []string{"u1"},
+					Disposal: models.Disposal{SignType: models.AnyoneSign},
+					Next:     []*models.NextStep{{Operation: "created", Step: "end"}},
+				},
+				"end": {Step: "end"},
+			},
+			wantErr: ErrNotServiceStep,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Helper{}
+			h.RegisterHandler("provision-account", ServiceHandlerFunc(func(context.Context, *models.Ticket) (string, error) {
+				t.Errorf("CompleteService() must not run the handler")
+				return "", nil
+			}))
+			tr, err := h.CompleteService(tt.operation, tt.comment, []string{"end"}, tt.ticket, tt.stepConfig, WithTime(at))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CompleteService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			want := &models.Action{
+				Operator:  models.SystemOperator,
+				Operation: tt.operation,
+				Step:      "provision",
+				Next:      tt.wantStep,
+				Comment:   tt.comment,
+				CreatedAt: at,
+			}
+			if diff := cmp.Diff(tr.Action, want); len(diff) > 0 || tr.Ticket.Step != tt.wantStep {
+				t.Errorf("CompleteService() step %s, action diff = %v", tr.Ticket.Step, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package ticket
+
+import (
+	"fmt"
+
+	"github.com/victorwong171/punched-tape/models"
+
+	"github.com/victorwong171/go-utils/utils"
+)
+
+// TemplateLookup 按唯一标识查找模板，用于创建子流程工单
+type TemplateLookup func(uid string) (*models.TicketTemplate, error)
+
+// SetTemplateLookup 设置模板查找方法，设置后进入子流程步骤时在Transition.Children中返回新建的子工单
+// 未设置时仅在父工单上记录子工单标识，子工单可通过NewChild创建
+func (h *Helper) SetTemplateLookup(lookup TemplateLookup) {
+	h.templates = lookup
+}
+
+// enterSubProcess 父工单进入子流程步骤，记录子工单标识并等待子工单结束
+func (h *Helper) enterSubProcess(parent *models.Ticket, step *models.StepConfig) (*models.Ticket, error) {
+	parent.Operator = nil
+	parent.AddChildren(childUid(parent, step.Step))
+	if h.templates == nil {
+		return nil, nil
+	}
+	tpl, err := h.templates(step.Template)
+	if err != nil || tpl == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSubProcessTemplate, step.Template)
+	}
+	return NewChild(parent, tpl)
+}
+
+// childUid 子工单标识由父工单标识、步骤名与序号确定，保证事件回放结果一致
+func childUid(parent *models.Ticket, step string) string {
+	return fmt.Sprintf("%s-%s-%d", parent.Uid, step, len(parent.Children)+1)
+}
+
+// NewChild 为处于子流程步骤的父工单创建最近一个子工单
+func NewChild(parent *models.Ticket, tpl *models.TicketTemplate) (*models.Ticket, error) {
+	if parent == nil || len(parent.Children) == 0 || tpl == nil {
+		return nil, ErrMissingArguments
+	}
+	start := tpl.StepConfigMap()[tpl.StartStep]
+	if start == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSubProcessTemplate, tpl.Uid)
+	}
+	return &models.Ticket{
+		Uid:      parent.Children[len(parent.Children)-1],
+		Name:     tpl.Name,
+		Status:   models.Running,
+		Step:     tpl.StartStep,
+		State:    start.State,
+		Operator: append([]string(nil), start.Operator...),
+		Template: tpl.Uid,
+		Parent:   parent.Uid,
+	}, nil
+}
+
+// CompleteChild 子工单结束后推进父工单，下一步骤为父步骤Next中Operation等于子工单状态的步骤
+func (h *Helper) CompleteChild(
+	parent,
+	child *models.Ticket,
+	endStep []string,
+	stepConfig map[string]*models.StepConfig,
+	opts ...ApprovalOption) (*models.Transition, error) {
+	if parent == nil || child == nil {
+		return nil, newApprovalError(ErrMissingArguments, "", "", models.SystemOperator, "", "")
+	}
+	fail := func(err error, next string) (*models.Transition, error) {
+		return nil, newApprovalError(err, parent.Uid, parent.Step, models.SystemOperator, child.Status, next)
+	}
+	if parent.Status != models.Running {
+		return fail(ErrTicketNotRunning, "")
+	}
+	step := stepConfig[parent.Step]
+	if step == nil {
+		return fail(ErrStepNotFound, "")
+	}
+	if !step.IsSubProcess() || child.Parent != parent.Uid || !utils.Contain(parent.Children, child.Uid) {
+		return fail(ErrNotChild, "")
+	}
+	if child.Status == models.Running {
+		return fail(ErrChildRunning, "")
+	}
+	var next string
+	for _, n := range step.GetNext() {
+		if n.Operation == child.Status {
+			next = n.Step
+			break
+		}
+	}
+	if len(next) == 0 {
+		return fail(ErrNextStepNotAllowed, "")
+	}
+	nextStep := stepConfig[next]
+	if nextStep == nil {
+		return fail(ErrNextStepNotFound, next)
+	}
+	options := newApprovalOptions(opts)
+	action := &models.Action{
+		Operator:  models.SystemOperator,
+		Operation: child.Status,
+		Step:      parent.Step,
+		Next:      next,
+		Comment:   child.Uid,
+		CreatedAt: options.at,
+	}
+	tr, err := h.settle(parent, updateTicket(parent.Clone(), nextStep, endStep), step, stepConfig, endStep, action, options, true)
+	if err != nil {
+		return nil, err
+	}
+	h.notify(tr, options)
+	return tr, nil
+}
@@ -0,0 +1,187 @@
+package ticket
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+func TestHelper_SubProcess(t *testing.T) {
+	childTpl := &models.TicketTemplate{
+		Uid:       "background",
+		Name:      "Background check",
+		StartStep: "check",
+		EndStep:   []string{"end"},
+		Config: []*models.StepConfig{
+			{
+				Step:     "check",
+				Operator: []string{"hr"},
+				Disposal: models.Disposal{SignType: models.AnyoneSign},
+				Next:     []*models.NextStep{{Operation: "approve", Step: "end"}},
+			},
+			{Step: "end"},
+		},
+	}
+	stepConfig := map[string]*models.StepConfig{
+		"apply": {
+			Step:     "apply",
+			Operator: []string{"manager"},
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "submit", Step: "background"}},
+		},
+		"background": {
+			Step:     "background",
+			Kind:     models.StepKindSubProcess,
+			Template: "background",
+			Next:     []*models.NextStep{{Operation: models.Passed, Step: "end"}},
+		},
+		"end": {Step: "end"},
+	}
+	tests := []struct {
+		name         string
+		lookup       TemplateLookup
+		wantChildren []*models.Ticket
+		wantErr      error
+	}{
+		{
+			name: "child opened through lookup",
+			lookup: func(uid string) (*models.TicketTemplate, error) {
+				if uid == childTpl.Uid {
+					return childTpl, nil
+				}
+				return nil, errors.New("not found")
+			},
+			wantChildren: []*models.Ticket{{
+				Uid:      "T1-background-1",
+				Name:     "Background check",
+				Status:   models.Running,
+				Step:     "check",
+				Operator: []string{"hr"},
+				Template: "background",
+				Parent:   "T1",
+			}},
+		},
+		{
+			name: "without lookup",
+		},
+		{
+			name:    "lookup fails",
+			lookup:  func(string) (*models.TicketTemplate, error) { return nil, errors.New("not found") },
+			wantErr: ErrSubProcessTemplate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Helper{}
+			if tt.lookup != nil {
+				h.SetTemplateLookup(tt.lookup)
+			}
+			parent := &models.Ticket{Uid: "T1", Status: models.Running, Step: "apply", Operator: []string{"manager"}}
+			tr, err := h.Transit("background", "submit", "manager", false, []string{"end"}, parent, stepConfig)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Transit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tr.Children, tt.wantChildren); len(diff) > 0 {
+				t.Errorf("Transit() children diff = %v", diff)
+			}
+			if !tr.HasEvent(models.EventChildOpened) {
+				t.Errorf("Transit() events = %v", tr.Events)
+			}
+			if diff := cmp.Diff(tr.Ticket.Children, []string{"T1-background-1"}); len(diff) > 0 {
+				t.Errorf("parent children diff = %v", diff)
+			}
+			// 未打开的子工单可由调用方按父工单创建
+			child, err := NewChild(tr.Ticket, childTpl)
+			if err != nil || child.Uid != "T1-background-1" || child.Parent != "T1" {
+				t.Errorf("NewChild() = %+v, error = %v", child, err)
+			}
+		})
+	}
+}
+
+func TestHelper_CompleteChild(t *testing.T) {
+	stepConfig := map[string]*models.StepConfig{
+		"apply": {
+			Step:     "apply",
+			Operator: []string{"manager"},
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "submit", Step: "background"}},
+		},
+		"background": {
+			Step:     "background",
+			Kind:     models.StepKindSubProcess,
+			Template: "background",
+			Next: []*models.NextStep{
+				{Operation: models.Passed, Step: "it"},
+				{Operation: models.Rejected, Step: "apply"},
+			},
+		},
+		"it": {
+			Step:     "it",
+			Operator: []string{"it"},
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "approve", Step: "end"}},
+		},
+		"end": {Step: "end"},
+	}
+	waiting := &models.Ticket{Uid: "T1", Status: models.Running, Step: "background", Children: []string{"T1-background-1"}}
+	tests := []struct {
+		name         string
+		child        *models.Ticket
+		wantStep     string
+		wantOperator []string
+		wantErr      error
+	}{
+		{
+			name:    "child still running",
+			child:   &models.Ticket{Uid: "T1-background-1", Parent: "T1", Status: models.Running},
+			wantErr: ErrChildRunning,
+		},
+		{
+			name:    "not a child",
+			child:   &models.Ticket{Uid: "X", Parent: "T2", Status: models.Passed},
+			wantErr: ErrNotChild,
+		},
+		{
+			name:         "child passed",
+			child:        &models.Ticket{Uid: "T1-background-1", Parent: "T1", Status: models.Passed},
+			wantStep:     "it",
+			wantOperator: []string{"it"},
+		},
+		{
+			name:         "child rejected",
+			child:        &models.Ticket{Uid: "T1-background-1", Parent: "T1", Status: models.Rejected},
+			wantStep:     "apply",
+			wantOperator: []string{"manager"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Helper{}
+			tr, err := h.CompleteChild(waiting, tt.child, []string{"end"}, stepConfig)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CompleteChild() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if tr.Ticket.Step != tt.wantStep || tr.Action.Operator != models.SystemOperator || tr.Action.Operation != tt.child.Status {
+				t.Errorf("CompleteChild() = %+v", tr)
+			}
+			if diff := cmp.Diff(tr.Ticket.Operator, tt.wantOperator); len(diff) > 0 {
+				t.Errorf("CompleteChild() operator diff = %v", diff)
+			}
+		})
+	}
+
+	// 等待子工单的步骤不能人工审批
+	h := &Helper{}
+	if _, err := h.Approval("it", models.Passed, "manager", true, []string{"end"}, waiting, stepConfig); !errors.Is(err, ErrWaitingForChild) {
+		t.Errorf("Approval() on waiting step error = %v, want %v", err, ErrWaitingForChild)
+	}
+}
@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// Subscription 模板级的webhook订阅
+type Subscription struct {
+	Uid      string   `json:"uid"`      // 订阅唯一标识
+	Template string   `json:"template"` // 订阅的工单模板唯一标识
+	URL      string   `json:"url"`      // 推送地址
+	Secret   string   `json:"secret"`   // HMAC-SHA256签名密钥
+	Events   []string `json:"events"`   // 订阅的生命周期事件，为空时订阅全部事件
+}
+
+// Attempt 一次推送尝试
+type Attempt struct {
+	At         time.Time `json:"at"`          // 尝试时间
+	StatusCode int       `json:"status_code"` // 响应状态码，请求失败时为0
+	Error      string    `json:"error"`       // 失败原因
+}
+
+// Delivery 一次待推送或已推送的webhook，保存在Outbox中
+type Delivery struct {
+	ID           string          `json:"id"`           // 推送唯一标识，同时作为幂等键发送给接收方
+	Subscription string          `json:"subscription"` // 订阅唯一标识
+	Ticket       string          `json:"ticket"`       // 工单唯一标识
+	Event        string          `json:"event"`        // 生命周期事件
+	URL          string          `json:"url"`          // 推送地址
+	Payload      json.RawMessage `json:"payload"`      // 请求体
+	Status       string          `json:"status"`       // pending/delivered/failed
+	Attempts     []*Attempt      `json:"attempts"`     // 推送记录
+	NextAttempt  time.Time       `json:"next_attempt"` // 下次推送时间
+	CreatedAt    time.Time       `json:"created_at"`   // 创建时间
+}
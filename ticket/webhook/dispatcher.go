@@ -0,0 +1,323 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/victorwong171/go-utils/desc/set"
+	"github.com/victorwong171/go-utils/utils"
+	"github.com/victorwong171/punched-tape/models"
+)
+
+const (
+	HeaderSignature = "X-Punched-Tape-Signature"
+	HeaderEvent     = "X-Punched-Tape-Event"
+	HeaderDelivery  = "X-Punched-Tape-Delivery"
+
+	defaultMaxAttempts = 8
+	defaultBaseBackoff = 30 * time.Second
+	defaultMaxBackoff  = time.Hour
+	defaultBatchSize   = 100
+)
+
+var (
+	ErrBadSubscription     = errors.New("bad webhook subscription")
+	ErrUnknownSubscription = errors.New("unknown webhook subscription")
+)
+
+// Payload webhook请求体
+type Payload struct {
+	ID        string         `json:"id"`        // 推送唯一标识
+	Event     string         `json:"event"`     // 生命周期事件
+	Template  string         `json:"template"`  // 工单模板唯一标识
+	Ticket    *models.Ticket `json:"ticket"`    // 流转后的工单
+	Action    *models.Action `json:"action"`    // 引起流转的最后一次操作
+	From      string         `json:"from"`      // 操作前所在步骤
+	To        string         `json:"to"`        // 操作后所在步骤
+	Timestamp int64          `json:"timestamp"` // 事件产生时间，unix秒
+}
+
+// Dispatcher 将工单生命周期事件写入Outbox，并按指数退避推送到订阅地址
+// 实现了ticket.Listener，可直接注册到Helper
+type Dispatcher struct {
+	mu            sync.RWMutex
+	subscriptions map[string]*Subscription
+	unsubscribed  set.Set[string]
+	outbox        Outbox
+	client        *http.Client
+	maxAttempts   int
+	baseBackoff   time.Duration
+	maxBackoff    time.Duration
+	now           func() time.Time
+	onError       func(err error)
+}
+
+// Option Dispatcher的可选配置
+type Option func(*Dispatcher)
+
+// WithHTTPClient 设置推送使用的http客户端
+func WithHTTPClient(client *http.Client) Option {
+	return func(d *Dispatcher) {
+		d.client = client
+	}
+}
+
+// WithBackoff 设置最大推送次数与退避区间，第n次失败后等待 base*2^(n-1)，不超过max
+func WithBackoff(maxAttempts int, base, max time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.maxAttempts = maxAttempts
+		d.baseBackoff = base
+		d.maxBackoff = max
+	}
+}
+
+// WithErrorHandler 设置OnTransition中写入Outbox失败时的回调
+func WithErrorHandler(onError func(err error)) Option {
+	return func(d *Dispatcher) {
+		d.onError = onError
+	}
+}
+
+// NewDispatcher 创建webhook推送器
+func NewDispatcher(outbox Outbox, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		subscriptions: make(map[string]*Subscription),
+		unsubscribed:  set.InitSet[string](0),
+		outbox:        outbox,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		maxAttempts:   defaultMaxAttempts,
+		baseBackoff:   defaultBaseBackoff,
+		maxBackoff:    defaultMaxBackoff,
+		now:           time.Now,
+		onError:       func(error) {},
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Subscribe 添加或替换订阅
+func (d *Dispatcher) Subscribe(sub *Subscription) error {
+	if sub == nil || len(sub.Uid) == 0 || len(sub.Template) == 0 || len(sub.URL) == 0 || len(sub.Secret) == 0 {
+		return ErrBadSubscription
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subscriptions[sub.Uid] = sub
+	d.unsubscribed.Drop(sub.Uid)
+	return nil
+}
+
+// Unsubscribe 删除订阅，并将Outbox中该订阅待推送的记录置为失败，进程重启后也不再发送
+func (d *Dispatcher) Unsubscribe(uid string) error {
+	d.mu.Lock()
+	delete(d.subscriptions, uid)
+	d.unsubscribed.Set(uid)
+	d.mu.Unlock()
+
+	deliveries, err := d.outbox.ListBySubscription(uid)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	now := d.now()
+	for _, delivery := range deliveries {
+		if delivery.Status != StatusPending {
+			continue
+		}
+		delivery.Attempts = append(delivery.Attempts, &Attempt{At: now, Error: ErrUnknownSubscription.Error()})
+		delivery.Status = StatusFailed
+		if err = d.outbox.Update(delivery); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// OnTransition 实现ticket.Listener
+func (d *Dispatcher) OnTransition(tr *models.Transition) {
+	if err := d.Enqueue(tr); err != nil {
+		d.onError(err)
+	}
+}
+
+// Enqueue 为流转事件匹配的每个订阅生成一条推送并写入Outbox
+func (d *Dispatcher) Enqueue(tr *models.Transition) error {
+	var errs []error
+	now := d.now()
+	for _, event := range tr.Events {
+		for _, sub := range d.match(tr.Ticket.GetTemplate(), event) {
+			delivery, err := d.newDelivery(sub, tr, event, now)
+			if err == nil {
+				err = d.outbox.Enqueue(delivery)
+			}
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Deliveries 查询工单的推送记录
+func (d *Dispatcher) Deliveries(ticket string) ([]*Delivery, error) {
+	return d.outbox.ListByTicket(ticket)
+}
+
+// Flush 推送所有到期的记录，返回本次处理的条数
+func (d *Dispatcher) Flush() (int, error) {
+	due, err := d.outbox.Due(d.now(), defaultBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	var errs []error
+	for _, delivery := range due {
+		d.deliver(delivery)
+		if err = d.outbox.Update(delivery); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(due), errors.Join(errs...)
+}
+
+// Run 每隔interval调用一次Flush，直到ctx结束
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.Flush(); err != nil {
+			d.onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) match(template, event string) []*Subscription {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	result := make([]*Subscription, 0)
+	for _, sub := range d.subscriptions {
+		if sub.Template == template && (len(sub.Events) == 0 || utils.Contain(sub.Events, event)) {
+			result = append(result, sub)
+		}
+	}
+	return result
+}
+
+func (d *Dispatcher) newDelivery(sub *Subscription, tr *models.Transition, event string, now time.Time) (*Delivery, error) {
+	id := utils.GetUuid()
+	payload, err := json.Marshal(&Payload{
+		ID:        id,
+		Event:     event,
+		Template:  sub.Template,
+		Ticket:    tr.Ticket,
+		Action:    tr.Action,
+		From:      tr.From,
+		To:        tr.To,
+		Timestamp: now.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Delivery{
+		ID:           id,
+		Subscription: sub.Uid,
+		Ticket:       tr.Ticket.GetUid(),
+		Event:        event,
+		URL:          sub.URL,
+		Payload:      payload,
+		Status:       StatusPending,
+		NextAttempt:  now,
+		CreatedAt:    now,
+	}, nil
+}
+
+// deliver 推送一次并更新推送记录的状态与下次推送时间，订阅被取消的推送直接置为失败
+func (d *Dispatcher) deliver(delivery *Delivery) {
+	now := d.now()
+	d.mu.RLock()
+	sub, ok := d.subscriptions[delivery.Subscription]
+	removed := d.unsubscribed.HasKey(delivery.Subscription)
+	d.mu.RUnlock()
+	// 重启后订阅尚未重新注册时保持待推送，不消耗推送次数
+	if !ok && !removed {
+		delivery.NextAttempt = now.Add(d.baseBackoff)
+		return
+	}
+
+	attempt := &Attempt{At: now}
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	if !ok {
+		attempt.Error = ErrUnknownSubscription.Error()
+		delivery.Status = StatusFailed
+		return
+	}
+
+	attempt.StatusCode, attempt.Error = d.post(sub, delivery)
+	if len(attempt.Error) == 0 {
+		delivery.Status = StatusDelivered
+		return
+	}
+	if len(delivery.Attempts) >= d.maxAttempts {
+		delivery.Status = StatusFailed
+		return
+	}
+	delivery.NextAttempt = now.Add(d.backoff(len(delivery.Attempts)))
+}
+
+func (d *Dispatcher) post(sub *Subscription, delivery *Delivery) (int, string) {
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Sprintf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, ""
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.baseBackoff
+	for i := 1; i < attempts && wait < d.maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, d.maxBackoff)
+}
+
+// Sign 计算请求体的签名，格式为 sha256=<hex(HMAC-SHA256(secret, body))>
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 供接收方校验签名
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
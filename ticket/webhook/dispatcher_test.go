@@ -0,0 +1,325 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/victorwong171/punched-tape/models"
+	"github.com/victorwong171/punched-tape/ticket/ticket"
+)
+
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, failures int) *receiver {
+	r := &receiver{failures: failures}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		body, _ := io.ReadAll(req.Body)
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		if r.failures > 0 {
+			r.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func passedTransition() *models.Transition {
+	return &models.Transition{
+		Ticket: &models.Ticket{Uid: "t1", Template: "payroll", Status: models.Passed, Step: "end"},
+		From:   "review",
+		To:     "end",
+		Action: &models.Action{Operator: "boss", Operation: "pass", Comment: "ok"},
+		Events: []string{models.EventStepLeft, models.EventStepEntered, models.EventPassed},
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"event":"passed"}`)
+	sig := Sign("secret", body)
+	if sig != "sha256=2416145d470aec29c6df7ea73b80232e9b6d33e001d1ad1121511fe02f17363e" {
+		t.Errorf("Sign() = %v", sig)
+	}
+	if !Verify("secret", body, sig) {
+		t.Errorf("Verify() = false, want true")
+	}
+	if Verify("other", body, sig) {
+		t.Errorf("Verify() with wrong secret = true, want false")
+	}
+	if Verify("secret", []byte(`{}`), sig) {
+		t.Errorf("Verify() with tampered body = true, want false")
+	}
+}
+
+func TestDispatcher_Subscribe(t *testing.T) {
+	d := NewDispatcher(NewMemoryOutbox())
+	tests := []struct {
+		name    string
+		sub     *Subscription
+		wantErr error
+	}{
+		{name: "all is ok", sub: &Subscription{Uid: "s1", Template: "payroll", URL: "http://x", Secret: "k"}},
+		{name: "nil", sub: nil, wantErr: ErrBadSubscription},
+		{name: "no secret", sub: &Subscription{Uid: "s1", Template: "payroll", URL: "http://x"}, wantErr: ErrBadSubscription},
+		{name: "no template", sub: &Subscription{Uid: "s1", URL: "http://x", Secret: "k"}, wantErr: ErrBadSubscription},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := d.Subscribe(tt.sub); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Subscribe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDispatcher_Deliver(t *testing.T) {
+	r := newReceiver(t, 0)
+	d := NewDispatcher(NewMemoryOutbox())
+	_ = d.Subscribe(&Subscription{Uid: "s1", Template: "payroll", URL: r.URL, Secret: "k", Events: []string{models.EventPassed}})
+	_ = d.Subscribe(&Subscription{Uid: "s2", Template: "other", URL: r.URL, Secret: "k"})
+
+	d.OnTransition(passedTransition())
+	n, err := d.Flush()
+	if err != nil || n != 1 {
+		t.Fatalf("Flush() = %v, %v, want 1, nil", n, err)
+	}
+	if len(r.requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(r.requests))
+	}
+
+	req, body := r.requests[0], r.bodies[0]
+	if req.Header.Get(HeaderEvent) != models.EventPassed {
+		t.Errorf("event header = %v, want %v", req.Header.Get(HeaderEvent), models.EventPassed)
+	}
+	if !Verify("k", body, req.Header.Get(HeaderSignature)) {
+		t.Errorf("signature %v does not verify", req.Header.Get(HeaderSignature))
+	}
+	payload := &Payload{}
+	if err = json.Unmarshal(body, payload); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if payload.ID != req.Header.Get(HeaderDelivery) || payload.Ticket.Uid != "t1" || payload.Action.Comment != "ok" || payload.Template != "payroll" {
+		t.Errorf("payload = %+v", payload)
+	}
+
+	deliveries, _ := d.Deliveries("t1")
+	if len(deliveries) != 1 || deliveries[0].Status != StatusDelivered || deliveries[0].Attempts[0].StatusCode != http.StatusOK {
+		t.Errorf("Deliveries() = %+v", deliveries)
+	}
+
+	// 已推送的记录不再重复推送
+	if n, _ = d.Flush(); n != 0 {
+		t.Errorf("Flush() = %v, want 0", n)
+	}
+}
+
+func TestDispatcher_Retry(t *testing.T) {
+	r := newReceiver(t, 2)
+	now := time.Unix(1000, 0)
+	d := NewDispatcher(NewMemoryOutbox(), WithBackoff(5, time.Second, time.Minute))
+	d.now = func() time.Time { return now }
+	_ = d.Subscribe(&Subscription{Uid: "s1", Template: "payroll", URL: r.URL, Secret: "k", Events: []string{models.EventPassed}})
+	_ = d.Enqueue(passedTransition())
+
+	_, _ = d.Flush()
+	deliveries, _ := d.Deliveries("t1")
+	if deliveries[0].Status != StatusPending || !deliveries[0].NextAttempt.Equal(now.Add(time.Second)) {
+		t.Fatalf("after first failure delivery = %+v", deliveries[0])
+	}
+
+	// 未到下次推送时间
+	if n, _ := d.Flush(); n != 0 {
+		t.Errorf("Flush() = %v, want 0", n)
+	}
+
+	now = now.Add(time.Second)
+	_, _ = d.Flush()
+	deliveries, _ = d.Deliveries("t1")
+	if !deliveries[0].NextAttempt.Equal(now.Add(2 * time.Second)) {
+		t.Fatalf("after second failure next attempt = %v, want %v", deliveries[0].NextAttempt, now.Add(2*time.Second))
+	}
+
+	now = now.Add(2 * time.Second)
+	_, _ = d.Flush()
+	deliveries, _ = d.Deliveries("t1")
+	if deliveries[0].Status != StatusDelivered || len(deliveries[0].Attempts) != 3 {
+		t.Errorf("after retry delivery = %+v", deliveries[0])
+	}
+	if deliveries[0].Attempts[0].StatusCode != http.StatusServiceUnavailable || len(deliveries[0].Attempts[0].Error) == 0 {
+		t.Errorf("first attempt = %+v", deliveries[0].Attempts[0])
+	}
+}
+
+func TestDispatcher_GiveUp(t *testing.T) {
+	r := newReceiver(t, 10)
+	now := time.Unix(1000, 0)
+	d := NewDispatcher(NewMemoryOutbox(), WithBackoff(2, time.Second, time.Minute))
+	d.now = func() time.Time { return now }
+	_ = d.Subscribe(&Subscription{Uid: "s1", Template: "payroll", URL: r.URL, Secret: "k", Events: []string{models.EventPassed}})
+	_ = d.Enqueue(passedTransition())
+
+	_, _ = d.Flush()
+	now = now.Add(time.Minute)
+	_, _ = d.Flush()
+	deliveries, _ := d.Deliveries("t1")
+	if deliveries[0].Status != StatusFailed || len(deliveries[0].Attempts) != 2 {
+		t.Errorf("delivery = %+v, want failed after 2 attempts", deliveries[0])
+	}
+}
+
+func TestDispatcher_Unsubscribed(t *testing.T) {
+	d := NewDispatcher(NewMemoryOutbox())
+	_ = d.Subscribe(&Subscription{Uid: "s1", Template: "payroll", URL: "http://127.0.0.1:1", Secret: "k", Events: []string{models.EventPassed}})
+	_ = d.Enqueue(passedTransition())
+	if err := d.Unsubscribe("s1"); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+	_, _ = d.Flush()
+	deliveries, _ := d.Deliveries("t1")
+	if deliveries[0].Status != StatusFailed || deliveries[0].Attempts[0].Error != ErrUnknownSubscription.Error() {
+		t.Errorf("delivery = %+v, want failed with unknown subscription", deliveries[0])
+	}
+}
+
+func TestDispatcher_UnsubscribedSurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	outbox, _ := NewFileOutbox(path)
+	d := NewDispatcher(outbox)
+	_ = d.Subscribe(&Subscription{Uid: "s1", Template: "payroll", URL: "http://127.0.0.1:1", Secret: "k", Events: []string{models.EventPassed}})
+	_ = d.Enqueue(passedTransition())
+	if err := d.Unsubscribe("s1"); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+
+	// 取消订阅后进程重启，推送不再重新调度
+	outbox, _ = NewFileOutbox(path)
+	d = NewDispatcher(outbox)
+	if n, err := d.Flush(); n != 0 || err != nil {
+		t.Errorf("Flush() after restart = %v, %v, want 0", n, err)
+	}
+	deliveries, _ := d.Deliveries("t1")
+	if len(deliveries) != 1 || deliveries[0].Status != StatusFailed || len(deliveries[0].Attempts) != 1 {
+		t.Errorf("Deliveries() after restart = %+v, want one failed", deliveries)
+	}
+}
+
+func Test_backoff(t *testing.T) {
+	d := NewDispatcher(NewMemoryOutbox(), WithBackoff(10, time.Second, 10*time.Second))
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 4, want: 8 * time.Second},
+		{attempts: 5, want: 10 * time.Second},
+		{attempts: 60, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := d.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestDispatcher_SurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	outbox, _ := NewFileOutbox(path)
+	sub := &Subscription{Uid: "s1", Template: "payroll", URL: "http://127.0.0.1:1", Secret: "k", Events: []string{models.EventPassed}}
+
+	// 推送前进程退出
+	d := NewDispatcher(outbox)
+	_ = d.Subscribe(sub)
+	_ = d.Enqueue(passedTransition())
+
+	r := newReceiver(t, 0)
+	sub.URL = r.URL
+	outbox, _ = NewFileOutbox(path)
+	d = NewDispatcher(outbox)
+	at := time.Now()
+	d.now = func() time.Time { return at }
+
+	// 订阅重新注册前刷新，推送保持待推送
+	if n, err := d.Flush(); n != 1 || err != nil {
+		t.Fatalf("Flush() before Subscribe = %v, %v", n, err)
+	}
+	deliveries, _ := d.Deliveries("t1")
+	if len(deliveries) != 1 || deliveries[0].Status != StatusPending || len(deliveries[0].Attempts) != 0 {
+		t.Fatalf("Deliveries() before Subscribe = %+v, want pending without attempts", deliveries)
+	}
+	at = at.Add(defaultBaseBackoff)
+	_ = d.Subscribe(sub)
+	deliveries, _ = d.Deliveries("t1")
+	if len(deliveries) != 1 {
+		t.Fatalf("Deliveries() after restart = %v, want 1", len(deliveries))
+	}
+	// 推送地址在入队时确定
+	deliveries[0].URL = r.URL
+	_ = outbox.Update(deliveries[0])
+
+	if n, err := d.Flush(); n != 1 || err != nil {
+		t.Fatalf("Flush() = %v, %v", n, err)
+	}
+	if len(r.requests) != 1 {
+		t.Errorf("receiver got %d requests, want 1", len(r.requests))
+	}
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	r := newReceiver(t, 0)
+	d := NewDispatcher(NewMemoryOutbox())
+	_ = d.Subscribe(&Subscription{Uid: "s1", Template: "payroll", URL: r.URL, Secret: "k", Events: []string{models.EventPassed}})
+
+	h := &ticket.Helper{}
+	h.Register(d)
+	stepConfig := map[string]*models.StepConfig{
+		"review": {
+			Step:     "review",
+			Disposal: models.Disposal{SignType: models.AnyoneSign},
+			Next:     []*models.NextStep{{Operation: "pass", Step: "end"}},
+		},
+		"end": {Step: "end"},
+	}
+	tk := &models.Ticket{Uid: "t1", Template: "payroll", Status: models.Running, Step: "review", Operator: []string{"boss"}}
+	if _, err := h.Approval("end", "pass", "boss", false, []string{"end"}, tk, stepConfig); err != nil {
+		t.Fatalf("Approval() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if deliveries, _ := d.Deliveries("t1"); len(deliveries) == 1 && deliveries[0].Status == StatusDelivered {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	deliveries, _ := d.Deliveries("t1")
+	if len(deliveries) != 1 || deliveries[0].Status != StatusDelivered {
+		t.Errorf("Deliveries() = %+v, want one delivered", deliveries)
+	}
+}
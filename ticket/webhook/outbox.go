@@ -0,0 +1,151 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrDeliveryNotFound = errors.New("delivery not found")
+
+// Outbox 持久化的待推送队列，保证进程重启后未完成的推送可以继续
+type Outbox interface {
+	// Enqueue 加入新的推送
+	Enqueue(d *Delivery) error
+	// Due 返回到期待推送的记录，按下次推送时间排序
+	Due(now time.Time, limit int) ([]*Delivery, error)
+	// Update 保存推送结果
+	Update(d *Delivery) error
+	// ListByTicket 按工单查询推送记录，按创建时间排序
+	ListByTicket(ticket string) ([]*Delivery, error)
+	// ListBySubscription 按订阅查询推送记录，按创建时间排序
+	ListBySubscription(subscription string) ([]*Delivery, error)
+}
+
+type memoryOutbox struct {
+	mu         sync.Mutex
+	deliveries map[string]*Delivery
+	persist    func(map[string]*Delivery) error
+}
+
+// NewMemoryOutbox 创建基于内存的Outbox，进程退出后数据丢失，适用于测试
+func NewMemoryOutbox() Outbox {
+	return &memoryOutbox{
+		deliveries: make(map[string]*Delivery),
+		persist:    func(map[string]*Delivery) error { return nil },
+	}
+}
+
+// NewFileOutbox 创建基于JSON文件的Outbox，每次变更整体写入path
+// 创建时加载已有文件，适用于单进程部署
+func NewFileOutbox(path string) (Outbox, error) {
+	deliveries := make(map[string]*Delivery)
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err = json.Unmarshal(data, &deliveries); err != nil {
+			return nil, err
+		}
+	}
+	return &memoryOutbox{
+		deliveries: deliveries,
+		persist: func(deliveries map[string]*Delivery) error {
+			return writeFile(path, deliveries)
+		},
+	}, nil
+}
+
+// writeFile 先写临时文件再重命名，避免进程中断导致文件损坏
+func writeFile(path string, deliveries map[string]*Delivery) error {
+	data, err := json.Marshal(deliveries)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (o *memoryOutbox) Enqueue(d *Delivery) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.deliveries[d.ID] = clone(d)
+	return o.persist(o.deliveries)
+}
+
+func (o *memoryOutbox) Due(now time.Time, limit int) ([]*Delivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	due := make([]*Delivery, 0)
+	for _, d := range o.deliveries {
+		if d.Status == StatusPending && !d.NextAttempt.After(now) {
+			due = append(due, clone(d))
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttempt.Before(due[j].NextAttempt)
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (o *memoryOutbox) Update(d *Delivery) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok := o.deliveries[d.ID]; !ok {
+		return ErrDeliveryNotFound
+	}
+	o.deliveries[d.ID] = clone(d)
+	return o.persist(o.deliveries)
+}
+
+func (o *memoryOutbox) ListByTicket(ticket string) ([]*Delivery, error) {
+	return o.list(func(d *Delivery) bool { return d.Ticket == ticket }), nil
+}
+
+func (o *memoryOutbox) ListBySubscription(subscription string) ([]*Delivery, error) {
+	return o.list(func(d *Delivery) bool { return d.Subscription == subscription }), nil
+}
+
+// list 返回满足条件的推送记录，按创建时间排序
+func (o *memoryOutbox) list(match func(d *Delivery) bool) []*Delivery {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	result := make([]*Delivery, 0)
+	for _, d := range o.deliveries {
+		if match(d) {
+			result = append(result, clone(d))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// clone 复制推送记录，避免调用方修改Outbox内部数据
+func clone(d *Delivery) *Delivery {
+	c := *d
+	c.Attempts = append([]*Attempt(nil), d.Attempts...)
+	return &c
+}
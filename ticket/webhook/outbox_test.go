@@ -0,0 +1,113 @@
+package webhook
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testOutboxes(t *testing.T) map[string]func() Outbox {
+	dir := t.TempDir()
+	return map[string]func() Outbox{
+		"memory": NewMemoryOutbox,
+		"file": func() Outbox {
+			o, err := NewFileOutbox(filepath.Join(dir, "outbox.json"))
+			if err != nil {
+				t.Fatalf("NewFileOutbox() error = %v", err)
+			}
+			return o
+		},
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	base := time.Unix(1000, 0)
+	for name, newOutbox := range testOutboxes(t) {
+		t.Run(name, func(t *testing.T) {
+			o := newOutbox()
+			_ = o.Enqueue(&Delivery{ID: "1", Ticket: "t1", Subscription: "s1", Status: StatusPending, NextAttempt: base.Add(2 * time.Second), CreatedAt: base})
+			_ = o.Enqueue(&Delivery{ID: "2", Ticket: "t1", Status: StatusPending, NextAttempt: base.Add(time.Second), CreatedAt: base.Add(time.Second)})
+			_ = o.Enqueue(&Delivery{ID: "3", Ticket: "t2", Status: StatusPending, NextAttempt: base.Add(time.Hour), CreatedAt: base})
+			_ = o.Enqueue(&Delivery{ID: "4", Ticket: "t2", Status: StatusDelivered, NextAttempt: base, CreatedAt: base})
+
+			due, err := o.Due(base.Add(time.Minute), 0)
+			if err != nil {
+				t.Fatalf("Due() error = %v", err)
+			}
+			if len(due) != 2 || due[0].ID != "2" || due[1].ID != "1" {
+				t.Errorf("Due() = %v, want [2 1]", ids(due))
+			}
+			if due, _ = o.Due(base.Add(time.Minute), 1); len(due) != 1 {
+				t.Errorf("Due() with limit = %v, want 1 item", ids(due))
+			}
+
+			// 修改返回值不影响Outbox内部数据
+			due[0].Status = StatusFailed
+			if due, _ = o.Due(base.Add(time.Minute), 0); len(due) != 2 {
+				t.Errorf("Due() = %v, want 2 items", ids(due))
+			}
+
+			due[0].Status = StatusDelivered
+			due[0].Attempts = append(due[0].Attempts, &Attempt{At: base, StatusCode: 200})
+			if err = o.Update(due[0]); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if err = o.Update(&Delivery{ID: "missing"}); !errors.Is(err, ErrDeliveryNotFound) {
+				t.Errorf("Update() error = %v, wantErr %v", err, ErrDeliveryNotFound)
+			}
+
+			list, _ := o.ListByTicket("t1")
+			if len(list) != 2 || list[0].ID != "1" || list[1].ID != "2" {
+				t.Errorf("ListByTicket() = %v, want [1 2]", ids(list))
+			}
+			if list[1].Status != StatusDelivered || len(list[1].Attempts) != 1 {
+				t.Errorf("ListByTicket() delivery 2 = %+v, want delivered with 1 attempt", list[1])
+			}
+			if list, _ = o.ListBySubscription("s1"); len(list) != 1 || list[0].ID != "1" {
+				t.Errorf("ListBySubscription() = %v, want [1]", ids(list))
+			}
+		})
+	}
+}
+
+func TestFileOutbox_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	o, _ := NewFileOutbox(path)
+	_ = o.Enqueue(&Delivery{ID: "1", Ticket: "t1", Status: StatusPending, Payload: []byte(`{"a":1}`)})
+
+	reloaded, err := NewFileOutbox(path)
+	if err != nil {
+		t.Fatalf("NewFileOutbox() error = %v", err)
+	}
+	due, _ := reloaded.Due(time.Now(), 0)
+	if len(due) != 1 || string(due[0].Payload) != `{"a":1}` {
+		t.Errorf("Due() after reload = %v", due)
+	}
+}
+
+func TestFileOutbox_BadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "outbox.json")
+	_ = os.WriteFile(path, []byte("not json"), 0o644)
+	if _, err := NewFileOutbox(path); err == nil {
+		t.Errorf("NewFileOutbox() error = nil, want error")
+	}
+	if _, err := NewFileOutbox(dir); err == nil {
+		t.Errorf("NewFileOutbox() on directory error = nil, want error")
+	}
+
+	o, _ := NewFileOutbox(filepath.Join(dir, "missing", "outbox.json"))
+	if err := o.Enqueue(&Delivery{ID: "1"}); err == nil {
+		t.Errorf("Enqueue() error = nil, want error")
+	}
+}
+
+func ids(list []*Delivery) []string {
+	result := make([]string, 0, len(list))
+	for _, d := range list {
+		result = append(result, d.ID)
+	}
+	return result
+}
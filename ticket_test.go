@@ -43,6 +43,16 @@ func TestNewTicketBuilder(t *testing.T) {
 	}
 }
 
+func TestTicketBuilder_SetState(t *testing.T) {
+	builder := NewTicketBuilder("user123", "TICKET-001", "approval", "test")
+	if result := builder.SetState("in_review"); result != builder {
+		t.Errorf("SetState() should return builder instance")
+	}
+	if builder.option.State != "in_review" {
+		t.Errorf("SetState() = %v, want in_review", builder.option.State)
+	}
+}
+
 func TestTicketBuilder_SetStatus(t *testing.T) {
 	builder := NewTicketBuilder("user123", "TICKET-001", "approval", "test")
 
@@ -288,6 +298,29 @@ func TestTicketBuilder_SetMemo(t *testing.T) {
 	}
 }
 
+func TestTicketBuilder_SetTemplate(t *testing.T) {
+	builder := NewTicketBuilder("user123", "TICKET-001", "approval", "test")
+
+	if result := builder.SetTemplate("expense"); result != builder {
+		t.Errorf("SetTemplate() should return builder instance")
+	}
+	if builder.option.Template != "expense" {
+		t.Errorf("SetTemplate() = %v, want expense", builder.option.Template)
+	}
+}
+
+func TestTicketBuilder_SetFormData(t *testing.T) {
+	builder := NewTicketBuilder("user123", "TICKET-001", "approval", "test")
+
+	data := map[string]any{"amount": 100, "reason": "trip"}
+	if result := builder.SetFormData(data); result != builder {
+		t.Errorf("SetFormData() should return builder instance")
+	}
+	if len(builder.option.FormData) != 2 || builder.option.FormData["amount"] != 100 {
+		t.Errorf("SetFormData() = %v, want %v", builder.option.FormData, data)
+	}
+}
+
 func TestTicketBuilder_Build(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -295,6 +328,10 @@ func TestTicketBuilder_Build(t *testing.T) {
 		orderNum    string
 		step        string
 		status      string
+		template    *models.TicketTemplate
+		formData    map[string]any
+		state       string
+		wantState   string
 		expectError bool
 	}{
 		{
@@ -329,12 +366,74 @@ func TestTicketBuilder_Build(t *testing.T) {
 			status:      "invalid_status",
 			expectError: true,
 		},
+		{
+			name:        "custom status declared by template",
+			uid:         "user123",
+			orderNum:    "TICKET-001",
+			step:        "approval",
+			status:      "withdrawn",
+			template:    &models.TicketTemplate{Uid: "leave", Statuses: []string{"withdrawn"}},
+			expectError: false,
+		},
+		{
+			name:        "custom status of another template",
+			uid:         "user123",
+			orderNum:    "TICKET-001",
+			step:        "approval",
+			status:      "withdrawn",
+			template:    &models.TicketTemplate{Uid: "leave"},
+			expectError: true,
+		},
+		{
+			name:        "form data matches template form",
+			uid:         "user123",
+			orderNum:    "TICKET-001",
+			step:        "approval",
+			status:      models.Running,
+			template:    &models.TicketTemplate{Uid: "leave", Form: []*models.FormField{{Name: "days", Type: models.FieldNumber, Required: true}}},
+			formData:    map[string]any{"days": 3},
+			expectError: false,
+		},
+		{
+			name:        "required form field missing",
+			uid:         "user123",
+			orderNum:    "TICKET-001",
+			step:        "approval",
+			status:      models.Running,
+			template:    &models.TicketTemplate{Uid: "leave", Form: []*models.FormField{{Name: "days", Type: models.FieldNumber, Required: true}}},
+			formData:    map[string]any{"reason": "trip"},
+			expectError: true,
+		},
+		{
+			name:        "state from step config",
+			uid:         "user123",
+			orderNum:    "TICKET-001",
+			step:        "approval",
+			status:      models.Running,
+			template:    &models.TicketTemplate{Uid: "leave", Config: []*models.StepConfig{{Step: "approval", State: "in_review"}}},
+			wantState:   "in_review",
+			expectError: false,
+		},
+		{
+			name:        "explicit state kept",
+			uid:         "user123",
+			orderNum:    "TICKET-001",
+			step:        "approval",
+			status:      models.Running,
+			template:    &models.TicketTemplate{Uid: "leave", Config: []*models.StepConfig{{Step: "approval", State: "in_review"}}},
+			state:       "drafting",
+			wantState:   "drafting",
+			expectError: false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			builder := NewTicketBuilder(tt.uid, tt.orderNum, tt.step, "test")
-			builder.SetStatus(tt.status)
+			builder.SetStatus(tt.status).SetState(tt.state).SetFormData(tt.formData)
+			if tt.template != nil {
+				builder.SetTicketTemplate(tt.template)
+			}
 
 			ticket, err := builder.Build()
 
@@ -364,6 +463,9 @@ func TestTicketBuilder_Build(t *testing.T) {
 				if ticket.Status != tt.status {
 					t.Errorf("Build() Status = %v, want %v", ticket.Status, tt.status)
 				}
+				if ticket.State != tt.wantState {
+					t.Errorf("Build() State = %v, want %v", ticket.State, tt.wantState)
+				}
 			}
 		})
 	}